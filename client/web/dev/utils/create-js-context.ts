@@ -44,6 +44,7 @@ export const createJsContext = ({ sourcegraphBaseUrl }: { sourcegraphBaseUrl: st
         needServerRestart: false,
         needsSiteInit: false,
         resetPasswordEnabled: true,
+        passwordResetLinkExpirySeconds: 14400,
         sentryDSN: null,
         site: {
             'update.channel': 'release',
@@ -35,6 +35,7 @@ export const createJsContext = ({ sourcegraphBaseUrl }: { sourcegraphBaseUrl: st
     needServerRestart: false,
     needsSiteInit: false,
     resetPasswordEnabled: false,
+    passwordResetLinkExpirySeconds: 14400,
     sentryDSN: null,
     site: {},
     siteID,
@@ -2,6 +2,25 @@ import { SiteConfiguration } from '@sourcegraph/shared/src/schema/site.schema'
 
 export type DeployType = 'kubernetes' | 'docker-container' | 'docker-compose' | 'pure-docker' | 'dev' | 'helm'
 
+/**
+ * A GraphQL API rate limit. Defined in cmd/frontend/internal/app/jscontext/jscontext.go UserAPIRateLimit struct.
+ */
+export interface UserAPIRateLimit {
+    /** The cost of the requests that can be made per window. */
+    limit: number
+    windowSeconds: number
+}
+
+/**
+ * A subject in the settings cascade. Defined in cmd/frontend/internal/app/jscontext/jscontext.go SettingsSubject struct.
+ */
+export interface SettingsSubject {
+    type: 'Site' | 'Org' | 'User'
+    id: string
+    /** The URL of the page to edit the subject's settings. */
+    url: string
+}
+
 /**
  * Defined in cmd/frontend/internal/app/jscontext/jscontext.go JSContext struct
  */
@@ -32,6 +51,45 @@ export interface SourcegraphContext extends Pick<Required<SiteConfiguration>, 'e
      */
     readonly isAuthenticatedUser: boolean
 
+    /** The authenticated user, or null if the user is not authenticated. */
+    currentUser?: {
+        id: string
+        databaseID: number
+        username: string
+        siteAdmin: boolean
+        tags: string[] | null
+        /** Whether repository permissions syncs are being processed. Only computed for site admins. */
+        repoPermissionsSyncHealthy: boolean
+        /** The user's effective GraphQL API rate limit, or null if the user is not rate limited. */
+        apiRateLimit: UserAPIRateLimit | null
+        /** The settings subjects whose settings the user can edit, from the most to the least general. */
+        editableSettingsSubjects: SettingsSubject[]
+        /** Whether any of the saved searches of the user or their organizations sends notifications. */
+        hasNotifyingSavedSearches: boolean
+        /**
+         * Whether the user has site admin access only through a temporary assignment of the ADMIN role. siteAdmin
+         * is false for them, but the canX fields take their access into account.
+         */
+        temporaryAdmin: boolean
+        /** Whether the user can generate support bundles. */
+        canGenerateSupportBundle: boolean
+        /** Whether the user can create, update and delete incoming webhooks. */
+        canManageWebhooks: boolean
+        /** Whether the user can view the audit log of destructive RBAC operations. */
+        canViewAuditLogs: boolean
+        /** Whether the user administers batch changes that were created but never applied. */
+        hasDraftBatchChanges: boolean
+        /** The number of searches the user can run at the same time before the UI warns them. Not enforced by the server. */
+        maxConcurrentSearches: number
+        /** The names of the RBAC roles assigned to the user. */
+        roles: string[]
+        /** The RBAC permissions granted to the user by their roles, such as BATCH_CHANGES#READ. */
+        permissions: string[]
+    } | null
+
+    /** The GraphQL API rate limit for visitors who are not signed in, or null if they are not rate limited. */
+    anonymousAPIRateLimit?: UserAPIRateLimit | null
+
     readonly sentryDSN: string | null
 
     readonly openTelemetry?: {
@@ -85,6 +143,9 @@ export interface SourcegraphContext extends Pick<Required<SiteConfiguration>, 'e
     /** Whether the reset-password flow is enabled. */
     resetPasswordEnabled: boolean
 
+    /** How long password reset links are valid, in seconds. */
+    passwordResetLinkExpirySeconds: number
+
     /**
      * Likely running within a Docker container under a Mac host OS.
      */
@@ -131,6 +192,9 @@ export interface SourcegraphContext extends Pick<Required<SiteConfiguration>, 'e
     /** Authentication provider instances in site config. */
     authProviders: AuthProvider[]
 
+    /** Whether the builtin username/password login is disabled because only SSO is allowed. */
+    builtinLoginDisabled?: boolean
+
     /** What the minimum length for a password should be. */
     authMinPasswordLength: number
 
@@ -186,6 +250,56 @@ export interface SourcegraphContext extends Pick<Required<SiteConfiguration>, 'e
 
     /** Whether the feedback survey is enabled. */
     disableFeedbackSurvey?: boolean
+
+    /** The editor that "open in editor" links are preconfigured for, if any. */
+    defaultEditorIntegration?: string
+
+    /** The link template for the default editor, with %file, %line, and %col placeholders. */
+    defaultEditorLinkTemplate?: string
+
+    /** Instance-wide announcements configured by site admins. */
+    announcements?: {
+        id: string
+        message: string
+        severity: 'info' | 'warning' | 'error'
+        dismissible: boolean
+    }[]
+
+    /** Whether commits are required to be signed, so that the UI can warn users. */
+    requireSignedCommits?: boolean
+
+    /** Whether repository visibility rules are enforced, so that the UI hides public-sharing controls. */
+    repoVisibilityEnforced?: boolean
+
+    /** The base URL for share links. Defaults to externalURL, but may differ in proxied setups. */
+    shareURLBase: string
+
+    /** Mapping of file extensions to the languages that files with these extensions are highlighted as. */
+    syntaxHighlightingOverrides: Record<string, string>
+
+    /** The kind of code host that is preselected when adding a code host connection, or empty if there is no preference. */
+    preferredCodeHostKind: string
+
+    /** The order in which repository lists are sorted by default, e.g. "name" or "recently-updated". */
+    defaultRepoSortOrder: string
+
+    /** The number of results that a streaming search returns if the query doesn't specify count:. */
+    maxSearchResults: number
+
+    /** Whether the current user must join or create an organization before using Sourcegraph. */
+    orgMembershipRequired: boolean
+
+    /** The sanitized HTML shown on error pages instead of the default message, or empty to show the default message. */
+    customErrorPageContent: string
+
+    /** Whether a backup is in progress, so that a banner asking users to treat the instance as read-only is shown. */
+    backupInProgress: boolean
+
+    /** The sanitized HTML shown in the backup banner instead of the default message, or empty to show the default message. */
+    backupInProgressMessage: string
+
+    /** The locale, such as "en" or "pt-BR", to use before the settings are loaded. */
+    preferredLocale: string
 }
 
 export interface BrandAssets {
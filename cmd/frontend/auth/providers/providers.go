@@ -92,6 +92,9 @@ var (
 	curProviders   = map[string]map[string]Provider{}
 	curProvidersMu sync.RWMutex
 
+	// generation is incremented every time Update is called. It is guarded by curProvidersMu.
+	generation uint64
+
 	MockProviders []Provider
 )
 
@@ -101,6 +104,8 @@ func Update(pkgName string, providers []Provider) {
 	curProvidersMu.Lock()
 	defer curProvidersMu.Unlock()
 
+	generation++
+
 	if providers == nil {
 		delete(curProviders, pkgName)
 		return
@@ -118,6 +123,14 @@ func Update(pkgName string, providers []Provider) {
 	curProviders[pkgName] = newPkgProviders
 }
 
+// Generation returns a number that changes every time the set of registered providers is updated.
+// It lets callers cache values derived from Providers.
+func Generation() uint64 {
+	curProvidersMu.RLock()
+	defer curProvidersMu.RUnlock()
+	return generation
+}
+
 // Providers returns the set of currently registered authentication providers. When no providers are
 // registered, returns nil (and sign-in is effectively disabled).
 func Providers() []Provider {
@@ -25,14 +25,138 @@ type PermissionResolver interface {
 	CreatedAt() gqlutil.DateTime
 }
 
+type DeleteRolesResultResolver interface {
+	DeletedRoles() []graphql.ID
+	SkippedRoles() []SkippedRoleResolver
+}
+
+type SkippedRoleResolver interface {
+	Role() graphql.ID
+	Reason() string
+}
+
+type CreatePermissionsResultResolver interface {
+	CreatedPermissions() []PermissionResolver
+	SkippedPermissions() []SkippedPermissionResolver
+}
+
+type SkippedPermissionResolver interface {
+	Namespace() string
+	Action() string
+	Reason() string
+}
+
+type RoleDeletionPreviewResolver interface {
+	AffectedUserCount() int32
+	LostPermissions() []PermissionResolver
+}
+
+type RoleBlastRadiusResolver interface {
+	UserCount() int32
+	PermissionCount() int32
+	BlastRadius() int32
+}
+
+type PermissionNamespaceCountResolver interface {
+	Namespace() string
+	Count() int32
+}
+
+type PermissionMatrixRowResolver interface {
+	User() graphql.ID
+	Granted() []bool
+}
+
+type UserWithInertPermissionsResolver interface {
+	User() *UserResolver
+	Permissions() []PermissionResolver
+}
+
+type UserImpactedByRoleChangeResolver interface {
+	User() *UserResolver
+	LostPermissions() []PermissionResolver
+}
+
+type PermissionInheritancePathResolver interface {
+	User() *UserResolver
+	Role() RoleResolver
+	Permission() PermissionResolver
+}
+
+type SystemRoleValidationResolver interface {
+	Role() RoleResolver
+	Valid() bool
+	MissingPermissions() []PermissionResolver
+	ExtraPermissions() []PermissionResolver
+}
+
+type RoleChangeResolver interface {
+	Role() RoleResolver
+	ChangeType() string
+	ChangedAt() gqlutil.DateTime
+}
+
+type RoleAssignmentResolver interface {
+	User() *UserResolver
+	Role() RoleResolver
+	AssignedAt() gqlutil.DateTime
+	ExpiresAt() *gqlutil.DateTime
+}
+
+type RBACAuditEventResolver interface {
+	Name() string
+	Actor(ctx context.Context) (*UserResolver, error)
+	Argument() string
+	Timestamp() gqlutil.DateTime
+}
+
+type RBACSearchResultResolver interface {
+	Roles() []RoleResolver
+	Permissions() []PermissionResolver
+}
+
+type ImportRBACConfigResultResolver interface {
+	DryRun() bool
+	PermissionsCreated() int32
+	RolesCreated() int32
+	RolePermissionsAssigned() int32
+	UserRolesAssigned() int32
+	Warnings() []string
+}
+
 type RBACResolver interface {
 	// MUTATIONS
 	DeleteRole(ctx context.Context, args *DeleteRoleArgs) (*EmptyResponse, error)
 	CreateRole(ctx context.Context, args *CreateRoleArgs) (RoleResolver, error)
+	AssignRoleToUser(ctx context.Context, args *AssignRoleToUserArgs) (*EmptyResponse, error)
+	CopyRolesFromUser(ctx context.Context, args *CopyRolesFromUserArgs) (*EmptyResponse, error)
+	SetRoles(ctx context.Context, args *SetRolesArgs) (*UserResolver, error)
+	DeleteRoles(ctx context.Context, args *DeleteRolesArgs) (DeleteRolesResultResolver, error)
+	ImportRBACConfig(ctx context.Context, args *ImportRBACConfigArgs) (ImportRBACConfigResultResolver, error)
+	CreatePermissions(ctx context.Context, args *CreatePermissionsArgs) (CreatePermissionsResultResolver, error)
 
 	// QUERIES
 	Roles(ctx context.Context, args *ListRoleArgs) (*graphqlutil.ConnectionResolver[RoleResolver], error)
 	Permissions(ctx context.Context, args *ListPermissionArgs) (*graphqlutil.ConnectionResolver[PermissionResolver], error)
+	AdminOverrideCoverage(ctx context.Context, args *AdminOverrideCoverageArgs) ([]PermissionResolver, error)
+	MissingPermissionsForFeature(ctx context.Context, args *MissingPermissionsForFeatureArgs) ([]PermissionResolver, error)
+	RolesWithPrivilegeEscalationRisk(ctx context.Context) ([]RoleResolver, error)
+	EmptyRoles(ctx context.Context, args *EmptyRolesArgs) (*graphqlutil.ConnectionResolver[RoleResolver], error)
+	PermissionMatrix(ctx context.Context, args *PermissionMatrixArgs) ([]PermissionMatrixRowResolver, error)
+	PermissionCountsByNamespace(ctx context.Context) ([]PermissionNamespaceCountResolver, error)
+	RBACActions(ctx context.Context) ([]string, error)
+	UsersWithInertPermissions(ctx context.Context) ([]UserWithInertPermissionsResolver, error)
+	ValidateSystemRoles(ctx context.Context) ([]SystemRoleValidationResolver, error)
+	RecentRoleChanges(ctx context.Context, args *RecentRoleChangesArgs) ([]RoleChangeResolver, error)
+	RecentAssignmentsForManagedUsers(ctx context.Context, args *RecentAssignmentsForManagedUsersArgs) ([]RoleAssignmentResolver, error)
+	PreviewRoleDeletion(ctx context.Context, args *PreviewRoleDeletionArgs) (RoleDeletionPreviewResolver, error)
+	RoleBlastRadius(ctx context.Context, args *RoleBlastRadiusArgs) (RoleBlastRadiusResolver, error)
+	RolesAffectedByPermissionDeletion(ctx context.Context, args *RolesAffectedByPermissionDeletionArgs) ([]RoleResolver, error)
+	PermissionInheritancePath(ctx context.Context, args *PermissionInheritancePathArgs) ([]PermissionInheritancePathResolver, error)
+	RBACSearch(ctx context.Context, args *RBACSearchArgs) (RBACSearchResultResolver, error)
+	ExportRBACConfig(ctx context.Context) (string, error)
+	DestructiveRBACAudit(ctx context.Context, args *DestructiveRBACAuditArgs) ([]RBACAuditEventResolver, error)
+	UsersImpactedByRoleChange(ctx context.Context, args *UsersImpactedByRoleChangeArgs) ([]UserImpactedByRoleChangeResolver, error)
 
 	NodeResolvers() map[string]NodeByIDFunc
 }
@@ -42,19 +166,117 @@ type DeleteRoleArgs struct {
 }
 
 type CreateRoleArgs struct {
-	Name string
+	Name        string
+	Permissions *[]graphql.ID
+}
+
+type AssignRoleToUserArgs struct {
+	User      graphql.ID
+	Role      graphql.ID
+	ExpiresAt *gqlutil.DateTime
+}
+
+type CopyRolesFromUserArgs struct {
+	Source graphql.ID
+	Target graphql.ID
+}
+
+type SetRolesArgs struct {
+	User  graphql.ID
+	Roles []graphql.ID
+}
+
+type DeleteRolesArgs struct {
+	Roles []graphql.ID
+	Force bool
+}
+
+type CreatePermissionsArgs struct {
+	Permissions []PermissionInput
+}
+
+type PermissionInput struct {
+	Namespace string
+	Action    string
 }
 
 type ListRoleArgs struct {
 	graphqlutil.ConnectionResolverArgs
 
-	System bool
+	System *bool
 	User   *graphql.ID
+	SortBy string
+}
+
+type EmptyRolesArgs struct {
+	graphqlutil.ConnectionResolverArgs
 }
 
 type ListPermissionArgs struct {
 	graphqlutil.ConnectionResolverArgs
 
-	Role *graphql.ID
-	User *graphql.ID
+	Role      *graphql.ID
+	User      *graphql.ID
+	Namespace *string
+}
+
+type AdminOverrideCoverageArgs struct {
+	User graphql.ID
+}
+
+type MissingPermissionsForFeatureArgs struct {
+	User    graphql.ID
+	Feature string
+}
+
+type PermissionMatrixArgs struct {
+	Users       []graphql.ID
+	Permissions []graphql.ID
+}
+
+type RecentRoleChangesArgs struct {
+	First int32
+}
+
+type RecentAssignmentsForManagedUsersArgs struct {
+	First int32
+}
+
+type PreviewRoleDeletionArgs struct {
+	Role graphql.ID
+}
+
+type RoleBlastRadiusArgs struct {
+	Role graphql.ID
+}
+
+type RolesAffectedByPermissionDeletionArgs struct {
+	Permission graphql.ID
+}
+
+type PermissionInheritancePathArgs struct {
+	User       graphql.ID
+	Permission graphql.ID
+}
+
+type RBACSearchArgs struct {
+	Query string
+}
+
+type DestructiveRBACAuditArgs struct {
+	Since *gqlutil.DateTime
+	Until *gqlutil.DateTime
+	Actor *graphql.ID
+	First int32
+}
+
+type UsersImpactedByRoleChangeArgs struct {
+	Role  graphql.ID
+	Since gqlutil.DateTime
+	First int32
+}
+
+type ImportRBACConfigArgs struct {
+	Config string
+	DryRun bool
 }
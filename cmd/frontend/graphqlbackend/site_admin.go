@@ -17,6 +17,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
@@ -315,8 +317,9 @@ func (r *schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 	defer logRoleChangeAttempt(ctx, r.db, &eventName, &eventArgs, &err)
 
 	// 🚨 SECURITY: Only site admins can promote other users to site admin (or demote from site
-	// admin).
-	if err = auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+	// admin). Temporary admin access through the ADMIN role is not enough, as it could otherwise
+	// be turned into permanent admin access.
+	if err = auth.CheckCurrentUserIsPermanentSiteAdmin(ctx, r.db); err != nil {
 		return nil, err
 	}
 
@@ -327,11 +330,25 @@ func (r *schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 	if err = r.db.Users().SetIsSiteAdmin(ctx, affectedUserID, args.SiteAdmin); err != nil {
 		return nil, err
 	}
+	if !args.SiteAdmin {
+		r.logSiteAdministratorRoleRevoked(ctx, affectedUserID)
+	}
 
 	eventName = database.SecurityEventNameRoleChangeGranted
 	return &EmptyResponse{}, nil
 }
 
+// logSiteAdministratorRoleRevoked records that the SITE_ADMINISTRATOR system role
+// was revoked from the given user when they were demoted from site admin.
+func (r *schemaResolver) logSiteAdministratorRoleRevoked(ctx context.Context, userID int32) {
+	role, err := r.db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.SiteAdministratorSystemRole)})
+	if err != nil {
+		r.logger.Error("failed to look up the site administrator role", log.Error(err))
+		return
+	}
+	rbac.LogDestructiveEvent(ctx, r.logger, r.db, database.SecurityEventNameRBACUserRoleRevoked, map[string]any{"userID": userID, "roleID": role.ID})
+}
+
 func (r *schemaResolver) InvalidateSessionsByID(ctx context.Context, args *struct {
 	UserID graphql.ID
 }) (*EmptyResponse, error) {
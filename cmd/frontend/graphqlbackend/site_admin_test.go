@@ -580,6 +580,7 @@ func TestDeleteOrganization_OnCloud(t *testing.T) {
 func TestSetIsSiteAdmin(t *testing.T) {
 	testCases := map[string]struct {
 		isSiteAdmin           bool
+		isTemporaryAdmin      bool
 		argsUserID            int32
 		argsSiteAdmin         bool
 		result                *EmptyResponse
@@ -596,6 +597,15 @@ func TestSetIsSiteAdmin(t *testing.T) {
 			securityLogEventCalls: 1,
 			setIsSiteAdminCalls:   0,
 		},
+		"authenticated as temporary admin": {
+			isTemporaryAdmin:      true,
+			argsUserID:            2,
+			argsSiteAdmin:         true,
+			result:                nil,
+			wantErr:               auth.ErrMustBeSiteAdmin,
+			securityLogEventCalls: 1,
+			setIsSiteAdminCalls:   0,
+		},
 		"set current user as site-admin": {
 			isSiteAdmin:           true,
 			argsUserID:            1,
@@ -620,7 +630,7 @@ func TestSetIsSiteAdmin(t *testing.T) {
 			argsSiteAdmin:         false,
 			result:                &EmptyResponse{},
 			wantErr:               nil,
-			securityLogEventCalls: 1,
+			securityLogEventCalls: 2,
 			setIsSiteAdminCalls:   1,
 		},
 	}
@@ -630,12 +640,19 @@ func TestSetIsSiteAdmin(t *testing.T) {
 			users := database.NewMockUserStore()
 			users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{ID: 1, SiteAdmin: tc.isSiteAdmin}, nil)
 			users.SetIsSiteAdminFunc.SetDefaultReturn(nil)
+			if tc.isTemporaryAdmin {
+				users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Now().Add(time.Hour), nil)
+			}
 
 			securityLogEvents := database.NewMockSecurityEventLogsStore()
 			securityLogEvents.LogEventFunc.SetDefaultReturn()
 
+			roles := database.NewMockRoleStore()
+			roles.GetFunc.SetDefaultReturn(&types.Role{ID: 2, Name: string(types.SiteAdministratorSystemRole), System: true}, nil)
+
 			db := database.NewMockDB()
 			db.UsersFunc.SetDefaultReturn(users)
+			db.RolesFunc.SetDefaultReturn(roles)
 			db.SecurityEventLogsFunc.SetDefaultReturn(securityLogEvents)
 
 			s := newSchemaResolver(db, gitserver.NewClient())
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/graph-gophers/graphql-go/relay"
@@ -231,6 +232,10 @@ func (users *fakeUsersDB) GetByCurrentAuthUser(ctx context.Context) (*types.User
 	return a.User(ctx, users)
 }
 
+func (users *fakeUsersDB) TemporaryAdminExpiresAt(context.Context, int32) (time.Time, error) {
+	return time.Time{}, nil
+}
+
 func (users *fakeUsersDB) newUser(u types.User) int32 {
 	id := users.lastUserID + 1
 	users.lastUserID = id
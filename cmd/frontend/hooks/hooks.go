@@ -2,6 +2,7 @@
 package hooks
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -20,3 +21,8 @@ type LicenseInfo struct {
 }
 
 var GetLicenseInfo = func(isSiteAdmin bool) *LicenseInfo { return nil }
+
+// HasDraftBatchChanges reports whether the user with the given ID administers
+// draft batch changes, i.e. batch changes that were created but never applied.
+// It is set by the enterprise frontend.
+var HasDraftBatchChanges = func(ctx context.Context, userID int32) (bool, error) { return false, nil }
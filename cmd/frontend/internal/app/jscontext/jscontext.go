@@ -4,11 +4,19 @@ package jscontext
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/text/language"
+
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
@@ -20,11 +28,16 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/siteid"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/webhooks"
 	sgactor "github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/conf/deploy"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/markdown"
+	"github.com/sourcegraph/sourcegraph/internal/search/limits"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/internal/version"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -48,6 +61,94 @@ type authPasswordPolicy struct {
 	RequireUpperAndLowerCase  bool `json:"requireUpperAndLowerCase"`
 }
 
+type announcement struct {
+	ID          string `json:"id"`
+	Message     string `json:"message"`
+	Severity    string `json:"severity"`
+	Dismissible bool   `json:"dismissible"`
+}
+
+// CurrentUser is the subset of the authenticated user's information that the
+// web app needs at bootstrap.
+type CurrentUser struct {
+	ID         string   `json:"id"`
+	DatabaseID int32    `json:"databaseID"`
+	Username   string   `json:"username"`
+	SiteAdmin  bool     `json:"siteAdmin"`
+	Tags       []string `json:"tags"`
+
+	// RepoPermissionsSyncHealthy is whether repository permissions syncs are
+	// being processed. It is only computed for site admins, and always false
+	// for other users.
+	RepoPermissionsSyncHealthy bool `json:"repoPermissionsSyncHealthy"`
+
+	// APIRateLimit is the user's effective GraphQL API rate limit, or nil if
+	// the user's API usage is not limited.
+	APIRateLimit *UserAPIRateLimit `json:"apiRateLimit"`
+
+	// EditableSettingsSubjects are the settings subjects whose settings the
+	// user can edit in the settings cascade editor.
+	EditableSettingsSubjects []SettingsSubject `json:"editableSettingsSubjects"`
+
+	// HasNotifyingSavedSearches is whether any of the saved searches of the
+	// user or their organizations sends notifications.
+	HasNotifyingSavedSearches bool `json:"hasNotifyingSavedSearches"`
+
+	// TemporaryAdmin is whether the user has site admin access only through a
+	// temporary assignment of the ADMIN system role. SiteAdmin is false for
+	// them, but the Can fields below take their access into account.
+	TemporaryAdmin bool `json:"temporaryAdmin"`
+
+	// CanGenerateSupportBundle is whether the user is allowed to generate
+	// support bundles, so that the UI only offers it to them.
+	CanGenerateSupportBundle bool `json:"canGenerateSupportBundle"`
+
+	// CanManageWebhooks is whether the user is allowed to create, update and
+	// delete incoming webhooks, so that the UI only offers it to them.
+	CanManageWebhooks bool `json:"canManageWebhooks"`
+
+	// CanViewAuditLogs is whether the user is allowed to view the audit log of
+	// destructive RBAC operations, so that the UI only links to it for them.
+	CanViewAuditLogs bool `json:"canViewAuditLogs"`
+
+	// HasDraftBatchChanges is whether the user administers batch changes that
+	// were created but never applied, so that the UI can warn before they
+	// navigate away from them.
+	HasDraftBatchChanges bool `json:"hasDraftBatchChanges"`
+
+	// MaxConcurrentSearches is the number of searches the user can run at the
+	// same time before the UI warns them, from their search.maxConcurrentSearches
+	// setting. It is only a hint: the server doesn't limit concurrent searches.
+	MaxConcurrentSearches int `json:"maxConcurrentSearches"`
+
+	// Roles are the names of the RBAC roles currently assigned to the user.
+	Roles []string `json:"roles"`
+
+	// Permissions are the display names, such as BATCH_CHANGES#READ, of the
+	// RBAC permissions granted to the user by their roles, so that the UI can
+	// hide what the user isn't permitted to do.
+	Permissions []string `json:"permissions"`
+}
+
+// SettingsSubject is a subject in the settings cascade, i.e. the site, an
+// organization, or a user.
+type SettingsSubject struct {
+	// Type is the GraphQL type name of the subject: "Site", "Org", or "User".
+	Type string `json:"type"`
+	// ID is the GraphQL ID of the subject.
+	ID string `json:"id"`
+	// URL is the URL of the page to edit the subject's settings.
+	URL string `json:"url"`
+}
+
+// UserAPIRateLimit is the GraphQL API rate limit that applies to a user.
+type UserAPIRateLimit struct {
+	// Limit is the cost of the requests a user can make per window.
+	Limit int `json:"limit"`
+	// WindowSeconds is the length of the window the limit applies to.
+	WindowSeconds int `json:"windowSeconds"`
+}
+
 // JSContext is made available to JavaScript code via the
 // "sourcegraph/app/context" module.
 //
@@ -64,7 +165,12 @@ type JSContext struct {
 	AssetsRoot     string            `json:"assetsRoot"`
 	Version        string            `json:"version"`
 
-	IsAuthenticatedUser bool `json:"isAuthenticatedUser"`
+	IsAuthenticatedUser bool         `json:"isAuthenticatedUser"`
+	CurrentUser         *CurrentUser `json:"currentUser"`
+
+	// AnonymousAPIRateLimit is the GraphQL API rate limit that applies to the
+	// visitor if they are not signed in.
+	AnonymousAPIRateLimit *UserAPIRateLimit `json:"anonymousAPIRateLimit"`
 
 	SentryDSN     *string               `json:"sentryDSN"`
 	OpenTelemetry *schema.OpenTelemetry `json:"openTelemetry"`
@@ -90,12 +196,17 @@ type JSContext struct {
 
 	ResetPasswordEnabled bool `json:"resetPasswordEnabled"`
 
+	// PasswordResetLinkExpirySeconds is how long password reset links are
+	// valid, so that the reset-password screen can tell users.
+	PasswordResetLinkExpirySeconds int `json:"passwordResetLinkExpirySeconds"`
+
 	ExternalServicesUserMode string `json:"externalServicesUserMode"`
 
 	AuthMinPasswordLength int                `json:"authMinPasswordLength"`
 	AuthPasswordPolicy    authPasswordPolicy `json:"authPasswordPolicy"`
 
-	AuthProviders []authProviderInfo `json:"authProviders"`
+	AuthProviders        []authProviderInfo `json:"authProviders"`
+	BuiltinLoginDisabled bool               `json:"builtinLoginDisabled"`
 
 	Branding *schema.Branding `json:"branding"`
 
@@ -122,6 +233,176 @@ type JSContext struct {
 	OutboundRequestLogLimit int `json:"outboundRequestLogLimit"`
 
 	DisableFeedbackSurvey bool `json:"disableFeedbackSurvey"`
+
+	DefaultEditorIntegration  string `json:"defaultEditorIntegration"`
+	DefaultEditorLinkTemplate string `json:"defaultEditorLinkTemplate"`
+
+	Announcements []announcement `json:"announcements"`
+
+	RequireSignedCommits bool `json:"requireSignedCommits"`
+
+	RepoVisibilityEnforced bool `json:"repoVisibilityEnforced"`
+
+	ShareURLBase string `json:"shareURLBase"`
+
+	SyntaxHighlightingOverrides map[string]string `json:"syntaxHighlightingOverrides"`
+
+	PreferredCodeHostKind string `json:"preferredCodeHostKind"`
+
+	DefaultRepoSortOrder string `json:"defaultRepoSortOrder"`
+
+	MaxSearchResults int `json:"maxSearchResults"`
+
+	// OrgMembershipRequired is whether the current user must join or create an
+	// organization, because auth.orgMembershipRequired is enabled and they are
+	// not a member of any.
+	OrgMembershipRequired bool `json:"orgMembershipRequired"`
+
+	// CustomErrorPageContent is the sanitized HTML shown on error pages instead
+	// of the default message, or empty to show the default message.
+	CustomErrorPageContent string `json:"customErrorPageContent"`
+
+	// BackupInProgress is whether a backup of the instance is in progress, so
+	// that a banner asking users to treat it as read-only is shown.
+	BackupInProgress bool `json:"backupInProgress"`
+
+	// BackupInProgressMessage is the sanitized HTML shown in the backup banner
+	// instead of the default message, or empty to show the default message.
+	BackupInProgressMessage string `json:"backupInProgressMessage"`
+
+	// PreferredLocale is the locale, such as "en" or "pt-BR", that the UI
+	// should use before the settings are loaded.
+	PreferredLocale string `json:"preferredLocale"`
+}
+
+// ETag returns a strong entity tag for the JS context, so that a handler serving it can let
+// clients reuse a copy they already have. It covers every field except the ones that only reflect
+// the current request (XHRHeaders and UserAgentIsBot), so it changes whenever the site
+// configuration, the auth providers or the current user change.
+func (c JSContext) ETag() (string, error) {
+	c.XHRHeaders = nil
+	c.UserAgentIsBot = false
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified reports whether the request's If-None-Match header matches etag, in which case the
+// handler should respond with 304 Not Modified instead of serving the JS context again.
+func NotModified(r *http.Request, etag string) bool {
+	for _, header := range r.Header.Values("If-None-Match") {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// staticJSContext holds the parts of the JSContext that don't depend on the request. They only change
+// when the site configuration or the registered auth providers change, so they are computed once and
+// reused across requests.
+type staticJSContext struct {
+	siteID             string
+	deployType         string
+	likelyDockerOnMac  bool
+	authProviders      []authProviderInfo
+	authPasswordPolicy authPasswordPolicy
+}
+
+var (
+	staticJSContextMu sync.Mutex
+	// cachedStaticJSContext is nil when it needs to be recomputed. It is guarded by
+	// staticJSContextMu.
+	cachedStaticJSContext *staticJSContext
+	// cachedProvidersGeneration is the providers.Generation that cachedStaticJSContext was
+	// computed for. It is guarded by staticJSContextMu.
+	cachedProvidersGeneration uint64
+
+	watchStaticJSContextOnce sync.Once
+
+	getSiteID = siteid.Get // overridden in tests
+)
+
+// getStaticJSContext returns the memoized static portion of the JSContext, recomputing it if the
+// site configuration or the set of auth providers changed since it was last computed.
+func getStaticJSContext() staticJSContext {
+	watchStaticJSContextOnce.Do(func() {
+		// The watcher is registered before the static context is first computed, so that
+		// no config change can be missed. Auth providers are registered by their own config
+		// watchers, which may run after ours, so the providers generation is checked below
+		// as well.
+		conf.Watch(invalidateStaticJSContext)
+	})
+
+	staticJSContextMu.Lock()
+	defer staticJSContextMu.Unlock()
+
+	generation := providers.Generation()
+	if cachedStaticJSContext != nil && cachedProvidersGeneration == generation {
+		return *cachedStaticJSContext
+	}
+
+	static, complete := computeStaticJSContext()
+	if complete {
+		cachedStaticJSContext = &static
+		cachedProvidersGeneration = generation
+	}
+	return static
+}
+
+// invalidateStaticJSContext drops the memoized static portion of the JSContext so that it is
+// recomputed on the next request.
+func invalidateStaticJSContext() {
+	staticJSContextMu.Lock()
+	defer staticJSContextMu.Unlock()
+	cachedStaticJSContext = nil
+}
+
+// computeStaticJSContext computes the static portion of the JSContext. complete is false if an auth
+// provider hasn't fetched its information yet, in which case the result must not be cached.
+func computeStaticJSContext() (static staticJSContext, complete bool) {
+	complete = true
+
+	var authProviders []authProviderInfo
+	for _, p := range providers.Providers() {
+		if p.Config().Github != nil && p.Config().Github.Hidden {
+			continue
+		}
+		info := p.CachedInfo()
+		if info == nil {
+			complete = false
+			continue
+		}
+		authProviders = append(authProviders, authProviderInfo{
+			IsBuiltin:         p.Config().Builtin != nil,
+			DisplayName:       info.DisplayName,
+			ServiceType:       p.ConfigID().Type,
+			AuthenticationURL: info.AuthenticationURL,
+			ServiceID:         info.ServiceID,
+		})
+	}
+
+	pp := conf.AuthPasswordPolicy()
+
+	return staticJSContext{
+		siteID:            getSiteID(),
+		deployType:        deploy.Type(),
+		likelyDockerOnMac: cachedLikelyDockerOnMac(),
+		authProviders:     authProviders,
+		authPasswordPolicy: authPasswordPolicy{
+			Enabled:                   pp.Enabled,
+			NumberOfSpecialCharacters: pp.NumberOfSpecialCharacters,
+			RequireAtLeastOneNumber:   pp.RequireAtLeastOneNumber,
+			RequireUpperAndLowerCase:  pp.RequireUpperandLowerCase,
+		},
+	}, complete
 }
 
 // NewJSContextFromRequest populates a JSContext struct from the HTTP
@@ -140,38 +421,12 @@ func NewJSContextFromRequest(req *http.Request, db database.DB) JSContext {
 		headers["Cache-Control"] = "no-cache"
 	}
 
-	siteID := siteid.Get()
+	static := getStaticJSContext()
 
 	// Show the site init screen?
 	globalState, err := db.GlobalState().Get(req.Context())
 	needsSiteInit := err == nil && !globalState.Initialized
 
-	// Auth providers
-	var authProviders []authProviderInfo
-	for _, p := range providers.Providers() {
-		if p.Config().Github != nil && p.Config().Github.Hidden {
-			continue
-		}
-		info := p.CachedInfo()
-		if info != nil {
-			authProviders = append(authProviders, authProviderInfo{
-				IsBuiltin:         p.Config().Builtin != nil,
-				DisplayName:       info.DisplayName,
-				ServiceType:       p.ConfigID().Type,
-				AuthenticationURL: info.AuthenticationURL,
-				ServiceID:         info.ServiceID,
-			})
-		}
-	}
-
-	pp := conf.AuthPasswordPolicy()
-
-	var authPasswordPolicy authPasswordPolicy
-	authPasswordPolicy.Enabled = pp.Enabled
-	authPasswordPolicy.NumberOfSpecialCharacters = pp.NumberOfSpecialCharacters
-	authPasswordPolicy.RequireAtLeastOneNumber = pp.RequireAtLeastOneNumber
-	authPasswordPolicy.RequireUpperAndLowerCase = pp.RequireUpperandLowerCase
-
 	var sentryDSN *string
 	siteConfig := conf.Get().SiteConfiguration
 
@@ -184,13 +439,28 @@ func NewJSContextFromRequest(req *http.Request, db database.DB) JSContext {
 		openTelemetry = clientObservability.OpenTelemetry
 	}
 
+	defaultEditor, defaultEditorLinkTemplate := defaultEditorIntegration(conf.Get())
+	backupInProgress, backupInProgressMessage := backupInProgressBanner(conf.Get())
+
 	var licenseInfo *hooks.LicenseInfo
+	var currentUser *CurrentUser
+	var anonymousAPIRateLimit *UserAPIRateLimit
+	var requiresOrgMembership bool
+	var userLocale string
 	if !actor.IsAuthenticated() {
 		licenseInfo = hooks.GetLicenseInfo(false)
+		anonymousAPIRateLimit = apiRateLimit(conf.Get(), nil)
 	} else {
 		// Ignore err as we don't care if user does not exist
 		user, _ := actor.User(req.Context(), db.Users())
 		licenseInfo = hooks.GetLicenseInfo(user != nil && user.SiteAdmin)
+		if user != nil {
+			currentUser = createCurrentUser(req.Context(), user, db)
+			currentUser.APIRateLimit = apiRateLimit(conf.Get(), user)
+			currentUser.MaxConcurrentSearches = maxConcurrentSearches(viewerFinalSettings(req.Context(), db))
+			requiresOrgMembership = orgMembershipRequired(req.Context(), conf.Get(), user, db)
+			userLocale = settingsLocale(req.Context(), user, db)
+		}
 	}
 
 	// 🚨 SECURITY: This struct is sent to all users regardless of whether or
@@ -205,20 +475,22 @@ func NewJSContextFromRequest(req *http.Request, db database.DB) JSContext {
 		AssetsRoot:                 assetsutil.URL("").String(),
 		Version:                    version.Version(),
 		IsAuthenticatedUser:        actor.IsAuthenticated(),
+		CurrentUser:                currentUser,
+		AnonymousAPIRateLimit:      anonymousAPIRateLimit,
 		SentryDSN:                  sentryDSN,
 		OpenTelemetry:              openTelemetry,
 		RedirectUnsupportedBrowser: siteConfig.RedirectUnsupportedBrowser,
 		Debug:                      env.InsecureDev,
-		SiteID:                     siteID,
+		SiteID:                     static.siteID,
 
 		SiteGQLID: string(graphqlbackend.SiteGQLID()),
 
 		NeedsSiteInit:     needsSiteInit,
 		EmailEnabled:      conf.CanSendEmail(),
 		Site:              publicSiteConfiguration(),
-		LikelyDockerOnMac: likelyDockerOnMac(),
+		LikelyDockerOnMac: static.likelyDockerOnMac,
 		NeedServerRestart: globals.ConfigurationServerFrontendOnly.NeedServerRestart(),
-		DeployType:        deploy.Type(),
+		DeployType:        static.deployType,
 
 		SourcegraphDotComMode: envvar.SourcegraphDotComMode(),
 
@@ -228,16 +500,18 @@ func NewJSContextFromRequest(req *http.Request, db database.DB) JSContext {
 		// do the default behavior only in Go land.
 		AccessTokensAllow: conf.AccessTokensAllow(),
 
-		ResetPasswordEnabled: userpasswd.ResetPasswordEnabled(),
+		ResetPasswordEnabled:           userpasswd.ResetPasswordEnabled(),
+		PasswordResetLinkExpirySeconds: passwordResetLinkExpirySeconds(conf.Get()),
 
 		ExternalServicesUserMode: conf.ExternalServiceUserMode().String(),
 
 		AllowSignup: conf.AuthAllowSignup(),
 
 		AuthMinPasswordLength: conf.AuthMinPasswordLength(),
-		AuthPasswordPolicy:    authPasswordPolicy,
+		AuthPasswordPolicy:    static.authPasswordPolicy,
 
-		AuthProviders: authProviders,
+		AuthProviders:        static.authProviders,
+		BuiltinLoginDisabled: builtinLoginDisabled(conf.Get()),
 
 		Branding: globals.Branding(),
 
@@ -262,7 +536,209 @@ func NewJSContextFromRequest(req *http.Request, db database.DB) JSContext {
 		OutboundRequestLogLimit: conf.Get().OutboundRequestLogLimit,
 
 		DisableFeedbackSurvey: conf.Get().DisableFeedbackSurvey,
+
+		DefaultEditorIntegration:  defaultEditor,
+		DefaultEditorLinkTemplate: defaultEditorLinkTemplate,
+
+		Announcements: announcements(conf.Get()),
+
+		RequireSignedCommits: requireSignedCommits(conf.Get()),
+
+		RepoVisibilityEnforced: repoVisibilityEnforced(conf.Get()),
+
+		ShareURLBase: shareURLBase(conf.Get(), globals.ExternalURL().String()),
+
+		SyntaxHighlightingOverrides: syntaxHighlightingOverrides(conf.Get()),
+
+		PreferredCodeHostKind: preferredCodeHostKind(conf.Get()),
+
+		DefaultRepoSortOrder: defaultRepoSortOrder(conf.Get()),
+
+		MaxSearchResults: limits.DefaultMaxSearchResultsStreaming,
+
+		OrgMembershipRequired: requiresOrgMembership,
+
+		CustomErrorPageContent: customErrorPageContent(conf.Get()),
+
+		BackupInProgress:        backupInProgress,
+		BackupInProgressMessage: backupInProgressMessage,
+
+		PreferredLocale: preferredLocale(userLocale, req.Header.Get("Accept-Language")),
+	}
+}
+
+// createCurrentUser returns the CurrentUser for the given authenticated user.
+func createCurrentUser(ctx context.Context, user *types.User, db database.DB) *CurrentUser {
+	currentUser := &CurrentUser{
+		ID:         string(graphqlbackend.MarshalUserID(user.ID)),
+		DatabaseID: user.ID,
+		Username:   user.Username,
+		SiteAdmin:  user.SiteAdmin,
+		Tags:       user.Tags,
+	}
+
+	// 🚨 SECURITY: Only site admins can see the state of the permissions sync.
+	if user.SiteAdmin {
+		currentUser.RepoPermissionsSyncHealthy = isRepoPermissionsSyncHealthy(ctx, db)
+	}
+
+	currentUser.EditableSettingsSubjects = editableSettingsSubjects(ctx, user, db)
+	currentUser.HasNotifyingSavedSearches = hasNotifyingSavedSearches(ctx, user, db)
+	currentUser.TemporaryAdmin = isTemporaryAdmin(ctx, user, db)
+	// Support bundles, incoming webhooks, and the audit log are limited to site
+	// admins, including temporary ones. This mirrors the checks of the APIs
+	// behind them.
+	siteAdmin := user.SiteAdmin || currentUser.TemporaryAdmin
+	currentUser.CanGenerateSupportBundle = siteAdmin
+	currentUser.CanManageWebhooks = siteAdmin
+	currentUser.CanViewAuditLogs = siteAdmin
+	currentUser.HasDraftBatchChanges = hasDraftBatchChanges(ctx, user)
+	currentUser.Roles, currentUser.Permissions = rolesAndPermissions(ctx, user, db)
+
+	return currentUser
+}
+
+// rolesAndPermissions returns the names of the roles currently assigned to the
+// user and the display names of the permissions granted by them, sorted. Each
+// is listed with a single query, rather than one per role. If either can't be
+// listed, both are empty.
+func rolesAndPermissions(ctx context.Context, user *types.User, db database.DB) (roleNames, permissionNames []string) {
+	roles, err := db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+		UserID:         user.ID,
+	})
+	if err != nil {
+		return []string{}, []string{}
+	}
+	permissions, err := db.Permissions().List(ctx, database.PermissionListOpts{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+		UserID:         user.ID,
+	})
+	if err != nil {
+		return []string{}, []string{}
+	}
+
+	roleNames = make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	// A permission is listed once for every role of the user that grants it.
+	permissionNames = make([]string, 0, len(permissions))
+	seen := make(map[int32]struct{}, len(permissions))
+	for _, permission := range permissions {
+		if _, ok := seen[permission.ID]; ok {
+			continue
+		}
+		seen[permission.ID] = struct{}{}
+		permissionNames = append(permissionNames, permission.DisplayName())
+	}
+	sort.Strings(roleNames)
+	sort.Strings(permissionNames)
+	return roleNames, permissionNames
+}
+
+// editableSettingsSubjects returns the settings subjects whose settings the
+// user can edit, from the most to the least general: the global settings for
+// site admins, the organizations the user is a member of, and the user. If the
+// user's organizations can't be listed, they are omitted.
+func editableSettingsSubjects(ctx context.Context, user *types.User, db database.DB) []SettingsSubject {
+	var subjects []SettingsSubject
+
+	// 🚨 SECURITY: Only site admins can edit the global settings.
+	if user.SiteAdmin {
+		subjects = append(subjects, SettingsSubject{
+			Type: "Site",
+			ID:   string(graphqlbackend.SiteGQLID()),
+			URL:  "/site-admin/global-settings",
+		})
+	}
+
+	// Site admins can edit the settings of any organization, but only list the
+	// ones they are a member of so that the editor isn't flooded.
+	orgs, err := db.Orgs().GetByUserID(ctx, user.ID)
+	if err == nil {
+		for _, org := range orgs {
+			subjects = append(subjects, SettingsSubject{
+				Type: "Org",
+				ID:   string(graphqlbackend.MarshalOrgID(org.ID)),
+				URL:  "/organizations/" + org.Name + "/settings",
+			})
+		}
+	}
+
+	return append(subjects, SettingsSubject{
+		Type: "User",
+		ID:   string(graphqlbackend.MarshalUserID(user.ID)),
+		URL:  "/users/" + user.Username + "/settings",
+	})
+}
+
+// isTemporaryAdmin reports whether the user, who isn't a site admin, has site
+// admin access through a temporary assignment of the ADMIN system role. If the
+// assignment can't be looked up, it returns false.
+func isTemporaryAdmin(ctx context.Context, user *types.User, db database.DB) bool {
+	if user.SiteAdmin {
+		return false
+	}
+	expiresAt, err := db.Users().TemporaryAdminExpiresAt(ctx, user.ID)
+	return err == nil && !expiresAt.IsZero()
+}
+
+// hasDraftBatchChanges reports whether the user administers draft batch
+// changes. If the batch changes can't be counted, it returns false.
+func hasDraftBatchChanges(ctx context.Context, user *types.User) bool {
+	hasDrafts, err := hooks.HasDraftBatchChanges(ctx, user.ID)
+	return err == nil && hasDrafts
+}
+
+// hasNotifyingSavedSearches reports whether any of the saved searches of the
+// user or their organizations notifies by email or Slack. If the saved
+// searches can't be listed, it returns false.
+func hasNotifyingSavedSearches(ctx context.Context, user *types.User, db database.DB) bool {
+	savedSearches, err := db.SavedSearches().ListSavedSearchesByUserID(ctx, user.ID)
+	if err != nil {
+		return false
 	}
+	for _, savedSearch := range savedSearches {
+		if savedSearch.Notify || savedSearch.NotifySlack {
+			return true
+		}
+	}
+	return false
+}
+
+// repoPermissionsSyncStalledAfter is how long a permissions sync job can wait
+// to be processed before the permissions sync is considered stalled.
+const repoPermissionsSyncStalledAfter = time.Hour
+
+// isRepoPermissionsSyncHealthy reports whether permissions sync jobs are being
+// processed. If the state of the sync can't be determined, it is assumed to be
+// healthy so that admins aren't warned spuriously.
+func isRepoPermissionsSyncHealthy(ctx context.Context, db database.DB) bool {
+	first := 1
+	jobs, err := db.PermissionSyncJobs().List(ctx, database.ListPermissionSyncJobOpts{
+		State:       "queued",
+		NotCanceled: true,
+		PaginationArgs: &database.PaginationArgs{
+			First:     &first,
+			OrderBy:   database.OrderBy{{Field: "COALESCE(process_after, queued_at)"}},
+			Ascending: true,
+		},
+	})
+	if err != nil || len(jobs) == 0 {
+		return true
+	}
+	return repoPermissionsSyncHealthy(jobs[0], time.Now())
+}
+
+// repoPermissionsSyncHealthy reports whether the permissions sync is healthy
+// given the queued job that has been waiting the longest to be processed.
+func repoPermissionsSyncHealthy(oldestQueuedJob *database.PermissionSyncJob, now time.Time) bool {
+	waitingSince := oldestQueuedJob.QueuedAt
+	if oldestQueuedJob.ProcessAfter.After(waitingSince) {
+		waitingSince = oldestQueuedJob.ProcessAfter
+	}
+	return now.Sub(waitingSince) < repoPermissionsSyncStalledAfter
 }
 
 // publicSiteConfiguration is the subset of the site.schema.json site
@@ -281,19 +757,340 @@ func publicSiteConfiguration() schema.SiteConfiguration {
 	}
 }
 
+// builtinLoginDisabled reports whether the builtin username/password login is
+// unavailable because no builtin auth provider is configured, i.e. the instance
+// only allows signing in through SSO.
+func builtinLoginDisabled(c *conf.Unified) bool {
+	for _, p := range c.AuthProviders {
+		if p.Builtin != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultEditorIntegration returns the editor that "open in editor" links are
+// preconfigured for, and the link template to use for it. Both are empty if no
+// default editor is configured, or if a custom editor is configured without a
+// link template.
+func defaultEditorIntegration(c *conf.Unified) (editor, linkTemplate string) {
+	cfg := c.OpenInEditorDefault
+	if cfg == nil || cfg.EditorId == "" {
+		return "", ""
+	}
+	if cfg.EditorId == "custom" && cfg.UrlPattern == "" {
+		return "", ""
+	}
+	return cfg.EditorId, cfg.UrlPattern
+}
+
+// announcements returns the configured instance-wide announcements. Announcements
+// without a severity default to "info".
+func announcements(c *conf.Unified) []announcement {
+	result := make([]announcement, 0, len(c.Announcements))
+	for _, a := range c.Announcements {
+		severity := a.Severity
+		if severity == "" {
+			severity = "info"
+		}
+		result = append(result, announcement{
+			ID:          a.Id,
+			Message:     a.Message,
+			Severity:    severity,
+			Dismissible: a.Dismissible,
+		})
+	}
+	return result
+}
+
+// requireSignedCommits returns whether the UI should warn users that commits
+// are required to be signed.
+func requireSignedCommits(c *conf.Unified) bool {
+	return c.RequireSignedCommits
+}
+
+// apiRateLimitWindow is the window that API rate limits apply to, see
+// graphqlbackend.RateLimitWatcher.
+const apiRateLimitWindow = time.Hour
+
+// apiRateLimit returns the GraphQL API rate limit that applies to user, or to
+// anonymous visitors if user is nil. It returns nil if API rate limiting is
+// disabled or the user is not limited.
+func apiRateLimit(c *conf.Unified, user *types.User) *UserAPIRateLimit {
+	rlc := c.ApiRatelimit
+	if rlc == nil || !rlc.Enabled {
+		return nil
+	}
+
+	limit := rlc.PerIP
+	if user != nil {
+		limit = rlc.PerUser
+		// Overrides are keyed by the same UID the rate limiter uses, which is
+		// the user ID for authenticated users.
+		uid := strconv.Itoa(int(user.ID))
+		for _, o := range rlc.Overrides {
+			if o.Key != uid {
+				continue
+			}
+			switch l := o.Limit.(type) {
+			case string:
+				if l == "unlimited" {
+					return nil
+				}
+				if l == "blocked" {
+					limit = 0
+				}
+			case int:
+				limit = l
+			case float64:
+				limit = int(l)
+			}
+		}
+	}
+
+	return &UserAPIRateLimit{
+		Limit:         limit,
+		WindowSeconds: int(apiRateLimitWindow.Seconds()),
+	}
+}
+
+// repoVisibilityEnforced returns whether the UI should hide controls for sharing
+// repositories publicly.
+func repoVisibilityEnforced(c *conf.Unified) bool {
+	return c.EnforceRepoVisibility
+}
+
+// shareURLBase returns the base URL that the UI uses for share links, which is
+// the external URL unless another base is configured.
+func shareURLBase(c *conf.Unified, externalURL string) string {
+	if c.ShareURLBase != "" {
+		return strings.TrimSuffix(c.ShareURLBase, "/")
+	}
+	return externalURL
+}
+
+// syntaxHighlightingOverrides returns the configured mapping of file extensions
+// to the languages that files with these extensions are highlighted as. It is
+// never nil.
+func syntaxHighlightingOverrides(c *conf.Unified) map[string]string {
+	overrides := map[string]string{}
+	if c.SyntaxHighlighting != nil {
+		for extension, language := range c.SyntaxHighlighting.Languages.Extensions {
+			overrides[extension] = language
+		}
+	}
+	return overrides
+}
+
+// preferredCodeHostKind returns the kind of code host that the UI preselects
+// when a code host connection is added, or "" if there is no preference.
+func preferredCodeHostKind(c *conf.Unified) string {
+	return c.PreferredCodeHostKind
+}
+
+// defaultRepoSortOrder returns the order in which the UI sorts repository lists
+// by default.
+func defaultRepoSortOrder(c *conf.Unified) string {
+	if c.DefaultRepoSortOrder == "" {
+		return "name"
+	}
+	return c.DefaultRepoSortOrder
+}
+
+// defaultMaxConcurrentSearches is the number of searches the UI lets a user run
+// at the same time before warning them, if search.maxConcurrentSearches is unset.
+const defaultMaxConcurrentSearches = 10
+
+// viewerFinalSettings returns the merged settings of the current user, or nil if
+// they can't be read.
+func viewerFinalSettings(ctx context.Context, db database.DB) *schema.Settings {
+	settings, err := graphqlbackend.DecodedViewerFinalSettings(ctx, db)
+	if err != nil {
+		return nil
+	}
+	return settings
+}
+
+// maxConcurrentSearches returns the number of searches a user can run at the
+// same time before the UI warns them, as set by search.maxConcurrentSearches in
+// their settings.
+func maxConcurrentSearches(settings *schema.Settings) int {
+	if settings == nil || settings.SearchMaxConcurrentSearches <= 0 {
+		return defaultMaxConcurrentSearches
+	}
+	return settings.SearchMaxConcurrentSearches
+}
+
+// passwordResetLinkExpirySeconds returns how long password reset links are
+// valid, in seconds, as configured by auth.passwordResetLinkExpiry.
+func passwordResetLinkExpirySeconds(c *conf.Unified) int {
+	if c.AuthPasswordResetLinkExpiry <= 0 {
+		return conf.DefaultPasswordResetLinkExpiry
+	}
+	return c.AuthPasswordResetLinkExpiry
+}
+
+// customErrorPageContent returns the configured Markdown content for error
+// pages rendered as sanitized HTML, or "" if none is configured or it can't be
+// rendered.
+func customErrorPageContent(c *conf.Unified) string {
+	if strings.TrimSpace(c.CustomErrorPageContent) == "" {
+		return ""
+	}
+	html, err := markdown.Render(c.CustomErrorPageContent)
+	if err != nil {
+		return ""
+	}
+	return html
+}
+
+// backupInProgressBanner returns whether the backup banner is shown and its
+// configured Markdown message rendered as sanitized HTML. The message is ""
+// if the banner isn't shown, or if no message is configured or it can't be
+// rendered.
+func backupInProgressBanner(c *conf.Unified) (enabled bool, message string) {
+	if c.BackupInProgress == nil || !c.BackupInProgress.Enabled {
+		return false, ""
+	}
+	if strings.TrimSpace(c.BackupInProgress.Message) == "" {
+		return true, ""
+	}
+	html, err := markdown.Render(c.BackupInProgress.Message)
+	if err != nil {
+		return true, ""
+	}
+	return true, html
+}
+
+// supportedLocales are the locales that the UI can be shown in. The first one
+// is the default.
+var supportedLocales = []language.Tag{
+	language.English,
+	language.German,
+	language.Spanish,
+	language.French,
+	language.Japanese,
+	language.Korean,
+	language.BrazilianPortuguese,
+	language.SimplifiedChinese,
+}
+
+var localeMatcher = language.NewMatcher(supportedLocales)
+
+// settingsLocale returns the locale set in the user's latest settings, or ""
+// if there is none.
+func settingsLocale(ctx context.Context, user *types.User, db database.DB) string {
+	settings, err := db.Settings().GetLatest(ctx, api.SettingsSubject{User: &user.ID})
+	if err != nil || settings == nil {
+		return ""
+	}
+	var v struct {
+		Locale string `json:"locale"`
+	}
+	if err := jsonc.Unmarshal(settings.Contents, &v); err != nil {
+		return ""
+	}
+	return v.Locale
+}
+
+// preferredLocale returns the supported locale that best matches the locale
+// from the user's settings or, if it isn't set or supported, the request's
+// Accept-Language header. It defaults to English.
+func preferredLocale(userLocale, acceptLanguage string) string {
+	if tag, err := language.Parse(userLocale); err == nil {
+		if locale, ok := matchLocale(tag); ok {
+			return locale
+		}
+	}
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+		if locale, ok := matchLocale(tags...); ok {
+			return locale
+		}
+	}
+	return supportedLocales[0].String()
+}
+
+// matchLocale returns the supported locale that best matches the given tags,
+// which are in order of preference.
+func matchLocale(tags ...language.Tag) (string, bool) {
+	_, index, confidence := localeMatcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supportedLocales[index].String(), true
+}
+
+// orgMembershipRequired reports whether the user must join or create an
+// organization, because auth.orgMembershipRequired is enabled and they are not
+// a member of any. If the user's organizations can't be listed, it returns
+// false rather than blocking them.
+func orgMembershipRequired(ctx context.Context, c *conf.Unified, user *types.User, db database.DB) bool {
+	if !c.AuthOrgMembershipRequired {
+		return false
+	}
+	orgs, err := db.Orgs().GetByUserID(ctx, user.ID)
+	if err != nil {
+		return false
+	}
+	return len(orgs) == 0
+}
+
 var isBotPat = lazyregexp.New(`(?i:googlecloudmonitoring|pingdom.com|go .* package http|sourcegraph e2etest|bot|crawl|slurp|spider|feed|rss|camo asset proxy|http-client|sourcegraph-client)`)
 
 func isBot(userAgent string) bool {
 	return isBotPat.MatchString(userAgent)
 }
 
-func likelyDockerOnMac() bool {
-	r := net.DefaultResolver
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+// dockerOnMacProbeTimeout bounds how long the Docker for Mac probe may take. We assume we're not
+// running in Docker for Mac if it takes longer.
+const dockerOnMacProbeTimeout = time.Second
+
+var (
+	dockerOnMacOnce sync.Once
+	dockerOnMac     bool
+
+	// dockerOnMacResolver is used to probe for Docker for Mac.
+	dockerOnMacResolver hostResolver = net.DefaultResolver
+
+	// mockLikelyDockerOnMac, if set, is returned instead of probing for Docker for Mac, so that
+	// tests don't make DNS requests.
+	mockLikelyDockerOnMac *bool
+)
+
+// cachedLikelyDockerOnMac returns whether we're likely running in Docker for Mac. The probe only
+// runs once per process.
+func cachedLikelyDockerOnMac() bool {
+	if mockLikelyDockerOnMac != nil {
+		return *mockLikelyDockerOnMac
+	}
+	dockerOnMacOnce.Do(func() {
+		dockerOnMac = likelyDockerOnMac(dockerOnMacResolver, dockerOnMacProbeTimeout)
+	})
+	return dockerOnMac
+}
+
+// hostResolver is the subset of *net.Resolver used to probe for Docker for Mac.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// likelyDockerOnMac looks up the host.docker.internal host name that Docker for Mac provides. It
+// returns false if the lookup doesn't complete within timeout, even if r doesn't respect the
+// context deadline.
+func likelyDockerOnMac(r hostResolver, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	addrs, err := r.LookupHost(ctx, "host.docker.internal")
-	if err != nil || len(addrs) == 0 {
-		return false //  Assume we're not docker for mac.
+
+	found := make(chan bool, 1)
+	go func() {
+		addrs, err := r.LookupHost(ctx, "host.docker.internal")
+		found <- err == nil && len(addrs) > 0
+	}()
+
+	select {
+	case ok := <-found:
+		return ok
+	case <-ctx.Done():
+		return false // Assume we're not docker for mac.
 	}
-	return true
 }
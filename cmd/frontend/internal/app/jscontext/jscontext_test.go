@@ -1,8 +1,26 @@
 package jscontext
 
 import (
-	"runtime"
+	"context"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/siteid"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/search/limits"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestIsBot(t *testing.T) {
@@ -19,23 +37,1047 @@ func TestIsBot(t *testing.T) {
 	}
 }
 
+type fakeHostResolver func(ctx context.Context, host string) ([]string, error)
+
+func (f fakeHostResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f(ctx, host)
+}
+
 func Test_likelyDockerOnMac(t *testing.T) {
-	if runtime.GOOS != "linux" {
-		t.SkipNow()
+	// blocked is closed at the end of the test, to release the resolver that ignores the
+	// context deadline.
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+
+	tests := []struct {
+		name     string
+		resolver fakeHostResolver
+		want     bool
+	}{
+		{
+			name: "found",
+			resolver: func(context.Context, string) ([]string, error) {
+				return []string{"192.168.65.2"}, nil
+			},
+			want: true,
+		},
+		{
+			name: "not found",
+			resolver: func(context.Context, string) ([]string, error) {
+				return nil, errors.New("no such host")
+			},
+			want: false,
+		},
+		{
+			name: "times out",
+			resolver: func(ctx context.Context, _ string) ([]string, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			want: false,
+		},
+		{
+			name: "ignores the deadline",
+			resolver: func(context.Context, string) ([]string, error) {
+				<-blocked
+				return []string{"192.168.65.2"}, nil
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			if got := likelyDockerOnMac(tt.resolver, 10*time.Millisecond); got != tt.want {
+				t.Errorf("likelyDockerOnMac() = %v, want %v", got, tt.want)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("likelyDockerOnMac() took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestBuiltinLoginDisabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []schema.AuthProviders
+		want      bool
+	}{
+		{
+			name:      "builtin only",
+			providers: []schema.AuthProviders{{Builtin: &schema.BuiltinAuthProvider{Type: "builtin"}}},
+			want:      false,
+		},
+		{
+			name: "SSO only",
+			providers: []schema.AuthProviders{
+				{Saml: &schema.SAMLAuthProvider{Type: "saml"}},
+				{Openidconnect: &schema.OpenIDConnectAuthProvider{Type: "openidconnect"}},
+			},
+			want: true,
+		},
+		{
+			name: "builtin and SSO",
+			providers: []schema.AuthProviders{
+				{Github: &schema.GitHubAuthProvider{Type: "github"}},
+				{Builtin: &schema.BuiltinAuthProvider{Type: "builtin"}},
+			},
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthProviders: test.providers}}
+			if got := builtinLoginDisabled(c); got != test.want {
+				t.Errorf("want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDefaultEditorIntegration(t *testing.T) {
+	tests := []struct {
+		name             string
+		cfg              *schema.DefaultEditorIntegration
+		wantEditor       string
+		wantLinkTemplate string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:       "configured editor",
+			cfg:        &schema.DefaultEditorIntegration{EditorId: "vscode"},
+			wantEditor: "vscode",
+		},
+		{
+			name:             "custom editor",
+			cfg:              &schema.DefaultEditorIntegration{EditorId: "custom", UrlPattern: "idea://open?file=%file&line=%line&column=%col"},
+			wantEditor:       "custom",
+			wantLinkTemplate: "idea://open?file=%file&line=%line&column=%col",
+		},
+		{
+			name: "custom editor without link template",
+			cfg:  &schema.DefaultEditorIntegration{EditorId: "custom"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{OpenInEditorDefault: test.cfg}}
+			editor, linkTemplate := defaultEditorIntegration(c)
+			if editor != test.wantEditor {
+				t.Errorf("editor: want %q, got %q", test.wantEditor, editor)
+			}
+			if linkTemplate != test.wantLinkTemplate {
+				t.Errorf("link template: want %q, got %q", test.wantLinkTemplate, linkTemplate)
+			}
+		})
+	}
+}
+
+func TestAnnouncements(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		got := announcements(&conf.Unified{})
+		if len(got) != 0 {
+			t.Errorf("want no announcements, got %+v", got)
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			Announcements: []*schema.Announcement{
+				{Id: "maintenance", Message: "Maintenance on Saturday", Severity: "warning", Dismissible: true},
+				{Id: "outage", Message: "Search is degraded", Severity: "error"},
+				{Id: "welcome", Message: "Welcome!"},
+			},
+		}}
+		want := []announcement{
+			{ID: "maintenance", Message: "Maintenance on Saturday", Severity: "warning", Dismissible: true},
+			{ID: "outage", Message: "Search is degraded", Severity: "error"},
+			{ID: "welcome", Message: "Welcome!", Severity: "info"},
+		}
+		if diff := cmp.Diff(want, announcements(c)); diff != "" {
+			t.Errorf("announcements mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestRequireSignedCommits(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{RequireSignedCommits: enabled}}
+		if got := requireSignedCommits(c); got != enabled {
+			t.Errorf("requireSignedCommits = %v, want %v", got, enabled)
+		}
+	}
+
+	if requireSignedCommits(&conf.Unified{}) {
+		t.Error("requireSignedCommits should default to false")
+	}
+}
+
+func TestRepoVisibilityEnforced(t *testing.T) {
+	for _, enforced := range []bool{true, false} {
+		c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{EnforceRepoVisibility: enforced}}
+		if got := repoVisibilityEnforced(c); got != enforced {
+			t.Errorf("repoVisibilityEnforced = %v, want %v", got, enforced)
+		}
+	}
+
+	if repoVisibilityEnforced(&conf.Unified{}) {
+		t.Error("repoVisibilityEnforced should default to false")
+	}
+}
+
+func TestShareURLBase(t *testing.T) {
+	externalURL := "https://sourcegraph.example.com"
+
+	if got := shareURLBase(&conf.Unified{}, externalURL); got != externalURL {
+		t.Errorf("shareURLBase = %q, want the external URL %q", got, externalURL)
+	}
+
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{ShareURLBase: "https://share.example.com/"}}
+	if got, want := shareURLBase(c, externalURL), "https://share.example.com"; got != want {
+		t.Errorf("shareURLBase = %q, want %q", got, want)
+	}
+}
+
+func TestSyntaxHighlightingOverrides(t *testing.T) {
+	if got := syntaxHighlightingOverrides(&conf.Unified{}); got == nil || len(got) != 0 {
+		t.Errorf("syntaxHighlightingOverrides = %v, want an empty map", got)
+	}
+
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		SyntaxHighlighting: &schema.SyntaxHighlighting{
+			Languages: schema.SyntaxHighlightingLanguage{
+				Extensions: map[string]string{"tsx": "typescript", "tmpl": "go"},
+			},
+		},
+	}}
+	want := map[string]string{"tsx": "typescript", "tmpl": "go"}
+	if diff := cmp.Diff(want, syntaxHighlightingOverrides(c)); diff != "" {
+		t.Errorf("syntaxHighlightingOverrides mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPreferredCodeHostKind(t *testing.T) {
+	if got := preferredCodeHostKind(&conf.Unified{}); got != "" {
+		t.Errorf("preferredCodeHostKind = %q, want no preference", got)
 	}
 
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{PreferredCodeHostKind: "GITLAB"}}
+	if got, want := preferredCodeHostKind(c), "GITLAB"; got != want {
+		t.Errorf("preferredCodeHostKind = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultRepoSortOrder(t *testing.T) {
+	if got, want := defaultRepoSortOrder(&conf.Unified{}), "name"; got != want {
+		t.Errorf("defaultRepoSortOrder = %q, want %q", got, want)
+	}
+
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{DefaultRepoSortOrder: "recently-updated"}}
+	if got, want := defaultRepoSortOrder(c), "recently-updated"; got != want {
+		t.Errorf("defaultRepoSortOrder = %q, want %q", got, want)
+	}
+}
+
+func TestMaxSearchResults(t *testing.T) {
+	resetStaticJSContext(t)
+	conf.Mock(&conf.Unified{})
+	t.Cleanup(func() { conf.Mock(nil) })
+	configurationServer := globals.ConfigurationServerFrontendOnly
+	globals.ConfigurationServerFrontendOnly = &conf.Server{}
+	t.Cleanup(func() { globals.ConfigurationServerFrontendOnly = configurationServer })
+
+	db := database.NewMockDB()
+	db.GlobalStateFunc.SetDefaultReturn(database.NewMockGlobalStateStore())
+
+	// Streaming search doesn't read its default result count from the site
+	// configuration, so neither does the UI.
+	req := httptest.NewRequest("GET", "/", nil)
+	if got, want := NewJSContextFromRequest(req, db).MaxSearchResults, limits.DefaultMaxSearchResultsStreaming; got != want {
+		t.Errorf("MaxSearchResults = %d, want %d", got, want)
+	}
+}
+
+func TestMaxConcurrentSearches(t *testing.T) {
+	for _, settings := range []*schema.Settings{nil, {}} {
+		if got, want := maxConcurrentSearches(settings), 10; got != want {
+			t.Errorf("maxConcurrentSearches(%v) = %d, want the default %d", settings, got, want)
+		}
+	}
+
+	// The value comes from the user's merged settings.
+	graphqlbackend.MockDecodedViewerFinalSettings = &schema.Settings{SearchMaxConcurrentSearches: 3}
+	t.Cleanup(func() { graphqlbackend.MockDecodedViewerFinalSettings = nil })
+	if got, want := maxConcurrentSearches(viewerFinalSettings(context.Background(), database.NewMockDB())), 3; got != want {
+		t.Errorf("maxConcurrentSearches = %d, want %d", got, want)
+	}
+}
+
+func TestPasswordResetLinkExpirySeconds(t *testing.T) {
+	if got, want := passwordResetLinkExpirySeconds(&conf.Unified{}), 14400; got != want {
+		t.Errorf("passwordResetLinkExpirySeconds = %d, want the default %d", got, want)
+	}
+
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthPasswordResetLinkExpiry: 3600}}
+	if got, want := passwordResetLinkExpirySeconds(c), 3600; got != want {
+		t.Errorf("passwordResetLinkExpirySeconds = %d, want %d", got, want)
+	}
+}
+
+func TestCreateCurrentUser(t *testing.T) {
+	now := time.Now()
+
+	newDB := func(queuedJobs ...*database.PermissionSyncJob) *database.MockDB {
+		permissionSyncJobs := database.NewMockPermissionSyncJobStore()
+		permissionSyncJobs.ListFunc.SetDefaultReturn(queuedJobs, nil)
+		db := database.NewMockDB()
+		db.PermissionSyncJobsFunc.SetDefaultReturn(permissionSyncJobs)
+		db.OrgsFunc.SetDefaultReturn(database.NewMockOrgStore())
+		db.SavedSearchesFunc.SetDefaultReturn(database.NewMockSavedSearchStore())
+		db.RolesFunc.SetDefaultReturn(database.NewMockRoleStore())
+		db.PermissionsFunc.SetDefaultReturn(database.NewMockPermissionStore())
+		db.UsersFunc.SetDefaultReturn(database.NewMockUserStore())
+		return db
+	}
+
+	siteSubject := SettingsSubject{Type: "Site", ID: "U2l0ZToic2l0ZSI=", URL: "/site-admin/global-settings"}
+	adminSubject := SettingsSubject{Type: "User", ID: "VXNlcjox", URL: "/users/admin/settings"}
+
+	tests := []struct {
+		name string
+		user *types.User
+		db   *database.MockDB
+		want *CurrentUser
+	}{
+		{
+			name: "site admin with healthy sync",
+			user: &types.User{ID: 1, Username: "admin", SiteAdmin: true},
+			db:   newDB(&database.PermissionSyncJob{QueuedAt: now.Add(-time.Minute)}),
+			want: &CurrentUser{ID: "VXNlcjox", DatabaseID: 1, Username: "admin", SiteAdmin: true, RepoPermissionsSyncHealthy: true, EditableSettingsSubjects: []SettingsSubject{siteSubject, adminSubject}, CanGenerateSupportBundle: true, CanManageWebhooks: true, CanViewAuditLogs: true, Roles: []string{}, Permissions: []string{}},
+		},
+		{
+			name: "site admin without queued jobs",
+			user: &types.User{ID: 1, Username: "admin", SiteAdmin: true},
+			db:   newDB(),
+			want: &CurrentUser{ID: "VXNlcjox", DatabaseID: 1, Username: "admin", SiteAdmin: true, RepoPermissionsSyncHealthy: true, EditableSettingsSubjects: []SettingsSubject{siteSubject, adminSubject}, CanGenerateSupportBundle: true, CanManageWebhooks: true, CanViewAuditLogs: true, Roles: []string{}, Permissions: []string{}},
+		},
+		{
+			name: "site admin with stalled sync",
+			user: &types.User{ID: 1, Username: "admin", SiteAdmin: true},
+			db:   newDB(&database.PermissionSyncJob{QueuedAt: now.Add(-2 * time.Hour)}),
+			want: &CurrentUser{ID: "VXNlcjox", DatabaseID: 1, Username: "admin", SiteAdmin: true, RepoPermissionsSyncHealthy: false, EditableSettingsSubjects: []SettingsSubject{siteSubject, adminSubject}, CanGenerateSupportBundle: true, CanManageWebhooks: true, CanViewAuditLogs: true, Roles: []string{}, Permissions: []string{}},
+		},
+		{
+			name: "regular user",
+			user: &types.User{ID: 2, Username: "alice", Tags: []string{"beta"}},
+			db:   newDB(),
+			want: &CurrentUser{ID: "VXNlcjoy", DatabaseID: 2, Username: "alice", Tags: []string{"beta"}, EditableSettingsSubjects: []SettingsSubject{
+				{Type: "User", ID: "VXNlcjoy", URL: "/users/alice/settings"},
+			}, Roles: []string{}, Permissions: []string{}},
+		},
+		{
+			name: "temporary admin",
+			user: &types.User{ID: 3, Username: "bob"},
+			db: func() *database.MockDB {
+				db := newDB()
+				users := database.NewMockUserStore()
+				users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(now.Add(time.Hour), nil)
+				db.UsersFunc.SetDefaultReturn(users)
+				return db
+			}(),
+			// Temporary admins aren't reported as site admins, but can use what is limited to site admins.
+			want: &CurrentUser{ID: "VXNlcjoz", DatabaseID: 3, Username: "bob", EditableSettingsSubjects: []SettingsSubject{
+				{Type: "User", ID: "VXNlcjoz", URL: "/users/bob/settings"},
+			}, TemporaryAdmin: true, CanGenerateSupportBundle: true, CanManageWebhooks: true, CanViewAuditLogs: true, Roles: []string{}, Permissions: []string{}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := createCurrentUser(context.Background(), test.user, test.db)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("current user mismatch (-want +got):\n%s", diff)
+			}
+			// 🚨 SECURITY: The permissions sync state must not be queried for non-admins.
+			if !test.user.SiteAdmin && len(test.db.PermissionSyncJobsFunc.History()) > 0 {
+				t.Error("permissions sync state was queried for a non-admin user")
+			}
+		})
+	}
+}
+
+func TestEditableSettingsSubjects(t *testing.T) {
+	// alice is a member of the acme org, and can edit its settings, but not of
+	// the other org.
+	acme := &types.Org{ID: 1, Name: "acme"}
+	other := &types.Org{ID: 2, Name: "other"}
+	members := map[int32][]*types.Org{2: {acme}, 3: {other}}
+
+	orgs := database.NewMockOrgStore()
+	orgs.GetByUserIDFunc.SetDefaultHook(func(_ context.Context, userID int32) ([]*types.Org, error) {
+		return members[userID], nil
+	})
+	db := database.NewMockDB()
+	db.OrgsFunc.SetDefaultReturn(orgs)
+
+	tests := []struct {
+		name string
+		user *types.User
+		want []SettingsSubject
+	}{
+		{
+			name: "org member",
+			user: &types.User{ID: 2, Username: "alice"},
+			want: []SettingsSubject{
+				{Type: "Org", ID: "T3JnOjE=", URL: "/organizations/acme/settings"},
+				{Type: "User", ID: "VXNlcjoy", URL: "/users/alice/settings"},
+			},
+		},
+		{
+			name: "site admin without orgs",
+			user: &types.User{ID: 1, Username: "admin", SiteAdmin: true},
+			want: []SettingsSubject{
+				{Type: "Site", ID: "U2l0ZToic2l0ZSI=", URL: "/site-admin/global-settings"},
+				{Type: "User", ID: "VXNlcjox", URL: "/users/admin/settings"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := editableSettingsSubjects(context.Background(), test.user, db)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("editable settings subjects mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsTemporaryAdmin(t *testing.T) {
+	users := database.NewMockUserStore()
+	users.TemporaryAdminExpiresAtFunc.SetDefaultHook(func(_ context.Context, id int32) (time.Time, error) {
+		switch id {
+		case 3:
+			return time.Now().Add(time.Hour), nil
+		case 4:
+			return time.Time{}, errors.New("boom")
+		}
+		return time.Time{}, nil
+	})
+	db := database.NewMockDB()
+	db.UsersFunc.SetDefaultReturn(users)
+
 	tests := []struct {
 		name string
+		user *types.User
 		want bool
 	}{
-		{"base",
-			false},
+		{
+			name: "site admin",
+			user: &types.User{ID: 1, SiteAdmin: true},
+			want: false,
+		},
+		{
+			name: "non-admin",
+			user: &types.User{ID: 2},
+			want: false,
+		},
+		{
+			name: "temporary site admin",
+			user: &types.User{ID: 3},
+			want: true,
+		},
+		{
+			name: "error",
+			user: &types.User{ID: 4},
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isTemporaryAdmin(context.Background(), test.user, db); got != test.want {
+				t.Errorf("isTemporaryAdmin() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasDraftBatchChanges(t *testing.T) {
+	orig := hooks.HasDraftBatchChanges
+	t.Cleanup(func() { hooks.HasDraftBatchChanges = orig })
+
+	drafts := map[int32]bool{1: true}
+	hooks.HasDraftBatchChanges = func(_ context.Context, userID int32) (bool, error) {
+		if userID == 3 {
+			return false, errors.New("boom")
+		}
+		return drafts[userID], nil
+	}
+
+	tests := []struct {
+		name string
+		user *types.User
+		want bool
+	}{
+		{name: "with drafts", user: &types.User{ID: 1}, want: true},
+		{name: "without drafts", user: &types.User{ID: 2}, want: false},
+		{name: "error", user: &types.User{ID: 3}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := likelyDockerOnMac(); got != tt.want {
-				t.Errorf("likelyDockerOnMac() = %v, want %v", got, tt.want)
+			if got := hasDraftBatchChanges(context.Background(), tt.user); got != tt.want {
+				t.Errorf("hasDraftBatchChanges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolesAndPermissions(t *testing.T) {
+	user := &types.User{ID: 2, Username: "alice"}
+
+	roles := database.NewMockRoleStore()
+	roles.ListFunc.SetDefaultHook(func(_ context.Context, opts database.RolesListOptions) ([]*types.Role, error) {
+		if opts.UserID != user.ID {
+			t.Errorf("roles listed for user %d, want %d", opts.UserID, user.ID)
+		}
+		return []*types.Role{{ID: 3, Name: "USER", System: true}, {ID: 4, Name: "BATCH-CHANGES-WRITER"}}, nil
+	})
+	permissions := database.NewMockPermissionStore()
+	permissions.ListFunc.SetDefaultHook(func(_ context.Context, opts database.PermissionListOpts) ([]*types.Permission, error) {
+		if opts.UserID != user.ID {
+			t.Errorf("permissions listed for user %d, want %d", opts.UserID, user.ID)
+		}
+		// Both roles grant the READ permission.
+		return []*types.Permission{
+			{ID: 1, Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+			{ID: 2, Namespace: types.BatchChangesNamespace, Action: "READ"},
+			{ID: 2, Namespace: types.BatchChangesNamespace, Action: "READ"},
+		}, nil
+	})
+	db := database.NewMockDB()
+	db.RolesFunc.SetDefaultReturn(roles)
+	db.PermissionsFunc.SetDefaultReturn(permissions)
+
+	roleNames, permissionNames := rolesAndPermissions(context.Background(), user, db)
+	if diff := cmp.Diff([]string{"BATCH-CHANGES-WRITER", "USER"}, roleNames); diff != "" {
+		t.Errorf("roles mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"BATCH_CHANGES#READ", "BATCH_CHANGES#WRITE"}, permissionNames); diff != "" {
+		t.Errorf("permissions mismatch (-want +got):\n%s", diff)
+	}
+	// The roles and permissions are each listed with a single query.
+	if got := len(roles.ListFunc.History()); got != 1 {
+		t.Errorf("roles listed %d times, want 1", got)
+	}
+	if got := len(permissions.ListFunc.History()); got != 1 {
+		t.Errorf("permissions listed %d times, want 1", got)
+	}
+
+	t.Run("list error", func(t *testing.T) {
+		permissions.ListFunc.SetDefaultReturn(nil, errors.New("boom"))
+		roleNames, permissionNames := rolesAndPermissions(context.Background(), user, db)
+		if len(roleNames) != 0 || len(permissionNames) != 0 {
+			t.Errorf("want no roles or permissions on error, got %v and %v", roleNames, permissionNames)
+		}
+	})
+}
+
+func TestPreferredLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		userLocale     string
+		acceptLanguage string
+		want           string
+	}{
+		{
+			name:           "settings win over the header",
+			userLocale:     "fr",
+			acceptLanguage: "de-DE,de;q=0.9",
+			want:           "fr",
+		},
+		{
+			name:           "settings match a supported locale",
+			userLocale:     "pt-BR",
+			acceptLanguage: "",
+			want:           "pt-BR",
+		},
+		{
+			name:           "unsupported settings fall back to the header",
+			userLocale:     "nl",
+			acceptLanguage: "de",
+			want:           "de",
+		},
+		{
+			name:           "header",
+			userLocale:     "",
+			acceptLanguage: "de-CH",
+			want:           "de",
+		},
+		{
+			name:           "header quality values",
+			userLocale:     "",
+			acceptLanguage: "fr;q=0.5, ja;q=0.8, nl",
+			want:           "ja",
+		},
+		{
+			name:           "unsupported header",
+			userLocale:     "",
+			acceptLanguage: "nl",
+			want:           "en",
+		},
+		{
+			name:           "default",
+			userLocale:     "",
+			acceptLanguage: "",
+			want:           "en",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := preferredLocale(test.userLocale, test.acceptLanguage); got != test.want {
+				t.Errorf("preferredLocale = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSettingsLocale(t *testing.T) {
+	settings := database.NewMockSettingsStore()
+	settings.GetLatestFunc.SetDefaultHook(func(_ context.Context, subject api.SettingsSubject) (*api.Settings, error) {
+		switch *subject.User {
+		case 1:
+			return &api.Settings{Contents: `{
+				// Comments are allowed.
+				"locale": "ja",
+			}`}, nil
+		case 2:
+			return &api.Settings{Contents: `{}`}, nil
+		default:
+			return nil, nil
+		}
+	})
+	db := database.NewMockDB()
+	db.SettingsFunc.SetDefaultReturn(settings)
+
+	for userID, want := range map[int32]string{1: "ja", 2: "", 3: ""} {
+		if got := settingsLocale(context.Background(), &types.User{ID: userID}, db); got != want {
+			t.Errorf("user %d: settingsLocale = %q, want %q", userID, got, want)
+		}
+	}
+}
+
+func TestOrgMembershipRequired(t *testing.T) {
+	acme := &types.Org{ID: 1, Name: "acme"}
+	members := map[int32][]*types.Org{2: {acme}}
+
+	orgs := database.NewMockOrgStore()
+	orgs.GetByUserIDFunc.SetDefaultHook(func(_ context.Context, userID int32) ([]*types.Org, error) {
+		return members[userID], nil
+	})
+	db := database.NewMockDB()
+	db.OrgsFunc.SetDefaultReturn(orgs)
+
+	withoutOrgs := &types.User{ID: 1, Username: "alice"}
+	withOrgs := &types.User{ID: 2, Username: "bob"}
+
+	tests := []struct {
+		name     string
+		required bool
+		user     *types.User
+		want     bool
+	}{
+		{
+			name:     "not required, user without orgs",
+			required: false,
+			user:     withoutOrgs,
+			want:     false,
+		},
+		{
+			name:     "required, user without orgs",
+			required: true,
+			user:     withoutOrgs,
+			want:     true,
+		},
+		{
+			name:     "required, user with orgs",
+			required: true,
+			user:     withOrgs,
+			want:     false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthOrgMembershipRequired: test.required}}
+			if got := orgMembershipRequired(context.Background(), c, test.user, db); got != test.want {
+				t.Errorf("orgMembershipRequired() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasNotifyingSavedSearches(t *testing.T) {
+	tests := []struct {
+		name          string
+		savedSearches []*types.SavedSearch
+		want          bool
+	}{
+		{
+			name: "no saved searches",
+			want: false,
+		},
+		{
+			name: "saved searches without notifications",
+			savedSearches: []*types.SavedSearch{
+				{ID: 1, Query: "foo"},
+			},
+			want: false,
+		},
+		{
+			name: "saved search with email notifications",
+			savedSearches: []*types.SavedSearch{
+				{ID: 1, Query: "foo"},
+				{ID: 2, Query: "bar", Notify: true},
+			},
+			want: true,
+		},
+		{
+			name: "saved search with Slack notifications",
+			savedSearches: []*types.SavedSearch{
+				{ID: 1, Query: "foo", NotifySlack: true},
+			},
+			want: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			savedSearches := database.NewMockSavedSearchStore()
+			savedSearches.ListSavedSearchesByUserIDFunc.SetDefaultReturn(test.savedSearches, nil)
+			db := database.NewMockDB()
+			db.SavedSearchesFunc.SetDefaultReturn(savedSearches)
+
+			if got := hasNotifyingSavedSearches(context.Background(), &types.User{ID: 1}, db); got != test.want {
+				t.Errorf("hasNotifyingSavedSearches = %v, want %v", got, test.want)
 			}
 		})
 	}
 }
+
+func TestRepoPermissionsSyncHealthy(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		job  *database.PermissionSyncJob
+		want bool
+	}{
+		{
+			name: "recently queued",
+			job:  &database.PermissionSyncJob{QueuedAt: now.Add(-5 * time.Minute)},
+			want: true,
+		},
+		{
+			name: "waiting for too long",
+			job:  &database.PermissionSyncJob{QueuedAt: now.Add(-2 * time.Hour)},
+			want: false,
+		},
+		{
+			name: "scheduled for later",
+			job:  &database.PermissionSyncJob{QueuedAt: now.Add(-2 * time.Hour), ProcessAfter: now.Add(time.Minute)},
+			want: true,
+		},
+		{
+			name: "scheduled job waiting for too long",
+			job:  &database.PermissionSyncJob{QueuedAt: now.Add(-3 * time.Hour), ProcessAfter: now.Add(-2 * time.Hour)},
+			want: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := repoPermissionsSyncHealthy(test.job, now); got != test.want {
+				t.Errorf("want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAPIRateLimit(t *testing.T) {
+	user := &types.User{ID: 1, Username: "alice"}
+	rateLimit := &schema.ApiRatelimit{
+		Enabled: true,
+		PerIP:   100,
+		PerUser: 1000,
+	}
+
+	tests := []struct {
+		name      string
+		rateLimit *schema.ApiRatelimit
+		user      *types.User
+		want      *UserAPIRateLimit
+	}{
+		{
+			name:      "disabled",
+			rateLimit: &schema.ApiRatelimit{PerIP: 100, PerUser: 1000},
+			user:      user,
+			want:      nil,
+		},
+		{
+			name:      "default user limit",
+			rateLimit: rateLimit,
+			user:      user,
+			want:      &UserAPIRateLimit{Limit: 1000, WindowSeconds: 3600},
+		},
+		{
+			name:      "anonymous",
+			rateLimit: rateLimit,
+			want:      &UserAPIRateLimit{Limit: 100, WindowSeconds: 3600},
+		},
+		{
+			name: "custom user limit",
+			rateLimit: &schema.ApiRatelimit{
+				Enabled:   true,
+				PerIP:     100,
+				PerUser:   1000,
+				Overrides: []*schema.Overrides{{Key: "2", Limit: float64(10)}, {Key: "1", Limit: float64(5000)}},
+			},
+			user: user,
+			want: &UserAPIRateLimit{Limit: 5000, WindowSeconds: 3600},
+		},
+		{
+			name: "blocked user",
+			rateLimit: &schema.ApiRatelimit{
+				Enabled:   true,
+				PerUser:   1000,
+				Overrides: []*schema.Overrides{{Key: "1", Limit: "blocked"}},
+			},
+			user: user,
+			want: &UserAPIRateLimit{Limit: 0, WindowSeconds: 3600},
+		},
+		{
+			name: "unlimited user",
+			rateLimit: &schema.ApiRatelimit{
+				Enabled:   true,
+				PerUser:   1000,
+				Overrides: []*schema.Overrides{{Key: "1", Limit: "unlimited"}},
+			},
+			user: user,
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{ApiRatelimit: test.rateLimit}}
+			if diff := cmp.Diff(test.want, apiRateLimit(c, test.user)); diff != "" {
+				t.Errorf("rate limit mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if got := apiRateLimit(&conf.Unified{}, user); got != nil {
+		t.Errorf("want no rate limit without configuration, got %+v", got)
+	}
+}
+
+func TestCustomErrorPageContent(t *testing.T) {
+	if got := customErrorPageContent(&conf.Unified{}); got != "" {
+		t.Errorf("customErrorPageContent = %q, want empty by default", got)
+	}
+
+	c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		CustomErrorPageContent: "Contact **#help** for assistance.<script>alert(1)</script>",
+	}}
+	got := customErrorPageContent(c)
+	if !strings.Contains(got, "<strong>#help</strong>") {
+		t.Errorf("customErrorPageContent = %q, want rendered Markdown", got)
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("customErrorPageContent = %q, want scripts to be stripped", got)
+	}
+}
+
+func TestBackupInProgressBanner(t *testing.T) {
+	tests := []struct {
+		name        string
+		backup      *schema.BackupInProgress
+		wantEnabled bool
+		wantMessage string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "disabled",
+			backup: &schema.BackupInProgress{Message: "Backing up"},
+		},
+		{
+			name:        "enabled without message",
+			backup:      &schema.BackupInProgress{Enabled: true, Message: " "},
+			wantEnabled: true,
+		},
+		{
+			name:        "enabled with message",
+			backup:      &schema.BackupInProgress{Enabled: true, Message: "Backing up until **02:00 UTC**.<script>alert(1)</script>"},
+			wantEnabled: true,
+			wantMessage: "<p>Backing up until <strong>02:00 UTC</strong>.</p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{BackupInProgress: test.backup}}
+			enabled, message := backupInProgressBanner(c)
+			if enabled != test.wantEnabled {
+				t.Errorf("enabled = %v, want %v", enabled, test.wantEnabled)
+			}
+			if message != test.wantMessage {
+				t.Errorf("message = %q, want %q", message, test.wantMessage)
+			}
+		})
+	}
+}
+
+// resetStaticJSContext drops the memoized static JSContext and stubs out the parts of it that
+// can't be computed in tests.
+func resetStaticJSContext(t *testing.T) {
+	t.Helper()
+
+	// Don't register the config watcher, the tests invalidate the cache themselves.
+	watchStaticJSContextOnce.Do(func() {})
+
+	likely := false
+	mockLikelyDockerOnMac = &likely
+	getSiteID = func() string { return "site" }
+	invalidateStaticJSContext()
+	t.Cleanup(func() {
+		mockLikelyDockerOnMac = nil
+		getSiteID = siteid.Get
+		invalidateStaticJSContext()
+	})
+}
+
+func TestStaticJSContext(t *testing.T) {
+	resetStaticJSContext(t)
+
+	mockPasswordPolicy := func(enabled bool) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			AuthPasswordPolicy: &schema.AuthPasswordPolicy{Enabled: enabled},
+		}})
+	}
+	mockPasswordPolicy(false)
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	if got := getStaticJSContext(); got.siteID != "site" || got.authPasswordPolicy.Enabled {
+		t.Fatalf("unexpected static context %+v", got)
+	}
+
+	// The static context is memoized until the config watcher invalidates it.
+	mockPasswordPolicy(true)
+	if got := getStaticJSContext(); got.authPasswordPolicy.Enabled {
+		t.Fatal("want the memoized static context before the config change is observed")
+	}
+	invalidateStaticJSContext()
+	if got := getStaticJSContext(); !got.authPasswordPolicy.Enabled {
+		t.Fatal("want the static context to be recomputed after a config change")
+	}
+
+	// Updating the registered auth providers also invalidates it.
+	mockPasswordPolicy(false)
+	providers.Update("jscontext_test", nil)
+	if got := getStaticJSContext(); got.authPasswordPolicy.Enabled {
+		t.Fatal("want the static context to be recomputed after the auth providers change")
+	}
+}
+
+func BenchmarkStaticJSContext(b *testing.B) {
+	watchStaticJSContextOnce.Do(func() {})
+	likely := false
+	mockLikelyDockerOnMac = &likely
+	getSiteID = func() string { return "site" }
+	b.Cleanup(func() {
+		mockLikelyDockerOnMac = nil
+		getSiteID = siteid.Get
+		invalidateStaticJSContext()
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			invalidateStaticJSContext()
+			getStaticJSContext()
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			getStaticJSContext()
+		}
+	})
+}
+
+func TestJSContextETag(t *testing.T) {
+	newContext := func() JSContext {
+		return JSContext{
+			XHRHeaders:          map[string]string{"X-Requested-With": "Sourcegraph"},
+			IsAuthenticatedUser: true,
+			CurrentUser:         &CurrentUser{DatabaseID: 1, Username: "alice"},
+			AuthProviders:       []authProviderInfo{{IsBuiltin: true, DisplayName: "Builtin"}},
+			Site:                schema.SiteConfiguration{AuthPublic: true},
+		}
+	}
+	etag := func(c JSContext) string {
+		t.Helper()
+		etag, err := c.ETag()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return etag
+	}
+
+	want := etag(newContext())
+	if got := etag(newContext()); got != want {
+		t.Errorf("identical contexts have different ETags %s and %s", want, got)
+	}
+
+	// Fields that only reflect the current request don't change the ETag.
+	c := newContext()
+	c.XHRHeaders["Cache-Control"] = "no-cache"
+	c.UserAgentIsBot = true
+	if got := etag(c); got != want {
+		t.Errorf("per-request fields changed the ETag from %s to %s", want, got)
+	}
+
+	for name, change := range map[string]func(c *JSContext){
+		"batch changes":  func(c *JSContext) { c.BatchChangesEnabled = true },
+		"site config":    func(c *JSContext) { c.Site.AuthPublic = false },
+		"auth providers": func(c *JSContext) { c.AuthProviders = nil },
+		"current user":   func(c *JSContext) { c.CurrentUser.Username = "bob" },
+	} {
+		c := newContext()
+		change(&c)
+		if got := etag(c); got == want {
+			t.Errorf("%s: want the ETag to change", name)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc"`
+	tests := map[string]bool{
+		``:             false,
+		`"abc"`:        true,
+		`W/"abc"`:      true,
+		`"def", "abc"`: true,
+		`"def"`:        false,
+		`*`:            true,
+		`"abcd"`:       false,
+	}
+	for ifNoneMatch, want := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		if got := NotModified(r, etag); got != want {
+			t.Errorf("If-None-Match %q: want %v, got %v", ifNoneMatch, want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"encoding/json"
 	"html/template"
 	"log"
 	"net/http"
@@ -546,3 +547,29 @@ func servePingFromSelfHosted(w http.ResponseWriter, r *http.Request) error {
 	})
 	return nil
 }
+
+// serveJSContext serves the JS context as JSON. It is tagged with the JS
+// context's ETag, so a client that sends it back in If-None-Match gets a 304 Not
+// Modified until the site configuration, the auth providers or the current user
+// change.
+func serveJSContext(db database.DB) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx := jscontext.NewJSContextFromRequest(r, db)
+		etag, err := ctx.ETag()
+		if err != nil {
+			return err
+		}
+
+		// The JS context depends on the current user, so it must not be stored
+		// by shared caches and must be revalidated before it is reused.
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Set("ETag", etag)
+		if jscontext.NotModified(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		return json.NewEncoder(w).Encode(ctx)
+	}
+}
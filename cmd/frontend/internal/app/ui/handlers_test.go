@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"net/http"
@@ -87,6 +88,70 @@ func TestRedirects(t *testing.T) {
 	})
 }
 
+func TestServeJSContext(t *testing.T) {
+	gss := database.NewMockGlobalStateStore()
+	gss.GetFunc.SetDefaultReturn(database.GlobalState{SiteID: "a"}, nil)
+
+	db := database.NewMockDB()
+	db.GlobalStateFunc.SetDefaultReturn(gss)
+
+	InitRouter(db)
+
+	get := func(t *testing.T, ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req, err := http.NewRequest("GET", "/jscontext", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rw := httptest.NewRecorder()
+		uirouter.Router.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := get(t, "")
+	if rw.Code != http.StatusOK {
+		t.Fatalf("got HTTP response code %d, want %d", rw.Code, http.StatusOK)
+	}
+	etag := rw.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("got no ETag")
+	}
+	var ctx map[string]any
+	if err := json.Unmarshal(rw.Body.Bytes(), &ctx); err != nil {
+		t.Fatalf("body is not a JSON object: %s", err)
+	}
+	if _, ok := ctx["externalURL"]; !ok {
+		t.Errorf("body is missing externalURL: %s", rw.Body.String())
+	}
+
+	t.Run("matching If-None-Match", func(t *testing.T) {
+		rw := get(t, etag)
+		if rw.Code != http.StatusNotModified {
+			t.Fatalf("got HTTP response code %d, want %d", rw.Code, http.StatusNotModified)
+		}
+		if got := rw.Header().Get("ETag"); got != etag {
+			t.Errorf("got ETag %q, want %q", got, etag)
+		}
+		if rw.Body.Len() != 0 {
+			t.Errorf("got body %q, want none", rw.Body.String())
+		}
+	})
+
+	t.Run("stale If-None-Match", func(t *testing.T) {
+		rw := get(t, `"stale"`)
+		if rw.Code != http.StatusOK {
+			t.Fatalf("got HTTP response code %d, want %d", rw.Code, http.StatusOK)
+		}
+		if got := rw.Header().Get("ETag"); got != etag {
+			t.Errorf("got ETag %q, want %q", got, etag)
+		}
+	})
+}
+
 func TestRepoShortName(t *testing.T) {
 	tests := []struct {
 		input api.RepoName
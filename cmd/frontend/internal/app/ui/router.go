@@ -78,6 +78,7 @@ const (
 	routeDevToolTime             = "devtooltime"
 	routeEmbed                   = "embed"
 	routeCody                    = "cody"
+	routeJSContext               = "jscontext"
 
 	routeSearchStream  = "search.stream"
 	routeSearchConsole = "search.console"
@@ -172,6 +173,7 @@ func newRouter() *mux.Router {
 	r.PathPrefix("/devtooltime").Methods("GET").Name(routeDevToolTime)
 	r.PathPrefix("/cody").Methods("GET").Name(routeCody)
 	r.Path("/ping-from-self-hosted").Methods("GET", "OPTIONS").Name(uirouter.RoutePingFromSelfHosted)
+	r.Path("/jscontext").Methods("GET").Name(routeJSContext)
 
 	// 🚨 SECURITY: The embed route is used to serve embeddable content (via an iframe) to 3rd party sites.
 	// Any changes to the embedding route could have security implications. Please consult the security team
@@ -283,6 +285,7 @@ func initRouter(db database.DB, router *mux.Router) {
 	router.Get(routeViews).Handler(brandedNoIndex("View"))
 	router.Get(routeCody).Handler(brandedNoIndex("Cody"))
 	router.Get(uirouter.RoutePingFromSelfHosted).Handler(handler(db, servePingFromSelfHosted))
+	router.Get(routeJSContext).Handler(handler(db, serveJSContext(db)))
 
 	// 🚨 SECURITY: The embed route is used to serve embeddable content (via an iframe) to 3rd party sites.
 	// Any changes to the embedding route could have security implications. Please consult the security team
@@ -18,6 +18,7 @@ import (
 // This method is called as part of the background process by the `frontend` service.
 func UpdatePermissions(ctx context.Context, logger log.Logger, db database.DB) {
 	scopedLog := logger.Scoped("permission_update", "Updates the permission in the database based on the rbac schema configuration.")
+	var revoked []*types.RolePermission
 	err := db.WithTransact(ctx, func(tx database.DB) error {
 		permissionStore := tx.Permissions()
 		rolePermissionStore := tx.RolePermissions()
@@ -31,6 +32,16 @@ func UpdatePermissions(ctx context.Context, logger log.Logger, db database.DB) {
 		scopedLog.Info("RBAC Permissions update", log.Int("added", len(toBeAdded)), log.Int("deleted", len(toBeDeleted)))
 
 		if len(toBeDeleted) > 0 {
+			// Roles lose the permissions that are deleted, so we keep track of them to record
+			// the revocations in the security event log.
+			for _, opts := range toBeDeleted {
+				rolePermissions, err := rolePermissionStore.GetByPermissionID(ctx, database.GetRolePermissionOpts{PermissionID: opts.ID})
+				if err != nil {
+					return errors.Wrap(err, "fetching roles of redundant permissions")
+				}
+				revoked = append(revoked, rolePermissions...)
+			}
+
 			// We delete all the permissions that need to be deleted from the database. The role <> permissions are
 			// automatically deleted: https://app.golinks.io/role_permissions-permission_id_cascade.
 			err = permissionStore.BulkDelete(ctx, toBeDeleted)
@@ -53,7 +64,7 @@ func UpdatePermissions(ctx context.Context, logger log.Logger, db database.DB) {
 				// access.
 				// Context: https://sourcegraph.slack.com/archives/C044BUJET7C/p1675292124253779?thread_ts=1675280399.192819&cid=C044BUJET7C
 				if _, err := rolePermissionStore.BulkAssignPermissionsToSystemRoles(ctx, database.BulkAssignPermissionsToSystemRolesOpts{
-					Roles:        []types.SystemRole{types.SiteAdministratorSystemRole, types.UserSystemRole},
+					Roles:        rbac.DefaultSystemRoles,
 					PermissionID: permission.ID,
 				}); err != nil {
 					return errors.Wrap(err, "assigning permission to system roles")
@@ -66,5 +77,10 @@ func UpdatePermissions(ctx context.Context, logger log.Logger, db database.DB) {
 
 	if err != nil {
 		scopedLog.Error("failed to update RBAC permissions", log.Error(err))
+		return
+	}
+
+	for _, rp := range revoked {
+		rbac.LogDestructiveEvent(ctx, scopedLog, db, database.SecurityEventNameRBACRolePermissionRevoked, map[string]any{"roleID": rp.RoleID, "permissionID": rp.PermissionID})
 	}
 }
@@ -62,6 +62,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -237,6 +238,7 @@ func TestResolver_SetRepositoryPermissionsUnrestricted(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -301,6 +303,7 @@ func TestResolver_ScheduleRepositoryPermissionsSync(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -356,6 +359,7 @@ func TestResolver_ScheduleUserPermissionsSync(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -443,6 +447,7 @@ func TestResolver_SetRepositoryPermissionsForBitbucketProject(t *testing.T) {
 
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -467,6 +472,7 @@ func TestResolver_SetRepositoryPermissionsForBitbucketProject(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -626,6 +632,7 @@ func TestResolver_AuthorizedUserRepositories(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -893,6 +900,7 @@ func TestResolver_UsersWithPendingPermissions(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -954,6 +962,7 @@ func TestResolver_AuthzProviderTypes(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -999,6 +1008,7 @@ func TestResolver_AuthorizedUsers(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -1182,6 +1192,7 @@ func TestResolver_RepositoryPermissionsInfo(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -1267,6 +1278,7 @@ func TestResolver_UserPermissionsInfo(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -1352,6 +1364,7 @@ func TestResolver_SetSubRepositoryPermissionsForUsers(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		subrepos := edb.NewStrictMockSubRepoPermsStore()
 		subrepos.UpsertFunc.SetDefaultHook(func(ctx context.Context, i int32, id api.RepoID, permissions authz.SubRepoPermissions) error {
@@ -1527,6 +1540,7 @@ func TestResolver_BitbucketProjectPermissionJobs(t *testing.T) {
 
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -1546,6 +1560,7 @@ func TestResolver_BitbucketProjectPermissionJobs(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -1712,6 +1727,7 @@ func TestResolverPermissionsSyncJobs(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		users := database.NewStrictMockUserStore()
 		users.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{}, nil)
+		users.TemporaryAdminExpiresAtFunc.SetDefaultReturn(time.Time{}, nil)
 
 		db := edb.NewStrictMockEnterpriseDB()
 		db.UsersFunc.SetDefaultReturn(users)
@@ -47,6 +47,7 @@ type RoleConnection struct {
 type User struct {
 	ID         string
 	DatabaseID int32
+	Username   string
 	SiteAdmin  bool
 
 	// All permissions associated with the roles that have been assigned to the user.
@@ -58,3 +59,101 @@ type User struct {
 type EmptyResponse struct {
 	AlwaysNil string
 }
+
+type SkippedRole struct {
+	Role   string
+	Reason string
+}
+
+type DeleteRolesResult struct {
+	DeletedRoles []string
+	SkippedRoles []SkippedRole
+}
+
+type RoleDeletionPreview struct {
+	AffectedUserCount int
+	LostPermissions   []Permission
+}
+
+type RoleBlastRadius struct {
+	UserCount       int
+	PermissionCount int
+	BlastRadius     int
+}
+
+type PermissionNamespaceCount struct {
+	Namespace types.PermissionNamespace
+	Count     int
+}
+
+type PermissionMatrixRow struct {
+	User    string
+	Granted []bool
+}
+
+type UserWithInertPermissions struct {
+	User        User
+	Permissions []Permission
+}
+
+type PermissionInheritancePath struct {
+	User       User
+	Role       Role
+	Permission Permission
+}
+
+type SystemRoleValidation struct {
+	Role               Role
+	Valid              bool
+	MissingPermissions []Permission
+	ExtraPermissions   []Permission
+}
+
+type RoleChange struct {
+	Role       Role
+	ChangeType string
+}
+
+type RBACSearchResult struct {
+	Roles       []Role
+	Permissions []Permission
+}
+
+type ImportRBACConfigResult struct {
+	DryRun                  bool
+	PermissionsCreated      int32
+	RolesCreated            int32
+	RolePermissionsAssigned int32
+	UserRolesAssigned       int32
+	Warnings                []string
+}
+
+type RBACAuditEvent struct {
+	Name      string
+	Actor     *User
+	Argument  string
+	Timestamp gqlutil.DateTime
+}
+
+type UserImpactedByRoleChange struct {
+	User            User
+	LostPermissions []Permission
+}
+
+type SkippedPermission struct {
+	Namespace types.PermissionNamespace
+	Action    string
+	Reason    string
+}
+
+type CreatePermissionsResult struct {
+	CreatedPermissions []Permission
+	SkippedPermissions []SkippedPermission
+}
+
+type RoleAssignment struct {
+	User       User
+	Role       Role
+	AssignedAt gqlutil.DateTime
+	ExpiresAt  *gqlutil.DateTime
+}
@@ -0,0 +1,290 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// destructiveRBACEvents are the names of the security events recorded for RBAC
+// operations that remove roles, permissions or their assignments.
+var destructiveRBACEvents = []database.SecurityEventName{
+	database.SecurityEventNameRBACRoleDeleted,
+	database.SecurityEventNameRBACRolesBulkDeleted,
+	database.SecurityEventNameRBACRolePermissionRevoked,
+	database.SecurityEventNameRBACUserRoleRevoked,
+}
+
+// logDestructiveRBACEvent records a destructive RBAC operation performed by the
+// current actor in the security event log, so that it can be reviewed with
+// destructiveRbacAudit.
+func (r *Resolver) logDestructiveRBACEvent(ctx context.Context, name database.SecurityEventName, argument any) {
+	rbac.LogDestructiveEvent(ctx, r.logger, r.db, name, argument)
+}
+
+func (r *Resolver) DestructiveRBACAudit(ctx context.Context, args *gql.DestructiveRBACAuditArgs) ([]gql.RBACAuditEventResolver, error) {
+	// 🚨 SECURITY: Only site administrators can review the RBAC audit trail.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	if args.First < 0 {
+		return nil, errors.New("first must be a non-negative integer")
+	}
+
+	opts := database.SecurityEventLogsListOptions{
+		Names:       destructiveRBACEvents,
+		LimitOffset: &database.LimitOffset{Limit: int(args.First)},
+	}
+	if args.Since != nil {
+		opts.Since = args.Since.Time
+	}
+	if args.Until != nil {
+		opts.Until = args.Until.Time
+	}
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && !opts.Since.Before(opts.Until) {
+		return nil, errors.New("since must be before until")
+	}
+	if args.Actor != nil {
+		userID, err := gql.UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+		if userID == 0 {
+			return nil, ErrIDIsZero{}
+		}
+		opts.UserID = uint32(userID)
+	}
+
+	events, err := r.db.SecurityEventLogs().List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]gql.RBACAuditEventResolver, 0, len(events))
+	for _, event := range events {
+		resolvers = append(resolvers, &rbacAuditEventResolver{db: r.db, event: event})
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) UsersImpactedByRoleChange(ctx context.Context, args *gql.UsersImpactedByRoleChangeArgs) ([]gql.UserImpactedByRoleChangeResolver, error) {
+	// 🚨 SECURITY: Only site administrators can review the RBAC audit trail.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	if args.First < 0 {
+		return nil, errors.New("first must be a non-negative integer")
+	}
+
+	roleID, err := unmarshalRoleID(args.Role)
+	if err != nil {
+		return nil, err
+	}
+	if roleID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+	if _, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: roleID}); err != nil {
+		return nil, err
+	}
+
+	events, err := r.db.SecurityEventLogs().List(ctx, database.SecurityEventLogsListOptions{
+		Names: []database.SecurityEventName{
+			database.SecurityEventNameRBACRolePermissionRevoked,
+			database.SecurityEventNameRBACUserRoleRevoked,
+		},
+		Since:            args.Since.Time,
+		ArgumentContains: map[string]any{"roleID": roleID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make(map[int32]struct{})
+	removed := make(map[int32]struct{})
+	for _, event := range events {
+		var arg struct {
+			RoleID       int32 `json:"roleID"`
+			PermissionID int32 `json:"permissionID"`
+			UserID       int32 `json:"userID"`
+		}
+		if err := json.Unmarshal(event.Argument, &arg); err != nil {
+			return nil, errors.Wrapf(err, "parsing %s event", event.Name)
+		}
+		if arg.RoleID != roleID {
+			continue
+		}
+		if event.Name == database.SecurityEventNameRBACRolePermissionRevoked {
+			revoked[arg.PermissionID] = struct{}{}
+		} else {
+			removed[arg.UserID] = struct{}{}
+		}
+	}
+
+	// Permissions that were given back to the role since weren't lost.
+	rolePermissions, err := r.db.RolePermissions().GetByRoleID(ctx, database.GetRolePermissionOpts{RoleID: roleID})
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int32]struct{}, len(rolePermissions))
+	for _, rolePermission := range rolePermissions {
+		delete(revoked, rolePermission.PermissionID)
+		current[rolePermission.PermissionID] = struct{}{}
+	}
+
+	// Neither were users who were given the role back.
+	userRoles, err := r.db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: roleID})
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[int32]struct{}, len(userRoles))
+	for _, userRole := range userRoles {
+		delete(removed, userRole.UserID)
+		members[userRole.UserID] = struct{}{}
+	}
+
+	resolvers := []gql.UserImpactedByRoleChangeResolver{}
+	if len(revoked) == 0 && len(removed) == 0 {
+		return resolvers, nil
+	}
+
+	// Members of the role lost the permissions revoked from it, while users removed
+	// from the role lost all of its permissions. Permissions that have been deleted
+	// since can't be reported.
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].DisplayName() < permissions[j].DisplayName() })
+	var lostByMembers, lostByRemoved []*types.Permission
+	var lostIDs []int32
+	for _, permission := range permissions {
+		_, isRevoked := revoked[permission.ID]
+		_, isCurrent := current[permission.ID]
+		if isRevoked && len(members) > 0 {
+			lostByMembers = append(lostByMembers, permission)
+		}
+		if (isRevoked || isCurrent) && len(removed) > 0 {
+			lostByRemoved = append(lostByRemoved, permission)
+		}
+		if isRevoked || isCurrent {
+			lostIDs = append(lostIDs, permission.ID)
+		}
+	}
+	if len(lostByMembers) == 0 && len(lostByRemoved) == 0 {
+		return resolvers, nil
+	}
+
+	userIDs := make([]int32, 0, len(members)+len(removed))
+	if len(lostByMembers) > 0 {
+		for userID := range members {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(lostByRemoved) > 0 {
+		for userID := range removed {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	// Users still hold the lost permissions if another one of their roles grants them.
+	granted, err := r.db.Permissions().GrantedToUsers(ctx, userIDs, lostIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	lostByUser := make(map[int32][]gql.PermissionResolver, len(userIDs))
+	impactedIDs := make([]int32, 0, len(userIDs))
+	for _, userID := range userIDs {
+		stillGranted := make(map[int32]struct{}, len(granted[userID]))
+		for _, permissionID := range granted[userID] {
+			stillGranted[permissionID] = struct{}{}
+		}
+
+		lost := lostByMembers
+		if _, ok := removed[userID]; ok {
+			lost = lostByRemoved
+		}
+
+		for _, permission := range lost {
+			if _, ok := stillGranted[permission.ID]; !ok {
+				lostByUser[userID] = append(lostByUser[userID], &permissionResolver{permission: permission})
+			}
+		}
+		if len(lostByUser[userID]) > 0 {
+			impactedIDs = append(impactedIDs, userID)
+		}
+	}
+	if len(impactedIDs) == 0 {
+		return resolvers, nil
+	}
+
+	// Users are listed in ID order.
+	users, err := r.db.Users().List(ctx, &database.UsersListOptions{
+		UserIDs:     impactedIDs,
+		LimitOffset: &database.LimitOffset{Limit: int(args.First)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		resolvers = append(resolvers, &userImpactedByRoleChangeResolver{
+			user:            gql.NewUserResolver(r.db, user),
+			lostPermissions: lostByUser[user.ID],
+		})
+	}
+
+	return resolvers, nil
+}
+
+type userImpactedByRoleChangeResolver struct {
+	user            *gql.UserResolver
+	lostPermissions []gql.PermissionResolver
+}
+
+func (r *userImpactedByRoleChangeResolver) User() *gql.UserResolver {
+	return r.user
+}
+
+func (r *userImpactedByRoleChangeResolver) LostPermissions() []gql.PermissionResolver {
+	return r.lostPermissions
+}
+
+type rbacAuditEventResolver struct {
+	db    database.DB
+	event *database.SecurityEvent
+}
+
+func (r *rbacAuditEventResolver) Name() string {
+	return string(r.event.Name)
+}
+
+func (r *rbacAuditEventResolver) Actor(ctx context.Context) (*gql.UserResolver, error) {
+	user, err := r.db.Users().GetByID(ctx, int32(r.event.UserID))
+	if err != nil {
+		// The actor may have been deleted since.
+		if errcode.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return gql.NewUserResolver(r.db, user), nil
+}
+
+func (r *rbacAuditEventResolver) Argument() string {
+	return string(r.event.Argument)
+}
+
+func (r *rbacAuditEventResolver) Timestamp() gqlutil.DateTime {
+	return gqlutil.DateTime{Time: r.event.Timestamp}
+}
@@ -0,0 +1,323 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/log/logtest"
+	"github.com/stretchr/testify/require"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/rbac/resolvers/apitest"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestDestructiveRBACAudit(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	otherAdmin := createTestUser(t, db, true)
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Second)
+	err = db.SecurityEventLogs().InsertList(ctx, []*database.SecurityEvent{
+		{Name: database.SecurityEventNameRBACRoleDeleted, UserID: uint32(admin.ID), Argument: []byte(`{"roleID":1}`), Source: "BACKEND", Timestamp: now.Add(-3 * time.Hour)},
+		{Name: database.SecurityEventNameRBACRolesBulkDeleted, UserID: uint32(otherAdmin.ID), Argument: []byte(`{"roleID":2}`), Source: "BACKEND", Timestamp: now.Add(-2 * time.Hour)},
+		{Name: database.SecurityEventNameRBACRoleDeleted, UserID: uint32(admin.ID), Argument: []byte(`{"roleID":3}`), Source: "BACKEND", Timestamp: now.Add(-time.Hour)},
+		// Not a destructive RBAC operation.
+		{Name: database.SecurityEventNameSignInSucceeded, UserID: uint32(admin.ID), Source: "BACKEND", Timestamp: now.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	roleIDs := func(events []apitest.RBACAuditEvent) (ids []string) {
+		for _, event := range events {
+			ids = append(ids, event.Argument)
+		}
+		return ids
+	}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct{ DestructiveRbacAudit []apitest.RBACAuditEvent }
+		errs := apitest.Exec(userCtx, t, s, map[string]any{}, &response, queryDestructiveRBACAudit)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	for _, tc := range []struct {
+		name  string
+		input map[string]any
+		want  []string
+	}{
+		{
+			name:  "all",
+			input: map[string]any{},
+			want:  []string{`{"roleID": 3}`, `{"roleID": 2}`, `{"roleID": 1}`},
+		},
+		{
+			name:  "by actor",
+			input: map[string]any{"actor": string(gql.MarshalUserID(admin.ID))},
+			want:  []string{`{"roleID": 3}`, `{"roleID": 1}`},
+		},
+		{
+			name: "by window",
+			input: map[string]any{
+				"since": now.Add(-150 * time.Minute).Format(time.RFC3339),
+				"until": now.Add(-time.Hour).Format(time.RFC3339),
+			},
+			want: []string{`{"roleID": 2}`},
+		},
+		{
+			name: "by actor and window",
+			input: map[string]any{
+				"actor": string(gql.MarshalUserID(admin.ID)),
+				"since": now.Add(-150 * time.Minute).Format(time.RFC3339),
+			},
+			want: []string{`{"roleID": 3}`},
+		},
+		{
+			name:  "first",
+			input: map[string]any{"first": 2},
+			want:  []string{`{"roleID": 3}`, `{"roleID": 2}`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var response struct{ DestructiveRbacAudit []apitest.RBACAuditEvent }
+			apitest.MustExec(adminCtx, t, s, tc.input, &response, queryDestructiveRBACAudit)
+
+			if diff := cmp.Diff(tc.want, roleIDs(response.DestructiveRbacAudit)); diff != "" {
+				t.Fatalf("wrong events (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("role deletions are recorded", func(t *testing.T) {
+		role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
+		require.NoError(t, err)
+
+		var deleteResponse struct{ DeleteRole apitest.EmptyResponse }
+		apitest.MustExec(adminCtx, t, s, map[string]any{"role": string(marshalRoleID(role.ID))}, &deleteResponse, deleteRoleMutation)
+
+		var response struct{ DestructiveRbacAudit []apitest.RBACAuditEvent }
+		apitest.MustExec(adminCtx, t, s, map[string]any{"since": now.Format(time.RFC3339)}, &response, queryDestructiveRBACAudit)
+
+		require.Len(t, response.DestructiveRbacAudit, 1)
+		event := response.DestructiveRbacAudit[0]
+		require.Equal(t, string(database.SecurityEventNameRBACRoleDeleted), event.Name)
+		require.NotNil(t, event.Actor)
+		require.Equal(t, admin.ID, event.Actor.DatabaseID)
+	})
+
+	t.Run("user role revocations are recorded", func(t *testing.T) {
+		role, err := db.Roles().Create(ctx, "REVOKED-ROLE", false)
+		require.NoError(t, err)
+		_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: user.ID, RoleID: role.ID})
+		require.NoError(t, err)
+
+		// Setting the roles of the user revokes the roles that aren't given.
+		var setRolesResponse struct{ SetRoles apitest.User }
+		apitest.MustExec(adminCtx, t, s, map[string]any{
+			"user":  string(gql.MarshalUserID(user.ID)),
+			"roles": []string{},
+		}, &setRolesResponse, setRolesMutation)
+
+		var response struct{ DestructiveRbacAudit []apitest.RBACAuditEvent }
+		apitest.MustExec(adminCtx, t, s, map[string]any{"since": now.Format(time.RFC3339)}, &response, queryDestructiveRBACAudit)
+
+		var revocations []apitest.RBACAuditEvent
+		for _, event := range response.DestructiveRbacAudit {
+			if event.Name == string(database.SecurityEventNameRBACUserRoleRevoked) {
+				revocations = append(revocations, event)
+			}
+		}
+		require.Len(t, revocations, 1)
+		require.JSONEq(t, fmt.Sprintf(`{"userID": %d, "roleID": %d}`, user.ID, role.ID), revocations[0].Argument)
+		require.NotNil(t, revocations[0].Actor)
+		require.Equal(t, admin.ID, revocations[0].Actor.DatabaseID)
+	})
+}
+
+const queryDestructiveRBACAudit = `
+query DestructiveRBACAudit($since: DateTime, $until: DateTime, $actor: ID, $first: Int) {
+	destructiveRbacAudit(since: $since, until: $until, actor: $actor, first: $first) {
+		name
+		actor {
+			databaseID
+		}
+		argument
+		timestamp
+	}
+}
+`
+
+func TestUsersImpactedByRoleChange(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	otherUser := createTestUser(t, db, false)
+	removedUser := createTestUser(t, db, false)
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "BATCH_CHANGES_ADMIN", false)
+	require.NoError(t, err)
+	for _, p := range ps {
+		_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: p.ID})
+		require.NoError(t, err)
+	}
+	for _, userID := range []int32{user.ID, otherUser.ID, removedUser.ID} {
+		_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: role.ID, UserID: userID})
+		require.NoError(t, err)
+	}
+
+	// The other user is also granted BATCH_CHANGES#WRITE through another role.
+	otherRole, err := db.Roles().Create(ctx, "BATCH_CHANGES_WRITER", false)
+	require.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: otherRole.ID, PermissionID: ps[1].ID})
+	require.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: otherRole.ID, UserID: otherUser.ID})
+	require.NoError(t, err)
+
+	since := time.Now().Add(-time.Second)
+
+	// Remove the BATCH_CHANGES permissions from the role. RBAC#WRITE is removed as
+	// well, but given back right after. The store doesn't record revocations, so
+	// we record them like the code removing permissions from roles does.
+	for _, p := range ps {
+		err = db.RolePermissions().Revoke(adminCtx, database.RevokeRolePermissionOpts{RoleID: role.ID, PermissionID: p.ID})
+		require.NoError(t, err)
+		rbac.LogDestructiveEvent(adminCtx, logger, db, database.SecurityEventNameRBACRolePermissionRevoked, map[string]any{"roleID": role.ID, "permissionID": p.ID})
+	}
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: ps[2].ID})
+	require.NoError(t, err)
+
+	// Remove a user from the role, who loses all of its permissions.
+	var setRolesResponse struct{ SetRoles apitest.User }
+	apitest.MustExec(adminCtx, t, s, map[string]any{
+		"user":  string(gql.MarshalUserID(removedUser.ID)),
+		"roles": []string{},
+	}, &setRolesResponse, setRolesMutation)
+
+	input := map[string]any{
+		"role":  string(marshalRoleID(role.ID)),
+		"since": since.Format(time.RFC3339),
+	}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct {
+			UsersImpactedByRoleChange []apitest.UserImpactedByRoleChange
+		}
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryUsersImpactedByRoleChange)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct {
+			UsersImpactedByRoleChange []apitest.UserImpactedByRoleChange
+		}
+		apitest.MustExec(adminCtx, t, s, input, &response, queryUsersImpactedByRoleChange)
+
+		want := []apitest.UserImpactedByRoleChange{
+			{
+				User:            apitest.User{ID: string(gql.MarshalUserID(user.ID))},
+				LostPermissions: []apitest.Permission{{DisplayName: ps[0].DisplayName()}, {DisplayName: ps[1].DisplayName()}},
+			},
+			{
+				User:            apitest.User{ID: string(gql.MarshalUserID(otherUser.ID))},
+				LostPermissions: []apitest.Permission{{DisplayName: ps[0].DisplayName()}},
+			},
+			{
+				User: apitest.User{ID: string(gql.MarshalUserID(removedUser.ID))},
+				LostPermissions: []apitest.Permission{
+					{DisplayName: ps[0].DisplayName()},
+					{DisplayName: ps[1].DisplayName()},
+					{DisplayName: ps[2].DisplayName()},
+				},
+			},
+		}
+		if diff := cmp.Diff(want, response.UsersImpactedByRoleChange); diff != "" {
+			t.Fatalf("wrong users (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("first", func(t *testing.T) {
+		var response struct {
+			UsersImpactedByRoleChange []apitest.UserImpactedByRoleChange
+		}
+		apitest.MustExec(adminCtx, t, s, map[string]any{
+			"role":  string(marshalRoleID(role.ID)),
+			"since": since.Format(time.RFC3339),
+			"first": 2,
+		}, &response, queryUsersImpactedByRoleChange)
+
+		require.Len(t, response.UsersImpactedByRoleChange, 2)
+		require.Equal(t, string(gql.MarshalUserID(user.ID)), response.UsersImpactedByRoleChange[0].User.ID)
+		require.Equal(t, string(gql.MarshalUserID(otherUser.ID)), response.UsersImpactedByRoleChange[1].User.ID)
+	})
+
+	t.Run("no changes since the given time", func(t *testing.T) {
+		var response struct {
+			UsersImpactedByRoleChange []apitest.UserImpactedByRoleChange
+		}
+		apitest.MustExec(adminCtx, t, s, map[string]any{
+			"role":  string(marshalRoleID(role.ID)),
+			"since": time.Now().Add(time.Hour).Format(time.RFC3339),
+		}, &response, queryUsersImpactedByRoleChange)
+
+		require.Empty(t, response.UsersImpactedByRoleChange)
+	})
+}
+
+const queryUsersImpactedByRoleChange = `
+query UsersImpactedByRoleChange($role: ID!, $since: DateTime!, $first: Int) {
+	usersImpactedByRoleChange(role: $role, since: $since, first: $first) {
+		user {
+			id
+		}
+		lostPermissions {
+			displayName
+		}
+	}
+}
+`
@@ -0,0 +1,346 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// rbacConfigVersion is the version of the RBAC configuration document. It must
+// be incremented whenever the document format changes incompatibly.
+const rbacConfigVersion = 1
+
+// rbacConfig is the document that the RBAC configuration is exported to and
+// imported from. Users are referenced by username rather than by ID so that the
+// document can be imported into another instance.
+type rbacConfig struct {
+	Version     int                    `json:"version"`
+	Permissions []rbacConfigPermission `json:"permissions"`
+	Roles       []rbacConfigRole       `json:"roles"`
+}
+
+type rbacConfigPermission struct {
+	Namespace types.PermissionNamespace `json:"namespace"`
+	Action    string                    `json:"action"`
+}
+
+func (p rbacConfigPermission) displayName() string {
+	return (&types.Permission{Namespace: p.Namespace, Action: p.Action}).DisplayName()
+}
+
+type rbacConfigRole struct {
+	Name   string `json:"name"`
+	System bool   `json:"system"`
+	// Permissions are the display names of the permissions granted by the role.
+	Permissions []string             `json:"permissions"`
+	Users       []rbacConfigUserRole `json:"users"`
+}
+
+type rbacConfigUserRole struct {
+	Username  string     `json:"username"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (r *Resolver) ExportRBACConfig(ctx context.Context) (string, error) {
+	// 🚨 SECURITY: Only site administrators can export the RBAC configuration.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return "", err
+	}
+
+	config, err := exportRBACConfig(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func exportRBACConfig(ctx context.Context, db database.DB) (*rbacConfig, error) {
+	config := &rbacConfig{
+		Version:     rbacConfigVersion,
+		Permissions: []rbacConfigPermission{},
+		Roles:       []rbacConfigRole{},
+	}
+
+	permissions, err := db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range permissions {
+		config.Permissions = append(config.Permissions, rbacConfigPermission{Namespace: permission.Namespace, Action: permission.Action})
+	}
+	sort.Slice(config.Permissions, func(i, j int) bool {
+		return config.Permissions[i].displayName() < config.Permissions[j].displayName()
+	})
+
+	roles, err := db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		configRole := rbacConfigRole{
+			Name:        role.Name,
+			System:      role.System,
+			Permissions: []string{},
+			Users:       []rbacConfigUserRole{},
+		}
+
+		rolePermissions, err := db.Permissions().List(ctx, database.PermissionListOpts{
+			PaginationArgs: &database.PaginationArgs{},
+			RoleID:         role.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range rolePermissions {
+			configRole.Permissions = append(configRole.Permissions, permission.DisplayName())
+		}
+		sort.Strings(configRole.Permissions)
+
+		userRoles, err := db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: role.ID})
+		if err != nil {
+			return nil, err
+		}
+		if len(userRoles) > 0 {
+			userIDs := make([]int32, 0, len(userRoles))
+			expiresAt := make(map[int32]time.Time, len(userRoles))
+			for _, userRole := range userRoles {
+				userIDs = append(userIDs, userRole.UserID)
+				expiresAt[userRole.UserID] = userRole.ExpiresAt
+			}
+			// Deleted users are not listed, so their assignments aren't exported.
+			users, err := db.Users().List(ctx, &database.UsersListOptions{UserIDs: userIDs})
+			if err != nil {
+				return nil, err
+			}
+			for _, user := range users {
+				userRole := rbacConfigUserRole{Username: user.Username}
+				if t := expiresAt[user.ID]; !t.IsZero() {
+					userRole.ExpiresAt = &t
+				}
+				configRole.Users = append(configRole.Users, userRole)
+			}
+			sort.Slice(configRole.Users, func(i, j int) bool {
+				return configRole.Users[i].Username < configRole.Users[j].Username
+			})
+		}
+
+		config.Roles = append(config.Roles, configRole)
+	}
+
+	return config, nil
+}
+
+// errDryRun is returned from the import transaction to roll it back when the
+// import is only previewed.
+var errDryRun = errors.New("dry run")
+
+func (r *Resolver) ImportRBACConfig(ctx context.Context, args *gql.ImportRBACConfigArgs) (gql.ImportRBACConfigResultResolver, error) {
+	// 🚨 SECURITY: Only site administrators can import the RBAC configuration.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	var config rbacConfig
+	if err := json.Unmarshal([]byte(args.Config), &config); err != nil {
+		return nil, errors.Wrap(err, "invalid RBAC config")
+	}
+	if config.Version != rbacConfigVersion {
+		return nil, errors.Newf("unsupported RBAC config version %d, expected %d", config.Version, rbacConfigVersion)
+	}
+	for _, permission := range config.Permissions {
+		if !permission.Namespace.Valid() {
+			return nil, errors.Newf("invalid permission namespace %q", permission.Namespace)
+		}
+	}
+
+	var result *importRBACConfigResultResolver
+	err := r.db.WithTransact(ctx, func(tx database.DB) (err error) {
+		result, err = importRBACConfig(ctx, tx, &config)
+		if err != nil {
+			return err
+		}
+		if args.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+
+	result.dryRun = args.DryRun
+	return result, nil
+}
+
+// importRBACConfig merges the given configuration into the RBAC configuration
+// stored in db: missing permissions, roles, and assignments are created, but
+// nothing is removed.
+func importRBACConfig(ctx context.Context, db database.DB, config *rbacConfig) (*importRBACConfigResultResolver, error) {
+	result := &importRBACConfigResultResolver{warnings: []string{}}
+
+	existingPermissions, err := db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissionsByName := make(map[string]*types.Permission, len(existingPermissions))
+	for _, permission := range existingPermissions {
+		permissionsByName[permission.DisplayName()] = permission
+	}
+	for _, configPermission := range config.Permissions {
+		if _, ok := permissionsByName[configPermission.displayName()]; ok {
+			continue
+		}
+		permission, err := db.Permissions().Create(ctx, database.CreatePermissionOpts{
+			Namespace: configPermission.Namespace,
+			Action:    configPermission.Action,
+		})
+		if err != nil {
+			return nil, err
+		}
+		permissionsByName[permission.DisplayName()] = permission
+		result.permissionsCreated++
+	}
+
+	for _, configRole := range config.Roles {
+		role, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: configRole.Name})
+		if err != nil && !errcode.IsNotFound(err) {
+			return nil, err
+		}
+		if err != nil {
+			// System roles are created by Sourcegraph itself, so we never create them here.
+			if configRole.System {
+				result.warn("system role %q does not exist", configRole.Name)
+				continue
+			}
+			role, err = db.Roles().Create(ctx, configRole.Name, false)
+			if err != nil {
+				return nil, err
+			}
+			result.rolesCreated++
+		}
+
+		for _, name := range configRole.Permissions {
+			permission, ok := permissionsByName[name]
+			if !ok {
+				return nil, errors.Newf("role %q references unknown permission %q", configRole.Name, name)
+			}
+			_, err := db.RolePermissions().GetByRoleIDAndPermissionID(ctx, database.GetRolePermissionOpts{
+				RoleID:       role.ID,
+				PermissionID: permission.ID,
+			})
+			if err == nil {
+				continue
+			}
+			if !errcode.IsNotFound(err) {
+				return nil, err
+			}
+			if _, err := db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{
+				RoleID:       role.ID,
+				PermissionID: permission.ID,
+			}); err != nil {
+				return nil, err
+			}
+			result.rolePermissionsAssigned++
+		}
+
+		isAdminRole := role.System && role.Name == string(types.AdminSystemRole)
+		if isAdminRole && len(configRole.Users) > 0 {
+			if err := checkCurrentUserCanAssignAdminRole(ctx, db); err != nil {
+				return nil, err
+			}
+		}
+		for _, configUserRole := range configRole.Users {
+			// 🚨 SECURITY: The ADMIN system role grants site admin privileges, so it can
+			// only be assigned temporarily.
+			if isAdminRole && configUserRole.ExpiresAt == nil {
+				result.warn("assignment of role %q to user %q has no expiresAt", configRole.Name, configUserRole.Username)
+				continue
+			}
+			if configUserRole.ExpiresAt != nil && !configUserRole.ExpiresAt.After(time.Now()) {
+				result.warn("assignment of role %q to user %q has expired", configRole.Name, configUserRole.Username)
+				continue
+			}
+			user, err := db.Users().GetByUsername(ctx, configUserRole.Username)
+			if err != nil {
+				if errcode.IsNotFound(err) {
+					result.warn("user %q does not exist", configUserRole.Username)
+					continue
+				}
+				return nil, err
+			}
+			_, err = db.UserRoles().GetByRoleIDAndUserID(ctx, database.GetUserRoleOpts{
+				RoleID: role.ID,
+				UserID: user.ID,
+			})
+			if err == nil {
+				continue
+			}
+			if !errcode.IsNotFound(err) {
+				return nil, err
+			}
+			opts := database.AssignUserRoleOpts{RoleID: role.ID, UserID: user.ID}
+			if configUserRole.ExpiresAt != nil {
+				opts.ExpiresAt = *configUserRole.ExpiresAt
+			}
+			if _, err := db.UserRoles().Assign(ctx, opts); err != nil {
+				return nil, err
+			}
+			result.userRolesAssigned++
+		}
+	}
+
+	return result, nil
+}
+
+type importRBACConfigResultResolver struct {
+	dryRun                  bool
+	permissionsCreated      int32
+	rolesCreated            int32
+	rolePermissionsAssigned int32
+	userRolesAssigned       int32
+	warnings                []string
+}
+
+func (r *importRBACConfigResultResolver) warn(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *importRBACConfigResultResolver) DryRun() bool {
+	return r.dryRun
+}
+
+func (r *importRBACConfigResultResolver) PermissionsCreated() int32 {
+	return r.permissionsCreated
+}
+
+func (r *importRBACConfigResultResolver) RolesCreated() int32 {
+	return r.rolesCreated
+}
+
+func (r *importRBACConfigResultResolver) RolePermissionsAssigned() int32 {
+	return r.rolePermissionsAssigned
+}
+
+func (r *importRBACConfigResultResolver) UserRolesAssigned() int32 {
+	return r.userRolesAssigned
+}
+
+func (r *importRBACConfigResultResolver) Warnings() []string {
+	return r.warnings
+}
@@ -0,0 +1,231 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/log/logtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/rbac/resolvers/apitest"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestExportImportRBACConfig(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	// The source instance with a custom role that has a permission and is
+	// assigned to a user.
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	alice, err := db.Users().Create(ctx, database.NewUser{Username: "alice"})
+	require.NoError(t, err)
+	bob, err := db.Users().Create(ctx, database.NewUser{Username: "bob"})
+	require.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "PUBLISHER", false)
+	require.NoError(t, err)
+	permission, err := db.Permissions().Create(ctx, database.CreatePermissionOpts{
+		Namespace: types.BatchChangesNamespace,
+		Action:    "PUBLISH",
+	})
+	require.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: permission.ID})
+	require.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: role.ID, UserID: alice.ID})
+	require.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{
+		RoleID:    role.ID,
+		UserID:    bob.ID,
+		ExpiresAt: time.Now().Add(24 * time.Hour).Truncate(time.Second),
+	})
+	require.NoError(t, err)
+
+	// The fresh instance only knows about the same users.
+	freshDB := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	freshAdminUserID := createTestUser(t, freshDB, true).ID
+	freshAdminActorCtx := actor.WithActor(ctx, actor.FromUser(freshAdminUserID))
+
+	freshSchema, err := newSchema(freshDB, &Resolver{logger: logger, db: freshDB})
+	require.NoError(t, err)
+
+	_, err = freshDB.Users().Create(ctx, database.NewUser{Username: "alice"})
+	require.NoError(t, err)
+	_, err = freshDB.Users().Create(ctx, database.NewUser{Username: "bob"})
+	require.NoError(t, err)
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ ExportRBACConfig string }
+		errs := apitest.Exec(actorCtx, t, s, nil, &response, exportRBACConfigQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	var exported struct{ ExportRBACConfig string }
+	apitest.MustExec(adminActorCtx, t, s, nil, &exported, exportRBACConfigQuery)
+
+	t.Run("dry run", func(t *testing.T) {
+		input := map[string]any{"config": exported.ExportRBACConfig, "dryRun": true}
+		var response struct {
+			ImportRBACConfig apitest.ImportRBACConfigResult
+		}
+		apitest.MustExec(freshAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+
+		want := apitest.ImportRBACConfigResult{
+			DryRun:                  true,
+			PermissionsCreated:      1,
+			RolesCreated:            1,
+			RolePermissionsAssigned: 1,
+			UserRolesAssigned:       2,
+			Warnings:                []string{},
+		}
+		if diff := cmp.Diff(want, response.ImportRBACConfig); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+
+		// Nothing was persisted.
+		_, err := freshDB.Roles().Get(ctx, database.GetRoleOpts{Name: "PUBLISHER"})
+		assert.Error(t, err)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		input := map[string]any{"config": exported.ExportRBACConfig}
+		var response struct {
+			ImportRBACConfig apitest.ImportRBACConfigResult
+		}
+		apitest.MustExec(freshAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+
+		assert.False(t, response.ImportRBACConfig.DryRun)
+		assert.Equal(t, int32(1), response.ImportRBACConfig.RolesCreated)
+
+		var reexported struct{ ExportRBACConfig string }
+		apitest.MustExec(freshAdminActorCtx, t, freshSchema, nil, &reexported, exportRBACConfigQuery)
+
+		var want, have rbacConfig
+		require.NoError(t, json.Unmarshal([]byte(exported.ExportRBACConfig), &want))
+		require.NoError(t, json.Unmarshal([]byte(reexported.ExportRBACConfig), &have))
+		if diff := cmp.Diff(want, have); diff != "" {
+			t.Fatalf("wrong config after round trip (-want +got):\n%s", diff)
+		}
+
+		// Importing the same config again is a no-op.
+		apitest.MustExec(freshAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+		assert.Equal(t, apitest.ImportRBACConfigResult{Warnings: []string{}}, response.ImportRBACConfig)
+	})
+
+	adminRoleConfig := func(user rbacConfigUserRole) string {
+		config, err := json.Marshal(rbacConfig{
+			Version: rbacConfigVersion,
+			Roles: []rbacConfigRole{{
+				Name:   string(types.AdminSystemRole),
+				System: true,
+				Users:  []rbacConfigUserRole{user},
+			}},
+		})
+		require.NoError(t, err)
+		return string(config)
+	}
+
+	t.Run("permanent ADMIN role", func(t *testing.T) {
+		input := map[string]any{"config": adminRoleConfig(rbacConfigUserRole{Username: "alice"})}
+		var response struct {
+			ImportRBACConfig apitest.ImportRBACConfigResult
+		}
+		apitest.MustExec(freshAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+
+		want := apitest.ImportRBACConfigResult{
+			Warnings: []string{`assignment of role "ADMIN" to user "alice" has no expiresAt`},
+		}
+		if diff := cmp.Diff(want, response.ImportRBACConfig); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ADMIN role as temporary admin", func(t *testing.T) {
+		temporaryAdminID := createTestUser(t, freshDB, false).ID
+		temporaryAdminActorCtx := actor.WithActor(ctx, actor.FromUser(temporaryAdminID))
+		adminRole, err := freshDB.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.AdminSystemRole)})
+		require.NoError(t, err)
+		_, err = freshDB.UserRoles().AssignWithExpiry(ctx, database.AssignUserRoleOpts{
+			UserID:    temporaryAdminID,
+			RoleID:    adminRole.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		expiresAt := time.Now().Add(24 * time.Hour)
+		input := map[string]any{"config": adminRoleConfig(rbacConfigUserRole{Username: "alice", ExpiresAt: &expiresAt})}
+		var response struct {
+			ImportRBACConfig apitest.ImportRBACConfigResult
+		}
+		errs := apitest.Exec(temporaryAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		input := map[string]any{"config": `{"version":0}`}
+		var response struct {
+			ImportRBACConfig apitest.ImportRBACConfigResult
+		}
+		errs := apitest.Exec(freshAdminActorCtx, t, freshSchema, input, &response, importRBACConfigMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "unsupported RBAC config version 0, expected 1"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+}
+
+const exportRBACConfigQuery = `
+query {
+	exportRbacConfig
+}
+`
+
+const importRBACConfigMutation = `
+mutation ImportRBACConfig($config: String!, $dryRun: Boolean) {
+	importRbacConfig(config: $config, dryRun: $dryRun) {
+		dryRun
+		permissionsCreated
+		rolesCreated
+		rolePermissionsAssigned
+		userRolesAssigned
+		warnings
+	}
+}
+`
@@ -11,3 +11,21 @@ func TestErrIDIsZero(t *testing.T) {
 
 	assert.Equal(t, e.Error(), "invalid node id")
 }
+
+func TestErrRoleNameIsEmpty(t *testing.T) {
+	e := ErrRoleNameIsEmpty{}
+
+	assert.Equal(t, e.Error(), "role name must not be empty")
+}
+
+func TestErrDuplicateRoleName(t *testing.T) {
+	e := ErrDuplicateRoleName{Name: "TEST-ROLE"}
+
+	assert.Equal(t, e.Error(), `a role named "TEST-ROLE" already exists`)
+}
+
+func TestErrCannotDeleteSystemRole(t *testing.T) {
+	e := ErrCannotDeleteSystemRole{Name: "USER"}
+
+	assert.Equal(t, e.Error(), `the system role "USER" cannot be deleted`)
+}
@@ -1,7 +1,31 @@
 package resolvers
 
+import "fmt"
+
 type ErrIDIsZero struct{}
 
 func (e ErrIDIsZero) Error() string {
 	return "invalid node id"
 }
+
+type ErrRoleNameIsEmpty struct{}
+
+func (e ErrRoleNameIsEmpty) Error() string {
+	return "role name must not be empty"
+}
+
+type ErrDuplicateRoleName struct {
+	Name string
+}
+
+func (e ErrDuplicateRoleName) Error() string {
+	return fmt.Sprintf("a role named %q already exists", e.Name)
+}
+
+type ErrCannotDeleteSystemRole struct {
+	Name string
+}
+
+func (e ErrCannotDeleteSystemRole) Error() string {
+	return fmt.Sprintf("the system role %q cannot be deleted", e.Name)
+}
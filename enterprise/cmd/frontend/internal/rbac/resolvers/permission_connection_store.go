@@ -8,12 +8,14 @@ import (
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
 type permisionConnectionStore struct {
-	db     database.DB
-	roleID int32
-	userID int32
+	db        database.DB
+	roleID    int32
+	userID    int32
+	namespace types.PermissionNamespace
 }
 
 func (pcs *permisionConnectionStore) MarshalCursor(node gql.PermissionResolver, _ database.OrderBy) (*string, error) {
@@ -35,8 +37,9 @@ func (pcs *permisionConnectionStore) UnmarshalCursor(cursor string, _ database.O
 
 func (pcs *permisionConnectionStore) ComputeTotal(ctx context.Context) (*int32, error) {
 	count, err := pcs.db.Permissions().Count(ctx, database.PermissionListOpts{
-		RoleID: pcs.roleID,
-		UserID: pcs.userID,
+		RoleID:    pcs.roleID,
+		UserID:    pcs.userID,
+		Namespace: pcs.namespace,
 	})
 	if err != nil {
 		return nil, err
@@ -51,6 +54,7 @@ func (pcs *permisionConnectionStore) ComputeNodes(ctx context.Context, args *dat
 		PaginationArgs: args,
 		RoleID:         pcs.roleID,
 		UserID:         pcs.userID,
+		Namespace:      pcs.namespace,
 	})
 	if err != nil {
 		return nil, err
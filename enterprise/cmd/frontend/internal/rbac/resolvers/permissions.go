@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+	"sort"
 
 	"github.com/graph-gophers/graphql-go"
 
@@ -9,8 +10,11 @@ import (
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/auth"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
 func (r *Resolver) permissionByID(ctx context.Context, id graphql.ID) (gql.PermissionResolver, error) {
@@ -75,6 +79,10 @@ func (r *Resolver) Permissions(ctx context.Context, args *gql.ListPermissionArgs
 		connectionStore.roleID = roleID
 	}
 
+	if args.Namespace != nil {
+		connectionStore.namespace = types.PermissionNamespace(*args.Namespace)
+	}
+
 	return graphqlutil.NewConnectionResolver[gql.PermissionResolver](
 		&connectionStore,
 		&args.ConnectionResolverArgs,
@@ -85,3 +93,644 @@ func (r *Resolver) Permissions(ctx context.Context, args *gql.ListPermissionArgs
 		},
 	)
 }
+
+func (r *Resolver) AdminOverrideCoverage(ctx context.Context, args *gql.AdminOverrideCoverageArgs) ([]gql.PermissionResolver, error) {
+	// 🚨 SECURITY: Only site admins can query which permissions a user has through the site admin override.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	userID, err := gql.UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 {
+		return nil, errors.New("invalid user id provided")
+	}
+
+	user, err := r.db.Users().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionResolvers := []gql.PermissionResolver{}
+	// Users who aren't site admins don't bypass RBAC, so all of their permissions come from their roles.
+	if !user.SiteAdmin {
+		return permissionResolvers, nil
+	}
+
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rolePermissions, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+		PaginationArgs: &database.PaginationArgs{},
+		UserID:         userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	grantedByRole := make(map[int32]struct{}, len(rolePermissions))
+	for _, permission := range rolePermissions {
+		grantedByRole[permission.ID] = struct{}{}
+	}
+
+	for _, permission := range permissions {
+		if _, ok := grantedByRole[permission.ID]; ok {
+			continue
+		}
+		permissionResolvers = append(permissionResolvers, &permissionResolver{permission: permission})
+	}
+
+	return permissionResolvers, nil
+}
+
+func (r *Resolver) MissingPermissionsForFeature(ctx context.Context, args *gql.MissingPermissionsForFeatureArgs) ([]gql.PermissionResolver, error) {
+	// 🚨 SECURITY: Only site admins can query which permissions another user is missing.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	required, ok := rbac.RBACSchema.FeaturePermissions(args.Feature)
+	if !ok {
+		return nil, errors.Newf("unknown feature %q", args.Feature)
+	}
+
+	userID, err := gql.UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 {
+		return nil, errors.New("invalid user id provided")
+	}
+
+	user, err := r.db.Users().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionResolvers := []gql.PermissionResolver{}
+	// Site admins bypass RBAC, so they aren't missing any permissions.
+	if user.SiteAdmin {
+		return permissionResolvers, nil
+	}
+
+	userPermissions, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+		PaginationArgs: &database.PaginationArgs{},
+		UserID:         userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]struct{}, len(userPermissions))
+	for _, permission := range userPermissions {
+		granted[permission.DisplayName()] = struct{}{}
+	}
+
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsByName := make(map[string]*types.Permission, len(permissions))
+	for _, permission := range permissions {
+		permissionsByName[permission.DisplayName()] = permission
+	}
+
+	for _, permission := range required {
+		name := permission.DisplayName()
+		if _, ok := granted[name]; ok {
+			continue
+		}
+		// Permissions are synced from the RBAC schema on startup, so a required
+		// permission should exist. If it doesn't, nobody can have been granted it.
+		if p, ok := permissionsByName[name]; ok {
+			permission = p
+		}
+		permissionResolvers = append(permissionResolvers, &permissionResolver{permission: permission})
+	}
+
+	return permissionResolvers, nil
+}
+
+func (r *Resolver) PermissionCountsByNamespace(ctx context.Context) ([]gql.PermissionNamespaceCountResolver, error) {
+	// 🚨 SECURITY: Only site admins can query permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[types.PermissionNamespace]int32)
+	for _, permission := range permissions {
+		counts[permission.Namespace]++
+	}
+
+	countResolvers := make([]gql.PermissionNamespaceCountResolver, 0, len(counts))
+	for namespace, count := range counts {
+		countResolvers = append(countResolvers, &permissionNamespaceCountResolver{namespace: namespace, count: count})
+	}
+	sort.Slice(countResolvers, func(i, j int) bool {
+		return countResolvers[i].Namespace() < countResolvers[j].Namespace()
+	})
+
+	return countResolvers, nil
+}
+
+func (r *Resolver) RBACActions(ctx context.Context) ([]string, error) {
+	// 🚨 SECURITY: Only site admins can query permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Include the actions of the schema too, in case they haven't been synced to the database yet.
+	permissions = append(permissions, rbac.RBACSchema.Permissions()...)
+
+	seen := make(map[string]struct{})
+	actions := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if _, ok := seen[permission.Action]; ok {
+			continue
+		}
+		seen[permission.Action] = struct{}{}
+		actions = append(actions, permission.Action)
+	}
+	sort.Strings(actions)
+
+	return actions, nil
+}
+
+type permissionNamespaceCountResolver struct {
+	namespace types.PermissionNamespace
+	count     int32
+}
+
+func (r *permissionNamespaceCountResolver) Namespace() string {
+	return r.namespace.String()
+}
+
+func (r *permissionNamespaceCountResolver) Count() int32 {
+	return r.count
+}
+
+func (r *Resolver) PermissionMatrix(ctx context.Context, args *gql.PermissionMatrixArgs) ([]gql.PermissionMatrixRowResolver, error) {
+	// 🚨 SECURITY: Only site admins can check the permissions of other users.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int32, 0, len(args.Users))
+	for _, id := range args.Users {
+		userID, err := gql.UnmarshalUserID(id)
+		if err != nil {
+			return nil, err
+		}
+		if userID == 0 {
+			return nil, ErrIDIsZero{}
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	permissionIDs := make([]int32, 0, len(args.Permissions))
+	for _, id := range args.Permissions {
+		permissionID, err := unmarshalPermissionID(id)
+		if err != nil {
+			return nil, err
+		}
+		if permissionID == 0 {
+			return nil, ErrIDIsZero{}
+		}
+		permissionIDs = append(permissionIDs, permissionID)
+	}
+
+	rows := make([]gql.PermissionMatrixRowResolver, 0, len(userIDs))
+	if len(userIDs) == 0 {
+		return rows, nil
+	}
+
+	// The whole matrix is computed with four queries, regardless of its size.
+	users, err := r.db.Users().List(ctx, &database.UsersListOptions{UserIDs: userIDs})
+	if err != nil {
+		return nil, err
+	}
+	usersByID := make(map[int32]*types.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// Users with an active assignment of the ADMIN system role are site admins too.
+	adminRole, err := r.db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.AdminSystemRole)})
+	if err != nil {
+		return nil, err
+	}
+	adminAssignments, err := r.db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: adminRole.ID})
+	if err != nil {
+		return nil, err
+	}
+	temporaryAdmins := make(map[int32]struct{}, len(adminAssignments))
+	for _, ur := range adminAssignments {
+		temporaryAdmins[ur.UserID] = struct{}{}
+	}
+
+	granted, err := r.db.Permissions().GrantedToUsers(ctx, userIDs, permissionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, userID := range userIDs {
+		user, ok := usersByID[userID]
+		if !ok {
+			return nil, errors.Newf("user %s not found", args.Users[i])
+		}
+
+		grantedToUser := make(map[int32]struct{}, len(granted[userID]))
+		for _, permissionID := range granted[userID] {
+			grantedToUser[permissionID] = struct{}{}
+		}
+
+		_, isTemporaryAdmin := temporaryAdmins[userID]
+		row := &permissionMatrixRowResolver{user: args.Users[i], granted: make([]bool, len(permissionIDs))}
+		for j, permissionID := range permissionIDs {
+			// Site admins bypass RBAC, so they are granted every permission.
+			_, ok := grantedToUser[permissionID]
+			row.granted[j] = ok || user.SiteAdmin || isTemporaryAdmin
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+type permissionMatrixRowResolver struct {
+	user    graphql.ID
+	granted []bool
+}
+
+func (r *permissionMatrixRowResolver) User() graphql.ID {
+	return r.user
+}
+
+func (r *permissionMatrixRowResolver) Granted() []bool {
+	return r.granted
+}
+
+// licensedNamespaces maps the permission namespaces of licensed features to the
+// license feature that activates them. Permissions in namespaces that aren't
+// listed here are always effective.
+var licensedNamespaces = map[types.PermissionNamespace]func() licensing.Feature{
+	types.BatchChangesNamespace: func() licensing.Feature { return &licensing.FeatureBatchChanges{} },
+}
+
+// isNamespaceLicensed reports whether the current license activates the feature
+// that the permissions in the given namespace are for.
+func isNamespaceLicensed(namespace types.PermissionNamespace) (bool, error) {
+	feature, ok := licensedNamespaces[namespace]
+	if !ok {
+		return true, nil
+	}
+	if err := licensing.Check(feature()); err != nil {
+		if licensing.IsFeatureNotActivated(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Resolver) UsersWithInertPermissions(ctx context.Context) ([]gql.UserWithInertPermissionsResolver, error) {
+	// 🚨 SECURITY: Only site admins can check the permissions of other users.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	licensed := make(map[types.PermissionNamespace]bool)
+	var inertPermissions []*types.Permission
+	for _, permission := range permissions {
+		ok, seen := licensed[permission.Namespace]
+		if !seen {
+			if ok, err = isNamespaceLicensed(permission.Namespace); err != nil {
+				return nil, err
+			}
+			licensed[permission.Namespace] = ok
+		}
+		if !ok {
+			inertPermissions = append(inertPermissions, permission)
+		}
+	}
+
+	resolvers := []gql.UserWithInertPermissionsResolver{}
+	if len(inertPermissions) == 0 {
+		return resolvers, nil
+	}
+
+	// Find the active role assignments that grant each inert permission. A user
+	// can hold the same permission through several roles, so it's only recorded
+	// once per user.
+	userPermissions := make(map[int32][]*types.Permission)
+	usersByRole := make(map[int32][]int32)
+	for _, permission := range inertPermissions {
+		rolePermissions, err := r.db.RolePermissions().GetByPermissionID(ctx, database.GetRolePermissionOpts{PermissionID: permission.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		holders := make(map[int32]struct{})
+		for _, rolePermission := range rolePermissions {
+			userIDs, ok := usersByRole[rolePermission.RoleID]
+			if !ok {
+				userRoles, err := r.db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: rolePermission.RoleID})
+				if err != nil {
+					return nil, err
+				}
+				for _, userRole := range userRoles {
+					userIDs = append(userIDs, userRole.UserID)
+				}
+				usersByRole[rolePermission.RoleID] = userIDs
+			}
+			for _, userID := range userIDs {
+				holders[userID] = struct{}{}
+			}
+		}
+		for userID := range holders {
+			userPermissions[userID] = append(userPermissions[userID], permission)
+		}
+	}
+
+	if len(userPermissions) == 0 {
+		return resolvers, nil
+	}
+	userIDs := make([]int32, 0, len(userPermissions))
+	for userID := range userPermissions {
+		userIDs = append(userIDs, userID)
+	}
+	// Users are listed in ID order.
+	users, err := r.db.Users().List(ctx, &database.UsersListOptions{UserIDs: userIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		resolver := &userWithInertPermissionsResolver{user: gql.NewUserResolver(r.db, user)}
+		for _, permission := range userPermissions[user.ID] {
+			resolver.permissions = append(resolver.permissions, &permissionResolver{permission: permission})
+		}
+		resolvers = append(resolvers, resolver)
+	}
+
+	return resolvers, nil
+}
+
+type userWithInertPermissionsResolver struct {
+	user        *gql.UserResolver
+	permissions []gql.PermissionResolver
+}
+
+func (r *userWithInertPermissionsResolver) User() *gql.UserResolver {
+	return r.user
+}
+
+func (r *userWithInertPermissionsResolver) Permissions() []gql.PermissionResolver {
+	return r.permissions
+}
+
+func (r *Resolver) RolesAffectedByPermissionDeletion(ctx context.Context, args *gql.RolesAffectedByPermissionDeletionArgs) ([]gql.RoleResolver, error) {
+	// 🚨 SECURITY: Only site admins can query role permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	permissionID, err := unmarshalPermissionID(args.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if permissionID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	if _, err := r.db.Permissions().GetByID(ctx, database.GetPermissionOpts{ID: permissionID}); err != nil {
+		return nil, err
+	}
+
+	rolePermissions, err := r.db.RolePermissions().GetByPermissionID(ctx, database.GetRolePermissionOpts{PermissionID: permissionID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rolePermissions, func(i, j int) bool { return rolePermissions[i].RoleID < rolePermissions[j].RoleID })
+
+	roleResolvers := make([]gql.RoleResolver, 0, len(rolePermissions))
+	for _, rp := range rolePermissions {
+		role, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: rp.RoleID})
+		if err != nil {
+			return nil, err
+		}
+		roleResolvers = append(roleResolvers, &roleResolver{role: role, db: r.db})
+	}
+	return roleResolvers, nil
+}
+
+func (r *Resolver) PermissionInheritancePath(ctx context.Context, args *gql.PermissionInheritancePathArgs) ([]gql.PermissionInheritancePathResolver, error) {
+	// 🚨 SECURITY: Only site admins can trace how other users are granted permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	userID, err := gql.UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	permissionID, err := unmarshalPermissionID(args.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 || permissionID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	user, err := r.db.Users().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	permission, err := r.db.Permissions().GetByID(ctx, database.GetPermissionOpts{ID: permissionID})
+	if err != nil {
+		return nil, err
+	}
+
+	rolePermissions, err := r.db.RolePermissions().GetByPermissionID(ctx, database.GetRolePermissionOpts{PermissionID: permissionID})
+	if err != nil {
+		return nil, err
+	}
+	grantingRoles := make(map[int32]struct{}, len(rolePermissions))
+	for _, rp := range rolePermissions {
+		grantingRoles[rp.RoleID] = struct{}{}
+	}
+
+	// Only active role assignments are returned.
+	userRoles, err := r.db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(userRoles, func(i, j int) bool { return userRoles[i].RoleID < userRoles[j].RoleID })
+
+	userResolver := gql.NewUserResolver(r.db, user)
+	permissionResolver := &permissionResolver{permission: permission}
+	resolvers := []gql.PermissionInheritancePathResolver{}
+	for _, userRole := range userRoles {
+		if _, ok := grantingRoles[userRole.RoleID]; !ok {
+			continue
+		}
+		role, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: userRole.RoleID})
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, &permissionInheritancePathResolver{
+			user:       userResolver,
+			role:       &roleResolver{role: role, db: r.db},
+			permission: permissionResolver,
+		})
+	}
+	return resolvers, nil
+}
+
+type permissionInheritancePathResolver struct {
+	user       *gql.UserResolver
+	role       gql.RoleResolver
+	permission gql.PermissionResolver
+}
+
+func (r *permissionInheritancePathResolver) User() *gql.UserResolver {
+	return r.user
+}
+
+func (r *permissionInheritancePathResolver) Role() gql.RoleResolver {
+	return r.role
+}
+
+func (r *permissionInheritancePathResolver) Permission() gql.PermissionResolver {
+	return r.permission
+}
+
+func (r *Resolver) CreatePermissions(ctx context.Context, args *gql.CreatePermissionsArgs) (gql.CreatePermissionsResultResolver, error) {
+	// 🚨 SECURITY: Only site administrators can create permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	opts := make([]database.CreatePermissionOpts, 0, len(args.Permissions))
+	for _, permission := range args.Permissions {
+		namespace := types.PermissionNamespace(permission.Namespace)
+		if !namespace.Valid() {
+			return nil, errors.Newf("invalid permission namespace %q", permission.Namespace)
+		}
+		if permission.Action == "" {
+			return nil, errors.New("permission action must not be empty")
+		}
+		opts = append(opts, database.CreatePermissionOpts{Namespace: namespace, Action: permission.Action})
+	}
+
+	var result *createPermissionsResultResolver
+	err := r.db.WithTransact(ctx, func(tx database.DB) error {
+		result = &createPermissionsResultResolver{
+			created: []gql.PermissionResolver{},
+			skipped: []gql.SkippedPermissionResolver{},
+		}
+
+		existingPermissions, err := tx.Permissions().FetchAll(ctx)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]struct{}, len(existingPermissions))
+		for _, permission := range existingPermissions {
+			existing[permission.DisplayName()] = struct{}{}
+		}
+
+		var toCreate []database.CreatePermissionOpts
+		seen := make(map[string]struct{}, len(opts))
+		for _, opt := range opts {
+			displayName := (&types.Permission{Namespace: opt.Namespace, Action: opt.Action}).DisplayName()
+			if _, ok := existing[displayName]; ok {
+				result.skip(opt, "permission already exists")
+				continue
+			}
+			if _, ok := seen[displayName]; ok {
+				result.skip(opt, "permission appears more than once in the input")
+				continue
+			}
+			seen[displayName] = struct{}{}
+			toCreate = append(toCreate, opt)
+		}
+		if len(toCreate) == 0 {
+			return nil
+		}
+
+		permissions, err := tx.Permissions().BulkCreate(ctx, toCreate)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			result.created = append(result.created, &permissionResolver{permission: permission})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type createPermissionsResultResolver struct {
+	created []gql.PermissionResolver
+	skipped []gql.SkippedPermissionResolver
+}
+
+func (r *createPermissionsResultResolver) skip(opt database.CreatePermissionOpts, reason string) {
+	r.skipped = append(r.skipped, &skippedPermissionResolver{
+		namespace: opt.Namespace.String(),
+		action:    opt.Action,
+		reason:    reason,
+	})
+}
+
+func (r *createPermissionsResultResolver) CreatedPermissions() []gql.PermissionResolver {
+	return r.created
+}
+
+func (r *createPermissionsResultResolver) SkippedPermissions() []gql.SkippedPermissionResolver {
+	return r.skipped
+}
+
+type skippedPermissionResolver struct {
+	namespace string
+	action    string
+	reason    string
+}
+
+func (r *skippedPermissionResolver) Namespace() string {
+	return r.namespace
+}
+
+func (r *skippedPermissionResolver) Action() string {
+	return r.action
+}
+
+func (r *skippedPermissionResolver) Reason() string {
+	return r.reason
+}
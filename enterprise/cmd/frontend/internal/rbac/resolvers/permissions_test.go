@@ -11,6 +11,7 @@ import (
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/rbac/resolvers/apitest"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
@@ -128,6 +129,93 @@ query($first: Int!) {
 }
 `
 
+func TestPermissionsResolverNamespaceFilter(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.RBACNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+		{Namespace: types.BatchChangesNamespace, Action: "EXECUTE"},
+	})
+	require.NoError(t, err)
+
+	listPermissions := func(t *testing.T, input map[string]any) apitest.PermissionConnection {
+		t.Helper()
+		var response struct{ Permissions apitest.PermissionConnection }
+		apitest.MustExec(adminCtx, t, s, input, &response, queryPermissionConnectionByNamespace)
+		return response.Permissions
+	}
+
+	t.Run("without namespace", func(t *testing.T) {
+		permissions := listPermissions(t, map[string]any{"first": 10})
+		require.Equal(t, len(ps), permissions.TotalCount)
+		require.Len(t, permissions.Nodes, len(ps))
+	})
+
+	t.Run("with namespace", func(t *testing.T) {
+		want := []apitest.Permission{
+			{ID: string(marshalPermissionID(ps[4].ID))},
+			{ID: string(marshalPermissionID(ps[2].ID))},
+			{ID: string(marshalPermissionID(ps[0].ID))},
+		}
+
+		all := listPermissions(t, map[string]any{"first": 10, "namespace": string(types.BatchChangesNamespace)})
+		require.Equal(t, 3, all.TotalCount)
+		if diff := cmp.Diff(want, all.Nodes); diff != "" {
+			t.Fatalf("wrong permissions (-want +got):\n%s", diff)
+		}
+
+		// Paging through the filtered permissions yields the same permissions in the same order.
+		var paged []apitest.Permission
+		input := map[string]any{"first": 1, "namespace": string(types.BatchChangesNamespace)}
+		for {
+			page := listPermissions(t, input)
+			require.Equal(t, 3, page.TotalCount)
+			paged = append(paged, page.Nodes...)
+			if !page.PageInfo.HasNextPage {
+				break
+			}
+			input["after"] = *page.PageInfo.EndCursor
+		}
+		if diff := cmp.Diff(want, paged); diff != "" {
+			t.Fatalf("wrong paged permissions (-want +got):\n%s", diff)
+		}
+
+		rbacPermissions := listPermissions(t, map[string]any{"first": 10, "namespace": string(types.RBACNamespace)})
+		require.Equal(t, 2, rbacPermissions.TotalCount)
+		require.Len(t, rbacPermissions.Nodes, 2)
+	})
+}
+
+const queryPermissionConnectionByNamespace = `
+query($first: Int!, $after: String, $namespace: PermissionNamespace) {
+	permissions(first: $first, after: $after, namespace: $namespace) {
+		totalCount
+		pageInfo {
+			hasNextPage
+			endCursor
+		}
+		nodes {
+			id
+		}
+	}
+}
+`
+
 // Check if its a different user, site admin and same user
 func TestUserPermissionsListing(t *testing.T) {
 	logger := logtest.Scoped(t)
@@ -244,3 +332,778 @@ query ($node: ID!) {
 	}
 }
 `
+
+func TestAdminOverrideCoverage(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	adminWithRole := createTestUser(t, db, true)
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
+	require.NoError(t, err)
+
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{
+		RoleID:       role.ID,
+		PermissionID: ps[0].ID,
+	})
+	require.NoError(t, err)
+
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{
+		RoleID: role.ID,
+		UserID: adminWithRole.ID,
+	})
+	require.NoError(t, err)
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		input := map[string]any{"user": string(gql.MarshalUserID(admin.ID))}
+		var response struct{ AdminOverrideCoverage []apitest.Permission }
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryAdminOverrideCoverage)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	tests := []struct {
+		name string
+		user int32
+		want []apitest.Permission
+	}{
+		{
+			name: "site admin without roles",
+			user: admin.ID,
+			want: []apitest.Permission{
+				{ID: string(marshalPermissionID(ps[0].ID))},
+				{ID: string(marshalPermissionID(ps[1].ID))},
+			},
+		},
+		{
+			name: "site admin with a role",
+			user: adminWithRole.ID,
+			want: []apitest.Permission{
+				{ID: string(marshalPermissionID(ps[1].ID))},
+			},
+		},
+		{
+			name: "non site admin",
+			user: user.ID,
+			want: []apitest.Permission{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			input := map[string]any{"user": string(gql.MarshalUserID(tc.user))}
+			var response struct{ AdminOverrideCoverage []apitest.Permission }
+			apitest.MustExec(adminCtx, t, s, input, &response, queryAdminOverrideCoverage)
+
+			if diff := cmp.Diff(tc.want, response.AdminOverrideCoverage); diff != "" {
+				t.Fatalf("wrong permissions response (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const queryAdminOverrideCoverage = `
+query($user: ID!) {
+	adminOverrideCoverage(user: $user) {
+		id
+	}
+}
+`
+
+func TestMissingPermissionsForFeature(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	userWithAll := createTestUser(t, db, false)
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	readerRole, err := db.Roles().Create(ctx, "READER", false)
+	require.NoError(t, err)
+	writerRole, err := db.Roles().Create(ctx, "WRITER", false)
+	require.NoError(t, err)
+
+	for _, assignment := range []struct {
+		role       int32
+		permission int32
+	}{
+		{readerRole.ID, ps[0].ID},
+		{writerRole.ID, ps[0].ID},
+		{writerRole.ID, ps[1].ID},
+	} {
+		_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{
+			RoleID:       assignment.role,
+			PermissionID: assignment.permission,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: readerRole.ID, UserID: user.ID})
+	require.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: writerRole.ID, UserID: userWithAll.ID})
+	require.NoError(t, err)
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		input := map[string]any{"user": string(gql.MarshalUserID(user.ID)), "feature": "batch_changes"}
+		var response struct{ MissingPermissionsForFeature []apitest.Permission }
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryMissingPermissionsForFeature)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("unknown feature", func(t *testing.T) {
+		input := map[string]any{"user": string(gql.MarshalUserID(user.ID)), "feature": "unknown"}
+		var response struct{ MissingPermissionsForFeature []apitest.Permission }
+		errs := apitest.Exec(adminCtx, t, s, input, &response, queryMissingPermissionsForFeature)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, `unknown feature "unknown"`)
+	})
+
+	tests := []struct {
+		name string
+		user int32
+		want []apitest.Permission
+	}{
+		{
+			name: "user missing some permissions",
+			user: user.ID,
+			want: []apitest.Permission{
+				{ID: string(marshalPermissionID(ps[1].ID))},
+			},
+		},
+		{
+			name: "user with all permissions",
+			user: userWithAll.ID,
+			want: []apitest.Permission{},
+		},
+		{
+			name: "site admin",
+			user: admin.ID,
+			want: []apitest.Permission{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			input := map[string]any{"user": string(gql.MarshalUserID(tc.user)), "feature": "batch_changes"}
+			var response struct{ MissingPermissionsForFeature []apitest.Permission }
+			apitest.MustExec(adminCtx, t, s, input, &response, queryMissingPermissionsForFeature)
+
+			if diff := cmp.Diff(tc.want, response.MissingPermissionsForFeature); diff != "" {
+				t.Fatalf("wrong permissions response (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const queryMissingPermissionsForFeature = `
+query($user: ID!, $feature: String!) {
+	missingPermissionsForFeature(user: $user, feature: $feature) {
+		id
+	}
+}
+`
+
+func TestPermissionMatrix(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	userWithoutRoles := createTestUser(t, db, false)
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	readerRole, err := db.Roles().Create(ctx, "READER", false)
+	require.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: readerRole.ID, PermissionID: ps[0].ID})
+	require.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: readerRole.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	userIDs := []string{
+		string(gql.MarshalUserID(user.ID)),
+		string(gql.MarshalUserID(admin.ID)),
+		string(gql.MarshalUserID(userWithoutRoles.ID)),
+	}
+	input := map[string]any{
+		"users":       userIDs,
+		"permissions": []string{string(marshalPermissionID(ps[0].ID)), string(marshalPermissionID(ps[1].ID))},
+	}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct{ PermissionMatrix []apitest.PermissionMatrixRow }
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryPermissionMatrix)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct{ PermissionMatrix []apitest.PermissionMatrixRow }
+		apitest.MustExec(adminCtx, t, s, input, &response, queryPermissionMatrix)
+
+		want := []apitest.PermissionMatrixRow{
+			{User: userIDs[0], Granted: []bool{true, false}},
+			// Site admins bypass RBAC.
+			{User: userIDs[1], Granted: []bool{true, true}},
+			{User: userIDs[2], Granted: []bool{false, false}},
+		}
+		if diff := cmp.Diff(want, response.PermissionMatrix); diff != "" {
+			t.Fatalf("wrong permission matrix (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryPermissionMatrix = `
+query($users: [ID!]!, $permissions: [ID!]!) {
+	permissionMatrix(users: $users, permissions: $permissions) {
+		user
+		granted
+	}
+}
+`
+
+func TestPermissionCountsByNamespace(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	_, err = db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+		{Namespace: types.BatchChangesNamespace, Action: "PUBLISH"},
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct {
+			PermissionCountsByNamespace []apitest.PermissionNamespaceCount
+		}
+		errs := apitest.Exec(userCtx, t, s, nil, &response, queryPermissionCountsByNamespace)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct {
+			PermissionCountsByNamespace []apitest.PermissionNamespaceCount
+		}
+		apitest.MustExec(adminCtx, t, s, nil, &response, queryPermissionCountsByNamespace)
+
+		want := []apitest.PermissionNamespaceCount{
+			{Namespace: types.BatchChangesNamespace, Count: 3},
+			{Namespace: types.RBACNamespace, Count: 1},
+		}
+		if diff := cmp.Diff(want, response.PermissionCountsByNamespace); diff != "" {
+			t.Fatalf("wrong permission counts (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryPermissionCountsByNamespace = `
+query {
+	permissionCountsByNamespace {
+		namespace
+		count
+	}
+}
+`
+
+func TestRBACActions(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	_, err = db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "PUBLISH"},
+		{Namespace: types.RBACNamespace, Action: "READ"},
+	})
+	require.NoError(t, err)
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct{ RbacActions []string }
+		errs := apitest.Exec(userCtx, t, s, nil, &response, queryRBACActions)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct{ RbacActions []string }
+		apitest.MustExec(adminCtx, t, s, nil, &response, queryRBACActions)
+
+		require.Contains(t, response.RbacActions, "READ")
+
+		// PUBLISH only exists in the database, WRITE only in the schema.
+		want := []string{"PUBLISH", "READ", "WRITE"}
+		if diff := cmp.Diff(want, response.RbacActions); diff != "" {
+			t.Fatalf("wrong actions (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryRBACActions = `
+query {
+	rbacActions
+}
+`
+
+func TestUsersWithInertPermissions(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "BATCH_CHANGES_ADMIN", false)
+	require.NoError(t, err)
+	for _, p := range ps {
+		_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: p.ID})
+		require.NoError(t, err)
+	}
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{RoleID: role.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { licensing.MockCheckFeature = nil })
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct {
+			UsersWithInertPermissions []apitest.UserWithInertPermissions
+		}
+		errs := apitest.Exec(userCtx, t, s, nil, &response, queryUsersWithInertPermissions)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("feature licensed", func(t *testing.T) {
+		licensing.MockCheckFeature = func(licensing.Feature) error { return nil }
+
+		var response struct {
+			UsersWithInertPermissions []apitest.UserWithInertPermissions
+		}
+		apitest.MustExec(adminCtx, t, s, nil, &response, queryUsersWithInertPermissions)
+
+		require.Empty(t, response.UsersWithInertPermissions)
+	})
+
+	t.Run("feature not licensed", func(t *testing.T) {
+		licensing.MockCheckFeature = func(feature licensing.Feature) error {
+			return licensing.NewFeatureNotActivatedError(fmt.Sprintf("%s is not activated", feature.FeatureName()))
+		}
+
+		var response struct {
+			UsersWithInertPermissions []apitest.UserWithInertPermissions
+		}
+		apitest.MustExec(adminCtx, t, s, nil, &response, queryUsersWithInertPermissions)
+
+		// Only the permission for the unlicensed feature is inert.
+		want := []apitest.UserWithInertPermissions{
+			{
+				User:        apitest.User{ID: string(gql.MarshalUserID(user.ID))},
+				Permissions: []apitest.Permission{{DisplayName: ps[0].DisplayName()}},
+			},
+		}
+		if diff := cmp.Diff(want, response.UsersWithInertPermissions); diff != "" {
+			t.Fatalf("wrong users (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryUsersWithInertPermissions = `
+query {
+	usersWithInertPermissions {
+		user {
+			id
+		}
+		permissions {
+			displayName
+		}
+	}
+}
+`
+
+func TestRolesAffectedByPermissionDeletion(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+	readPermission, writePermission := ps[0], ps[1]
+
+	role, err := db.Roles().Create(ctx, "BATCH-CHANGES-READER", false)
+	require.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: readPermission.ID})
+	require.NoError(t, err)
+
+	input := map[string]any{"permission": string(marshalPermissionID(readPermission.ID))}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct{ RolesAffectedByPermissionDeletion []apitest.Role }
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryRolesAffectedByPermissionDeletion)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct{ RolesAffectedByPermissionDeletion []apitest.Role }
+		apitest.MustExec(adminCtx, t, s, input, &response, queryRolesAffectedByPermissionDeletion)
+
+		want := []apitest.Role{{ID: string(marshalRoleID(role.ID)), Name: role.Name}}
+		if diff := cmp.Diff(want, response.RolesAffectedByPermissionDeletion); diff != "" {
+			t.Fatalf("wrong roles (-want +got):\n%s", diff)
+		}
+
+		// No role grants the other permission.
+		input := map[string]any{"permission": string(marshalPermissionID(writePermission.ID))}
+		apitest.MustExec(adminCtx, t, s, input, &response, queryRolesAffectedByPermissionDeletion)
+		require.Empty(t, response.RolesAffectedByPermissionDeletion)
+
+		// The permission is not deleted.
+		_, err := db.Permissions().GetByID(ctx, database.GetPermissionOpts{ID: readPermission.ID})
+		require.NoError(t, err)
+	})
+}
+
+const queryRolesAffectedByPermissionDeletion = `
+query RolesAffectedByPermissionDeletion($permission: ID!) {
+	rolesAffectedByPermissionDeletion(permission: $permission) {
+		id
+		name
+	}
+}
+`
+
+func TestPermissionInheritancePath(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	require.NoError(t, err)
+	readPermission, writePermission := ps[0], ps[1]
+
+	// Both roles grant the read permission, only the writer role grants the write permission.
+	reader, err := db.Roles().Create(ctx, "BATCH-CHANGES-READER", false)
+	require.NoError(t, err)
+	writer, err := db.Roles().Create(ctx, "BATCH-CHANGES-WRITER", false)
+	require.NoError(t, err)
+	for _, rp := range []database.AssignRolePermissionOpts{
+		{RoleID: reader.ID, PermissionID: readPermission.ID},
+		{RoleID: writer.ID, PermissionID: readPermission.ID},
+		{RoleID: writer.ID, PermissionID: writePermission.ID},
+	} {
+		_, err := db.RolePermissions().Assign(ctx, rp)
+		require.NoError(t, err)
+	}
+
+	_, err = db.UserRoles().BulkAssignToUser(ctx, database.BulkAssignToUserOpts{UserID: user.ID, RoleIDs: []int32{reader.ID, writer.ID}})
+	require.NoError(t, err)
+
+	input := func(permissionID int32) map[string]any {
+		return map[string]any{
+			"user":       string(gql.MarshalUserID(user.ID)),
+			"permission": string(marshalPermissionID(permissionID)),
+		}
+	}
+	path := func(role *types.Role, permission *types.Permission) apitest.PermissionInheritancePath {
+		return apitest.PermissionInheritancePath{
+			User:       apitest.User{ID: string(gql.MarshalUserID(user.ID))},
+			Role:       apitest.Role{ID: string(marshalRoleID(role.ID)), Name: role.Name},
+			Permission: apitest.Permission{ID: string(marshalPermissionID(permission.ID)), DisplayName: permission.DisplayName()},
+		}
+	}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct {
+			PermissionInheritancePath []apitest.PermissionInheritancePath
+		}
+		errs := apitest.Exec(userCtx, t, s, input(readPermission.ID), &response, queryPermissionInheritancePath)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("single role", func(t *testing.T) {
+		var response struct {
+			PermissionInheritancePath []apitest.PermissionInheritancePath
+		}
+		apitest.MustExec(adminCtx, t, s, input(writePermission.ID), &response, queryPermissionInheritancePath)
+
+		want := []apitest.PermissionInheritancePath{path(writer, writePermission)}
+		if diff := cmp.Diff(want, response.PermissionInheritancePath); diff != "" {
+			t.Fatalf("wrong paths (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("multiple roles granting the same permission", func(t *testing.T) {
+		var response struct {
+			PermissionInheritancePath []apitest.PermissionInheritancePath
+		}
+		apitest.MustExec(adminCtx, t, s, input(readPermission.ID), &response, queryPermissionInheritancePath)
+
+		want := []apitest.PermissionInheritancePath{path(reader, readPermission), path(writer, readPermission)}
+		if diff := cmp.Diff(want, response.PermissionInheritancePath); diff != "" {
+			t.Fatalf("wrong paths (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryPermissionInheritancePath = `
+query PermissionInheritancePath($user: ID!, $permission: ID!) {
+	permissionInheritancePath(user: $user, permission: $permission) {
+		user {
+			id
+		}
+		role {
+			id
+			name
+		}
+		permission {
+			id
+			displayName
+		}
+	}
+}
+`
+
+func TestCreatePermissions(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	user := createTestUser(t, db, false)
+	userCtx := actor.WithActor(ctx, actor.FromUser(user.ID))
+
+	admin := createTestUser(t, db, true)
+	adminCtx := actor.WithActor(ctx, actor.FromUser(admin.ID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	existing, err := db.Permissions().Create(ctx, database.CreatePermissionOpts{Namespace: types.BatchChangesNamespace, Action: "READ"})
+	require.NoError(t, err)
+
+	input := map[string]any{"permissions": []map[string]any{
+		{"namespace": "BATCH_CHANGES", "action": "READ"},
+		{"namespace": "BATCH_CHANGES", "action": "WRITE"},
+		{"namespace": "RBAC", "action": "READ"},
+		{"namespace": "BATCH_CHANGES", "action": "WRITE"},
+	}}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		var response struct {
+			CreatePermissions apitest.CreatePermissionsResult
+		}
+		errs := apitest.Exec(userCtx, t, s, input, &response, mutationCreatePermissions)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		var response struct {
+			CreatePermissions apitest.CreatePermissionsResult
+		}
+		apitest.MustExec(adminCtx, t, s, input, &response, mutationCreatePermissions)
+
+		var created []string
+		for _, permission := range response.CreatePermissions.CreatedPermissions {
+			created = append(created, permission.DisplayName)
+		}
+		require.ElementsMatch(t, []string{"BATCH_CHANGES#WRITE", "RBAC#READ"}, created)
+
+		wantSkipped := []apitest.SkippedPermission{
+			{Namespace: types.BatchChangesNamespace, Action: "READ", Reason: "permission already exists"},
+			{Namespace: types.BatchChangesNamespace, Action: "WRITE", Reason: "permission appears more than once in the input"},
+		}
+		if diff := cmp.Diff(wantSkipped, response.CreatePermissions.SkippedPermissions); diff != "" {
+			t.Fatalf("wrong skipped permissions (-want +got):\n%s", diff)
+		}
+
+		permissions, err := db.Permissions().FetchAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, permissions, 3)
+
+		// The existing permission is left unchanged.
+		permission, err := db.Permissions().GetByID(ctx, database.GetPermissionOpts{ID: existing.ID})
+		require.NoError(t, err)
+		require.Equal(t, existing.CreatedAt, permission.CreatedAt)
+	})
+
+	t.Run("empty action", func(t *testing.T) {
+		input := map[string]any{"permissions": []map[string]any{
+			{"namespace": "BATCH_CHANGES", "action": "DELETE"},
+			{"namespace": "BATCH_CHANGES", "action": ""},
+		}}
+		var response struct {
+			CreatePermissions apitest.CreatePermissionsResult
+		}
+		errs := apitest.Exec(adminCtx, t, s, input, &response, mutationCreatePermissions)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "permission action must not be empty")
+
+		// Nothing is created.
+		permissions, err := db.Permissions().FetchAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, permissions, 3)
+	})
+}
+
+const mutationCreatePermissions = `
+mutation CreatePermissions($permissions: [PermissionInput!]!) {
+	createPermissions(permissions: $permissions) {
+		createdPermissions {
+			displayName
+		}
+		skippedPermissions {
+			namespace
+			action
+			reason
+		}
+	}
+}
+`
@@ -8,6 +8,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/auth"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 )
 
@@ -31,3 +32,11 @@ func (r *Resolver) NodeResolvers() map[string]graphqlbackend.NodeByIDFunc {
 		},
 	}
 }
+
+// checkCurrentUserCanAssignAdminRole returns an error unless the current user
+// can assign the ADMIN system role, which grants site admin privileges. Holding
+// the ADMIN role is not enough, as that would let temporary admins extend their
+// own access.
+func checkCurrentUserCanAssignAdminRole(ctx context.Context, db database.DB) error {
+	return auth.CheckCurrentUserIsPermanentSiteAdmin(ctx, db)
+}
@@ -2,41 +2,87 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/graph-gophers/graphql-go"
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
+// roleSortByUserCount is the RoleSortBy enum value for sorting roles by the number of users they are assigned to.
+const roleSortByUserCount = "USER_COUNT"
+
 type roleConnectionStore struct {
-	db     database.DB
-	system bool
+	ctx context.Context
+	db  database.DB
+	// system, if set, only matches system roles if true and only roles that aren't system roles if false.
+	system *bool
 	userID int32
+	// withoutPermissions is set to only match roles that have no permissions assigned to them.
+	withoutPermissions bool
+
+	// sortByUserCount is set when roles are ordered by the number of users they are assigned to. Cursors
+	// then take the form <user count>@<role ID>.
+	sortByUserCount bool
 }
 
 func (rcs *roleConnectionStore) MarshalCursor(node gql.RoleResolver, _ database.OrderBy) (*string, error) {
 	cursor := string(node.ID())
 
+	if rcs.sortByUserCount {
+		roleID, err := unmarshalRoleID(node.ID())
+		if err != nil {
+			return nil, err
+		}
+
+		userRoles, err := rcs.db.UserRoles().GetByRoleID(rcs.ctx, database.GetUserRoleOpts{RoleID: roleID})
+		if err != nil {
+			return nil, err
+		}
+
+		cursor = fmt.Sprintf("%d@%s", len(userRoles), cursor)
+	}
+
 	return &cursor, nil
 }
 
 func (rcs *roleConnectionStore) UnmarshalCursor(cursor string, _ database.OrderBy) (*string, error) {
+	var userCount int
+	if rcs.sortByUserCount {
+		values := strings.SplitN(cursor, "@", 2)
+		if len(values) != 2 {
+			return nil, errors.Newf("invalid cursor: expected <user count>@<role ID>, got %q", cursor)
+		}
+
+		var err error
+		userCount, err = strconv.Atoi(values[0])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+
+		cursor = values[1]
+	}
+
 	nodeID, err := unmarshalRoleID(graphql.ID(cursor))
 	if err != nil {
 		return nil, err
 	}
 
 	id := strconv.Itoa(int(nodeID))
+	if rcs.sortByUserCount {
+		id = fmt.Sprintf("%d, %s", userCount, id)
+	}
 
 	return &id, nil
 }
 
 func (rcs *roleConnectionStore) ComputeTotal(ctx context.Context) (*int32, error) {
-	count, err := rcs.db.Roles().Count(ctx, database.RolesListOptions{
-		UserID: rcs.userID,
-	})
+	opts := rcs.listOptions()
+	count, err := rcs.db.Roles().Count(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -46,11 +92,9 @@ func (rcs *roleConnectionStore) ComputeTotal(ctx context.Context) (*int32, error
 }
 
 func (rcs *roleConnectionStore) ComputeNodes(ctx context.Context, args *database.PaginationArgs) ([]gql.RoleResolver, error) {
-	roles, err := rcs.db.Roles().List(ctx, database.RolesListOptions{
-		PaginationArgs: args,
-		System:         rcs.system,
-		UserID:         rcs.userID,
-	})
+	opts := rcs.listOptions()
+	opts.PaginationArgs = args
+	roles, err := rcs.db.Roles().List(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -62,3 +106,15 @@ func (rcs *roleConnectionStore) ComputeNodes(ctx context.Context, args *database
 
 	return roleResolvers, nil
 }
+
+func (rcs *roleConnectionStore) listOptions() database.RolesListOptions {
+	opts := database.RolesListOptions{
+		UserID:             rcs.userID,
+		WithoutPermissions: rcs.withoutPermissions,
+	}
+	if rcs.system != nil {
+		opts.System = *rcs.system
+		opts.ExcludeSystem = !*rcs.system
+	}
+	return opts
+}
@@ -2,23 +2,46 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/log"
 
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/auth"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
 func (r *Resolver) Roles(ctx context.Context, args *gql.ListRoleArgs) (*graphqlutil.ConnectionResolver[gql.RoleResolver], error) {
 	connectionStore := roleConnectionStore{
+		ctx:    ctx,
 		db:     r.db,
 		system: args.System,
 	}
 
+	orderBy := database.OrderBy{{Field: "roles.id"}}
+	if args.SortBy == roleSortByUserCount {
+		// 🚨 SECURITY: Only site admins can see how many users a role is assigned to.
+		if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+			return nil, err
+		}
+
+		connectionStore.sortByUserCount = true
+		orderBy = database.OrderBy{{Field: database.RoleUserCountColumn}, {Field: "roles.id"}}
+	}
+
 	if args.User != nil {
 		userID, err := gql.UnmarshalUserID(*args.User)
 		if err != nil {
@@ -43,9 +66,7 @@ func (r *Resolver) Roles(ctx context.Context, args *gql.ListRoleArgs) (*graphqlu
 		&connectionStore,
 		&args.ConnectionResolverArgs,
 		&graphqlutil.ConnectionResolverOptions{
-			OrderBy: database.OrderBy{
-				{Field: "roles.id"},
-			},
+			OrderBy: orderBy,
 		},
 	)
 }
@@ -89,6 +110,17 @@ func (r *Resolver) DeleteRole(ctx context.Context, args *gql.DeleteRoleArgs) (_
 		return nil, ErrIDIsZero{}
 	}
 
+	role, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: roleID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete role")
+	}
+
+	// Deleting a system role would break authorization for everyone it is assigned to.
+	if role.System {
+		return nil, ErrCannotDeleteSystemRole{Name: role.Name}
+	}
+
+	// The permissions and assignments of the role are deleted along with it.
 	err = r.db.Roles().Delete(ctx, database.DeleteRoleOpts{
 		ID: roleID,
 	})
@@ -96,6 +128,8 @@ func (r *Resolver) DeleteRole(ctx context.Context, args *gql.DeleteRoleArgs) (_
 		return nil, err
 	}
 
+	r.logDestructiveRBACEvent(ctx, database.SecurityEventNameRBACRoleDeleted, map[string]any{"roleID": roleID})
+
 	return &gql.EmptyResponse{}, nil
 }
 
@@ -105,7 +139,48 @@ func (r *Resolver) CreateRole(ctx context.Context, args *gql.CreateRoleArgs) (gq
 		return nil, err
 	}
 
-	newRole, err := r.db.Roles().Create(ctx, args.Name, false)
+	if strings.TrimSpace(args.Name) == "" {
+		return nil, ErrRoleNameIsEmpty{}
+	}
+
+	var permissionIDs []int32
+	if args.Permissions != nil {
+		for _, id := range *args.Permissions {
+			permissionID, err := unmarshalPermissionID(id)
+			if err != nil {
+				return nil, err
+			}
+
+			if permissionID == 0 {
+				return nil, ErrIDIsZero{}
+			}
+			permissionIDs = append(permissionIDs, permissionID)
+		}
+	}
+
+	var newRole *types.Role
+	err := r.db.WithTransact(ctx, func(tx database.DB) (err error) {
+		if _, err := tx.Roles().Get(ctx, database.GetRoleOpts{Name: args.Name}); err == nil {
+			return ErrDuplicateRoleName{Name: args.Name}
+		} else if !errcode.IsNotFound(err) {
+			return err
+		}
+
+		newRole, err = tx.Roles().Create(ctx, args.Name, false)
+		if err != nil {
+			return err
+		}
+
+		for _, permissionID := range permissionIDs {
+			if _, err := tx.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{
+				RoleID:       newRole.ID,
+				PermissionID: permissionID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -115,3 +190,837 @@ func (r *Resolver) CreateRole(ctx context.Context, args *gql.CreateRoleArgs) (gq
 		role: newRole,
 	}, nil
 }
+
+func (r *Resolver) AssignRoleToUser(ctx context.Context, args *gql.AssignRoleToUserArgs) (*gql.EmptyResponse, error) {
+	// 🚨 SECURITY: Only site administrators can assign roles to users.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	userID, err := gql.UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	roleID, err := unmarshalRoleID(args.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 || roleID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	opts := database.AssignUserRoleOpts{
+		UserID: userID,
+		RoleID: roleID,
+	}
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.Time.After(time.Now()) {
+			return nil, errors.New("expiresAt must be in the future")
+		}
+		opts.ExpiresAt = args.ExpiresAt.Time
+	}
+
+	role, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: roleID})
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: The other system roles are tied to the site admin status of users,
+	// so they can't be assigned here.
+	isAdminRole := role.System && role.Name == string(types.AdminSystemRole)
+	if role.System && !isAdminRole {
+		return nil, errors.Newf("the system role %s cannot be assigned", role.Name)
+	}
+	// 🚨 SECURITY: The ADMIN system role grants site admin privileges, so it can
+	// only be assigned temporarily, and not by temporary admins.
+	if isAdminRole {
+		if err := checkCurrentUserCanAssignAdminRole(ctx, r.db); err != nil {
+			return nil, err
+		}
+		if args.ExpiresAt == nil {
+			return nil, errors.Newf("the %s role can only be assigned with an expiresAt", types.AdminSystemRole)
+		}
+	}
+
+	// Only an explicit expiresAt changes the expiration of an existing assignment,
+	// so that temporary assignments never silently become permanent.
+	assign := r.db.UserRoles().Assign
+	if args.ExpiresAt != nil {
+		assign = r.db.UserRoles().AssignWithExpiry
+	}
+	if _, err := assign(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if isAdminRole {
+		r.logAdminRoleAssigned(ctx, userID, opts.ExpiresAt)
+	}
+
+	return &gql.EmptyResponse{}, nil
+}
+
+// logAdminRoleAssigned records in the audit log that the ADMIN system role was
+// assigned to a user.
+func (r *Resolver) logAdminRoleAssigned(ctx context.Context, userID int32, expiresAt time.Time) {
+	audit.Log(ctx, r.logger, audit.Record{
+		Entity: "user roles",
+		Action: "temporary ADMIN role assigned",
+		Fields: []log.Field{
+			log.Int32("userID", userID),
+			log.Time("expiresAt", expiresAt),
+		},
+	})
+}
+
+func (r *Resolver) CopyRolesFromUser(ctx context.Context, args *gql.CopyRolesFromUserArgs) (*gql.EmptyResponse, error) {
+	// 🚨 SECURITY: Only site administrators can assign roles to users.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	sourceID, err := gql.UnmarshalUserID(args.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := gql.UnmarshalUserID(args.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceID == 0 || targetID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	if sourceID == targetID {
+		return nil, errors.New("source and target must be different users")
+	}
+
+	err = r.db.WithTransact(ctx, func(tx database.DB) error {
+		sourceRoles, err := tx.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: sourceID})
+		if err != nil {
+			return err
+		}
+
+		targetRoles, err := tx.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: targetID})
+		if err != nil {
+			return err
+		}
+
+		held := make(map[int32]struct{}, len(targetRoles))
+		for _, ur := range targetRoles {
+			held[ur.RoleID] = struct{}{}
+		}
+
+		for _, ur := range sourceRoles {
+			// Roles the target already has are left as they are, including their expiration.
+			if _, ok := held[ur.RoleID]; ok {
+				continue
+			}
+
+			role, err := tx.Roles().Get(ctx, database.GetRoleOpts{ID: ur.RoleID})
+			if err != nil {
+				return err
+			}
+			// 🚨 SECURITY: System roles are tied to the site admin status of users and to temporary
+			// admin access, so they aren't copied.
+			if role.System {
+				continue
+			}
+
+			if _, err := tx.UserRoles().Assign(ctx, database.AssignUserRoleOpts{
+				UserID:    targetID,
+				RoleID:    ur.RoleID,
+				ExpiresAt: ur.ExpiresAt,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &gql.EmptyResponse{}, nil
+}
+
+func (r *Resolver) SetRoles(ctx context.Context, args *gql.SetRolesArgs) (*gql.UserResolver, error) {
+	// 🚨 SECURITY: Only site administrators can assign roles to users.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	userID, err := gql.UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	requested := make(map[int32]struct{}, len(args.Roles))
+	for _, id := range args.Roles {
+		roleID, err := unmarshalRoleID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if roleID == 0 {
+			return nil, ErrIDIsZero{}
+		}
+		requested[roleID] = struct{}{}
+	}
+
+	var revoked []int32
+	err = r.db.WithTransact(ctx, func(tx database.DB) error {
+		for roleID := range requested {
+			role, err := tx.Roles().Get(ctx, database.GetRoleOpts{ID: roleID})
+			if err != nil {
+				return err
+			}
+			// 🚨 SECURITY: System roles are tied to the site admin status of users and to temporary
+			// admin access, so they can't be set here.
+			if role.System {
+				return errors.Newf("the system role %s cannot be set", role.Name)
+			}
+		}
+
+		current, err := tx.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: userID})
+		if err != nil {
+			return err
+		}
+		held := make(map[int32]struct{}, len(current))
+		for _, ur := range current {
+			held[ur.RoleID] = struct{}{}
+		}
+
+		for _, ur := range current {
+			if _, ok := requested[ur.RoleID]; ok {
+				continue
+			}
+			role, err := tx.Roles().Get(ctx, database.GetRoleOpts{ID: ur.RoleID})
+			if err != nil {
+				return err
+			}
+			if role.System {
+				continue
+			}
+			if err := tx.UserRoles().Revoke(ctx, database.RevokeUserRoleOpts{UserID: userID, RoleID: ur.RoleID}); err != nil {
+				return err
+			}
+			revoked = append(revoked, ur.RoleID)
+		}
+
+		for roleID := range requested {
+			// Roles the user already has are left as they are, including their expiration.
+			if _, ok := held[roleID]; ok {
+				continue
+			}
+			if _, err := tx.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: userID, RoleID: roleID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, roleID := range revoked {
+		r.logDestructiveRBACEvent(ctx, database.SecurityEventNameRBACUserRoleRevoked, map[string]any{"userID": userID, "roleID": roleID})
+	}
+
+	return gql.UserByIDInt32(ctx, r.db, userID)
+}
+
+func (r *Resolver) DeleteRoles(ctx context.Context, args *gql.DeleteRolesArgs) (gql.DeleteRolesResultResolver, error) {
+	// 🚨 SECURITY: Only site administrators can delete roles.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	var roleIDs []int32
+	seen := make(map[int32]struct{}, len(args.Roles))
+	for _, id := range args.Roles {
+		roleID, err := unmarshalRoleID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if roleID == 0 {
+			return nil, ErrIDIsZero{}
+		}
+
+		if _, ok := seen[roleID]; ok {
+			continue
+		}
+		seen[roleID] = struct{}{}
+		roleIDs = append(roleIDs, roleID)
+	}
+
+	var result *deleteRolesResultResolver
+	err := r.db.WithTransact(ctx, func(tx database.DB) error {
+		result = &deleteRolesResultResolver{
+			deleted: []graphql.ID{},
+			skipped: []gql.SkippedRoleResolver{},
+		}
+		for _, roleID := range roleIDs {
+			role, err := tx.Roles().Get(ctx, database.GetRoleOpts{ID: roleID})
+			if err != nil {
+				if errcode.IsNotFound(err) {
+					result.skip(roleID, "role not found")
+					continue
+				}
+				return err
+			}
+
+			if role.System {
+				result.skip(roleID, "system roles cannot be deleted")
+				continue
+			}
+
+			if !args.Force {
+				userRoles, err := tx.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: roleID})
+				if err != nil {
+					return err
+				}
+
+				if len(userRoles) > 0 {
+					result.skip(roleID, fmt.Sprintf("role is assigned to %d user(s)", len(userRoles)))
+					continue
+				}
+			}
+
+			if err := tx.Roles().Delete(ctx, database.DeleteRoleOpts{ID: roleID}); err != nil {
+				return err
+			}
+			result.deleted = append(result.deleted, marshalRoleID(roleID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.deleted) > 0 {
+		r.logDestructiveRBACEvent(ctx, database.SecurityEventNameRBACRolesBulkDeleted, map[string]any{
+			"roles": result.deleted,
+			"force": args.Force,
+		})
+	}
+
+	return result, nil
+}
+
+type deleteRolesResultResolver struct {
+	deleted []graphql.ID
+	skipped []gql.SkippedRoleResolver
+}
+
+func (r *deleteRolesResultResolver) skip(roleID int32, reason string) {
+	r.skipped = append(r.skipped, &skippedRoleResolver{role: marshalRoleID(roleID), reason: reason})
+}
+
+func (r *deleteRolesResultResolver) DeletedRoles() []graphql.ID {
+	return r.deleted
+}
+
+func (r *deleteRolesResultResolver) SkippedRoles() []gql.SkippedRoleResolver {
+	return r.skipped
+}
+
+type skippedRoleResolver struct {
+	role   graphql.ID
+	reason string
+}
+
+func (r *skippedRoleResolver) Role() graphql.ID {
+	return r.role
+}
+
+func (r *skippedRoleResolver) Reason() string {
+	return r.reason
+}
+
+func (r *Resolver) PreviewRoleDeletion(ctx context.Context, args *gql.PreviewRoleDeletionArgs) (gql.RoleDeletionPreviewResolver, error) {
+	// 🚨 SECURITY: Only site administrators can preview the deletion of roles.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	roleID, err := unmarshalRoleID(args.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if roleID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	if _, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: roleID}); err != nil {
+		return nil, err
+	}
+
+	userRoles, err := r.db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: roleID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Roles are usually shared by many users, so we only fetch the permissions of each role once.
+	permissionsByRole := map[int32][]*types.Permission{}
+	rolePermissions := func(roleID int32) ([]*types.Permission, error) {
+		if permissions, ok := permissionsByRole[roleID]; ok {
+			return permissions, nil
+		}
+		permissions, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+			PaginationArgs: &database.PaginationArgs{},
+			RoleID:         roleID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		permissionsByRole[roleID] = permissions
+		return permissions, nil
+	}
+
+	permissions, err := rolePermissions(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The other roles of all affected users are fetched at once.
+	userIDs := make([]int32, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		userIDs = append(userIDs, userRole.UserID)
+	}
+	allUserRoles, err := r.db.UserRoles().GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	otherRolesByUser := make(map[int32][]int32, len(userIDs))
+	for _, userRole := range allUserRoles {
+		if userRole.RoleID != roleID {
+			otherRolesByUser[userRole.UserID] = append(otherRolesByUser[userRole.UserID], userRole.RoleID)
+		}
+	}
+
+	lost := make(map[int32]struct{}, len(permissions))
+	for _, userRole := range userRoles {
+		grantedByOtherRole := map[int32]struct{}{}
+		for _, otherRoleID := range otherRolesByUser[userRole.UserID] {
+			otherPermissions, err := rolePermissions(otherRoleID)
+			if err != nil {
+				return nil, err
+			}
+			for _, permission := range otherPermissions {
+				grantedByOtherRole[permission.ID] = struct{}{}
+			}
+		}
+
+		for _, permission := range permissions {
+			if _, ok := grantedByOtherRole[permission.ID]; !ok {
+				lost[permission.ID] = struct{}{}
+			}
+		}
+	}
+
+	lostPermissions := []gql.PermissionResolver{}
+	for _, permission := range permissions {
+		if _, ok := lost[permission.ID]; ok {
+			lostPermissions = append(lostPermissions, &permissionResolver{permission: permission})
+		}
+	}
+
+	return &roleDeletionPreviewResolver{
+		affectedUserCount: int32(len(userRoles)),
+		lostPermissions:   lostPermissions,
+	}, nil
+}
+
+type roleDeletionPreviewResolver struct {
+	affectedUserCount int32
+	lostPermissions   []gql.PermissionResolver
+}
+
+func (r *roleDeletionPreviewResolver) AffectedUserCount() int32 {
+	return r.affectedUserCount
+}
+
+func (r *roleDeletionPreviewResolver) LostPermissions() []gql.PermissionResolver {
+	return r.lostPermissions
+}
+
+func (r *Resolver) RoleBlastRadius(ctx context.Context, args *gql.RoleBlastRadiusArgs) (gql.RoleBlastRadiusResolver, error) {
+	// 🚨 SECURITY: Only site administrators can evaluate the impact of role changes.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	roleID, err := unmarshalRoleID(args.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if roleID == 0 {
+		return nil, ErrIDIsZero{}
+	}
+
+	if _, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: roleID}); err != nil {
+		return nil, err
+	}
+
+	userRoles, err := r.db.UserRoles().GetByRoleID(ctx, database.GetUserRoleOpts{RoleID: roleID})
+	if err != nil {
+		return nil, err
+	}
+
+	permissionCount, err := r.db.Permissions().Count(ctx, database.PermissionListOpts{RoleID: roleID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &roleBlastRadiusResolver{
+		userCount:       int32(len(userRoles)),
+		permissionCount: int32(permissionCount),
+	}, nil
+}
+
+type roleBlastRadiusResolver struct {
+	userCount       int32
+	permissionCount int32
+}
+
+func (r *roleBlastRadiusResolver) UserCount() int32 {
+	return r.userCount
+}
+
+func (r *roleBlastRadiusResolver) PermissionCount() int32 {
+	return r.permissionCount
+}
+
+func (r *roleBlastRadiusResolver) BlastRadius() int32 {
+	// The product can overflow an Int, so it is clamped to the largest one.
+	blastRadius := int64(r.userCount) * int64(r.permissionCount)
+	if blastRadius > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(blastRadius)
+}
+
+// privilegeEscalationPermissions are the display names of the permissions that
+// let their holders manage roles and permissions, and thereby grant themselves
+// any other permission.
+var privilegeEscalationPermissions = map[string]struct{}{
+	(&types.Permission{Namespace: types.RBACNamespace, Action: "WRITE"}).DisplayName(): {},
+}
+
+func (r *Resolver) RolesWithPrivilegeEscalationRisk(ctx context.Context) ([]gql.RoleResolver, error) {
+	// 🚨 SECURITY: Only site admins can audit role definitions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	roles, err := r.db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roleResolvers := []gql.RoleResolver{}
+	for _, role := range roles {
+		// The default system roles are granted every permission automatically, so
+		// reporting them would only be noise.
+		if isDefaultSystemRole(role) {
+			continue
+		}
+		permissions, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+			PaginationArgs: &database.PaginationArgs{},
+			RoleID:         role.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range permissions {
+			if _, ok := privilegeEscalationPermissions[permission.DisplayName()]; ok {
+				roleResolvers = append(roleResolvers, &roleResolver{role: role, db: r.db})
+				break
+			}
+		}
+	}
+
+	return roleResolvers, nil
+}
+
+// isDefaultSystemRole returns whether the given role is one of rbac.DefaultSystemRoles.
+func isDefaultSystemRole(role *types.Role) bool {
+	if !role.System {
+		return false
+	}
+	for _, name := range rbac.DefaultSystemRoles {
+		if role.Name == string(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resolver) EmptyRoles(ctx context.Context, args *gql.EmptyRolesArgs) (*graphqlutil.ConnectionResolver[gql.RoleResolver], error) {
+	// 🚨 SECURITY: Only site admins can audit role definitions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	connectionStore := roleConnectionStore{
+		ctx:                ctx,
+		db:                 r.db,
+		withoutPermissions: true,
+	}
+
+	return graphqlutil.NewConnectionResolver[gql.RoleResolver](
+		&connectionStore,
+		&args.ConnectionResolverArgs,
+		&graphqlutil.ConnectionResolverOptions{
+			OrderBy: database.OrderBy{{Field: "roles.id"}},
+		},
+	)
+}
+
+func (r *Resolver) ValidateSystemRoles(ctx context.Context) ([]gql.SystemRoleValidationResolver, error) {
+	// 🚨 SECURITY: Only site admins can audit role definitions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	roles, err := r.db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+		System:         true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allPermissions, err := r.db.Permissions().FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissionsByName := make(map[string]*types.Permission, len(allPermissions))
+	for _, permission := range allPermissions {
+		permissionsByName[permission.DisplayName()] = permission
+	}
+
+	validations := make([]gql.SystemRoleValidationResolver, 0, len(roles))
+	for _, role := range roles {
+		granted, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+			PaginationArgs: &database.PaginationArgs{},
+			RoleID:         role.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		grantedByName := make(map[string]*types.Permission, len(granted))
+		for _, permission := range granted {
+			grantedByName[permission.DisplayName()] = permission
+		}
+
+		validation := &systemRoleValidationResolver{
+			role:               &roleResolver{role: role, db: r.db},
+			missingPermissions: []gql.PermissionResolver{},
+			extraPermissions:   []gql.PermissionResolver{},
+		}
+
+		expected := rbac.RBACSchema.SystemRolePermissions(types.SystemRole(role.Name))
+		expectedNames := make(map[string]struct{}, len(expected))
+		for _, permission := range expected {
+			name := permission.DisplayName()
+			expectedNames[name] = struct{}{}
+			if _, ok := grantedByName[name]; ok {
+				continue
+			}
+			// Permissions are synced from the RBAC schema on startup, so an
+			// expected permission should exist. If it doesn't, it's reported
+			// without an ID.
+			if p, ok := permissionsByName[name]; ok {
+				permission = p
+			}
+			validation.missingPermissions = append(validation.missingPermissions, &permissionResolver{permission: permission})
+		}
+		for _, permission := range granted {
+			if _, ok := expectedNames[permission.DisplayName()]; !ok {
+				validation.extraPermissions = append(validation.extraPermissions, &permissionResolver{permission: permission})
+			}
+		}
+
+		validations = append(validations, validation)
+	}
+
+	return validations, nil
+}
+
+type systemRoleValidationResolver struct {
+	role               gql.RoleResolver
+	missingPermissions []gql.PermissionResolver
+	extraPermissions   []gql.PermissionResolver
+}
+
+func (r *systemRoleValidationResolver) Role() gql.RoleResolver {
+	return r.role
+}
+
+func (r *systemRoleValidationResolver) Valid() bool {
+	return len(r.missingPermissions) == 0 && len(r.extraPermissions) == 0
+}
+
+func (r *systemRoleValidationResolver) MissingPermissions() []gql.PermissionResolver {
+	return r.missingPermissions
+}
+
+func (r *systemRoleValidationResolver) ExtraPermissions() []gql.PermissionResolver {
+	return r.extraPermissions
+}
+
+func (r *Resolver) RecentRoleChanges(ctx context.Context, args *gql.RecentRoleChangesArgs) ([]gql.RoleChangeResolver, error) {
+	// 🚨 SECURITY: Only site admins can monitor changes to roles.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	if args.First < 0 {
+		return nil, errors.New("first must be a non-negative integer")
+	}
+
+	first := int(args.First)
+	roles, err := r.db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{
+			First:   &first,
+			OrderBy: database.OrderBy{{Field: database.RoleChangedAtColumn}, {Field: "roles.id"}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changeResolvers := make([]gql.RoleChangeResolver, 0, len(roles))
+	for _, role := range roles {
+		changeResolvers = append(changeResolvers, &roleChangeResolver{role: &roleResolver{role: role, db: r.db}})
+	}
+	return changeResolvers, nil
+}
+
+func (r *Resolver) RecentAssignmentsForManagedUsers(ctx context.Context, args *gql.RecentAssignmentsForManagedUsersArgs) ([]gql.RoleAssignmentResolver, error) {
+	if args.First < 0 {
+		return nil, errors.New("first must be a non-negative integer")
+	}
+
+	// 🚨 SECURITY: Only site admins and organization admins can see the role assignments of the users they
+	// administer. Site admins administer all users. Every member of an organization can administer it, so
+	// organization admins are the users that are a member of at least one organization, and they administer
+	// only the members of their organizations.
+	opts := database.ListRecentUserRolesOpts{Limit: int(args.First)}
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		if !errors.Is(err, auth.ErrMustBeSiteAdmin) {
+			return nil, err
+		}
+		uid := actor.FromContext(ctx).UID
+		memberships, err := r.db.OrgMembers().GetByUserID(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		if len(memberships) == 0 {
+			return nil, auth.ErrMustBeSiteAdmin
+		}
+		opts.OrgsOfUserID = uid
+	}
+
+	userRoles, err := r.db.UserRoles().ListRecent(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(userRoles) == 0 {
+		return []gql.RoleAssignmentResolver{}, nil
+	}
+
+	userIDs := make([]int32, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		userIDs = append(userIDs, userRole.UserID)
+	}
+	us, err := r.db.Users().List(ctx, &database.UsersListOptions{UserIDs: userIDs})
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[int32]*gql.UserResolver, len(us))
+	for _, u := range us {
+		users[u.ID] = gql.NewUserResolver(r.db, u)
+	}
+
+	roles := make(map[int32]gql.RoleResolver)
+	resolvers := make([]gql.RoleAssignmentResolver, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		// Users that were deleted in the meantime are skipped.
+		user, ok := users[userRole.UserID]
+		if !ok {
+			continue
+		}
+		role, ok := roles[userRole.RoleID]
+		if !ok {
+			ro, err := r.db.Roles().Get(ctx, database.GetRoleOpts{ID: userRole.RoleID})
+			if err != nil {
+				return nil, err
+			}
+			role = &roleResolver{role: ro, db: r.db}
+			roles[userRole.RoleID] = role
+		}
+		resolvers = append(resolvers, &roleAssignmentResolver{user: user, role: role, userRole: userRole})
+	}
+	return resolvers, nil
+}
+
+type roleAssignmentResolver struct {
+	user     *gql.UserResolver
+	role     gql.RoleResolver
+	userRole *types.UserRole
+}
+
+func (r *roleAssignmentResolver) User() *gql.UserResolver {
+	return r.user
+}
+
+func (r *roleAssignmentResolver) Role() gql.RoleResolver {
+	return r.role
+}
+
+func (r *roleAssignmentResolver) AssignedAt() gqlutil.DateTime {
+	return gqlutil.DateTime{Time: r.userRole.CreatedAt}
+}
+
+func (r *roleAssignmentResolver) ExpiresAt() *gqlutil.DateTime {
+	if r.userRole.ExpiresAt.IsZero() {
+		return nil
+	}
+	return &gqlutil.DateTime{Time: r.userRole.ExpiresAt}
+}
+
+type roleChangeResolver struct {
+	role *roleResolver
+}
+
+func (r *roleChangeResolver) Role() gql.RoleResolver {
+	return r.role
+}
+
+func (r *roleChangeResolver) ChangeType() string {
+	if r.role.role.UpdatedAt.IsZero() {
+		return "CREATED"
+	}
+	return "UPDATED"
+}
+
+func (r *roleChangeResolver) ChangedAt() gqlutil.DateTime {
+	if r.role.role.UpdatedAt.IsZero() {
+		return gqlutil.DateTime{Time: r.role.role.CreatedAt}
+	}
+	return gqlutil.DateTime{Time: r.role.role.UpdatedAt}
+}
@@ -3,17 +3,23 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/sourcegraph/log/logtest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/rbac/resolvers/apitest"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/auth"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -37,8 +43,13 @@ func TestRoleConnectionResolver(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// All sourcegraph instances are seeded with two system roles at migration,
+	// All sourcegraph instances are seeded with three system roles at migration,
 	// so we take those into account when querying roles.
+	adminRole, err := db.Roles().Get(ctx, database.GetRoleOpts{
+		Name: string(types.AdminSystemRole),
+	})
+	assert.NoError(t, err)
+
 	siteAdminRole, err := db.Roles().Get(ctx, database.GetRoleOpts{
 		Name: string(types.SiteAdministratorSystemRole),
 	})
@@ -66,6 +77,9 @@ func TestRoleConnectionResolver(t *testing.T) {
 			{
 				ID: string(marshalRoleID(r.ID)),
 			},
+			{
+				ID: string(marshalRoleID(adminRole.ID)),
+			},
 			{
 				ID: string(marshalRoleID(siteAdminRole.ID)),
 			},
@@ -81,10 +95,10 @@ func TestRoleConnectionResolver(t *testing.T) {
 			wantTotalCount      int
 			wantNodes           []apitest.Role
 		}{
-			{firstParam: 1, wantHasNextPage: true, wantHasPreviousPage: false, wantTotalCount: 3, wantNodes: want[:1]},
-			{firstParam: 2, wantHasNextPage: true, wantHasPreviousPage: false, wantTotalCount: 3, wantNodes: want[:2]},
-			{firstParam: 3, wantHasNextPage: false, wantHasPreviousPage: false, wantTotalCount: 3, wantNodes: want},
-			{firstParam: 4, wantHasNextPage: false, wantHasPreviousPage: false, wantTotalCount: 3, wantNodes: want},
+			{firstParam: 1, wantHasNextPage: true, wantHasPreviousPage: false, wantTotalCount: 4, wantNodes: want[:1]},
+			{firstParam: 2, wantHasNextPage: true, wantHasPreviousPage: false, wantTotalCount: 4, wantNodes: want[:2]},
+			{firstParam: 4, wantHasNextPage: false, wantHasPreviousPage: false, wantTotalCount: 4, wantNodes: want},
+			{firstParam: 5, wantHasNextPage: false, wantHasPreviousPage: false, wantTotalCount: 4, wantNodes: want},
 		}
 
 		for _, tc := range tests {
@@ -125,6 +139,170 @@ query($first: Int!) {
 }
 `
 
+func TestRoleConnectionResolverSystemFilter(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	adminID := createTestUser(t, db, true).ID
+	adminCtx := actor.WithActor(ctx, actor.FromUser(adminID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	require.NoError(t, err)
+
+	// Besides the system roles every instance is seeded with, create one more system role and one custom role.
+	systemRole, err := db.Roles().Create(ctx, "TEST-SYSTEM-ROLE", true)
+	require.NoError(t, err)
+	customRole, err := db.Roles().Create(ctx, "TEST-CUSTOM-ROLE", false)
+	require.NoError(t, err)
+
+	allSystemRoles, err := db.Roles().List(ctx, database.RolesListOptions{System: true})
+	require.NoError(t, err)
+
+	listRoles := func(t *testing.T, input map[string]any) apitest.RoleConnection {
+		t.Helper()
+		var response struct{ Roles apitest.RoleConnection }
+		apitest.MustExec(adminCtx, t, s, input, &response, queryRoleConnectionBySystem)
+		return response.Roles
+	}
+
+	t.Run("system roles", func(t *testing.T) {
+		roles := listRoles(t, map[string]any{"first": 50, "system": true})
+
+		require.Equal(t, len(allSystemRoles), roles.TotalCount)
+		require.Len(t, roles.Nodes, len(allSystemRoles))
+		require.Equal(t, string(marshalRoleID(systemRole.ID)), roles.Nodes[0].ID)
+		for _, role := range roles.Nodes {
+			require.True(t, role.System)
+		}
+	})
+
+	t.Run("custom roles", func(t *testing.T) {
+		roles := listRoles(t, map[string]any{"first": 50, "system": false})
+
+		want := apitest.RoleConnection{
+			TotalCount: 1,
+			Nodes:      []apitest.Role{{ID: string(marshalRoleID(customRole.ID)), System: false}},
+		}
+		if diff := cmp.Diff(want, roles); diff != "" {
+			t.Fatalf("wrong roles response (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("all roles", func(t *testing.T) {
+		roles := listRoles(t, map[string]any{"first": 50})
+
+		require.Equal(t, len(allSystemRoles)+1, roles.TotalCount)
+		require.Len(t, roles.Nodes, len(allSystemRoles)+1)
+	})
+}
+
+const queryRoleConnectionBySystem = `
+query($first: Int!, $system: Boolean) {
+	roles(first: $first, system: $system) {
+		totalCount
+		pageInfo {
+			hasNextPage
+			hasPreviousPage
+		}
+		nodes {
+			id
+			system
+		}
+	}
+}
+`
+
+func TestRoleConnectionResolverSortByUserCount(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	userCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminID := createTestUser(t, db, true).ID
+	adminCtx := actor.WithActor(ctx, actor.FromUser(adminID))
+
+	s, err := newSchema(db, &Resolver{logger: logger, db: db})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The system roles created at migration time aren't assigned to the test users,
+	// so they sort after all roles with assigned users.
+	unassignedRole, err := db.Roles().Create(ctx, "UNASSIGNED-ROLE", false)
+	assert.NoError(t, err)
+	popularRole, err := db.Roles().Create(ctx, "POPULAR-ROLE", false)
+	assert.NoError(t, err)
+	singleUserRole, err := db.Roles().Create(ctx, "SINGLE-USER-ROLE", false)
+	assert.NoError(t, err)
+
+	for _, opts := range []database.AssignUserRoleOpts{
+		{UserID: userID, RoleID: popularRole.ID},
+		{UserID: adminID, RoleID: popularRole.ID},
+		{UserID: adminID, RoleID: singleUserRole.ID},
+	} {
+		_, err := db.UserRoles().Assign(ctx, opts)
+		assert.NoError(t, err)
+	}
+
+	t.Run("as non site-administrator", func(t *testing.T) {
+		input := map[string]any{"first": 1}
+		var response struct{ Roles apitest.RoleConnection }
+		errs := apitest.Exec(userCtx, t, s, input, &response, queryRoleConnectionSortedByUserCount)
+
+		assert.Len(t, errs, 1)
+		assert.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	t.Run("as site-administrator", func(t *testing.T) {
+		input := map[string]any{"first": 2}
+		var response struct{ Roles apitest.RoleConnection }
+		apitest.MustExec(adminCtx, t, s, input, &response, queryRoleConnectionSortedByUserCount)
+
+		want := []apitest.Role{
+			{ID: string(marshalRoleID(popularRole.ID))},
+			{ID: string(marshalRoleID(singleUserRole.ID))},
+		}
+		if diff := cmp.Diff(want, response.Roles.Nodes); diff != "" {
+			t.Fatalf("wrong roles (-want +got):\n%s", diff)
+		}
+		assert.True(t, response.Roles.PageInfo.HasNextPage)
+
+		// The next page continues with the roles that aren't assigned to anyone.
+		input = map[string]any{"first": 1, "after": *response.Roles.PageInfo.EndCursor}
+		apitest.MustExec(adminCtx, t, s, input, &response, queryRoleConnectionSortedByUserCount)
+
+		want = []apitest.Role{{ID: string(marshalRoleID(unassignedRole.ID))}}
+		if diff := cmp.Diff(want, response.Roles.Nodes); diff != "" {
+			t.Fatalf("wrong roles (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const queryRoleConnectionSortedByUserCount = `
+query($first: Int!, $after: String) {
+	roles(first: $first, after: $after, sortBy: USER_COUNT) {
+		pageInfo {
+			hasNextPage
+			endCursor
+		}
+		nodes {
+			id
+		}
+	}
+}
+`
+
 func TestUserRoleListing(t *testing.T) {
 	logger := logtest.Scoped(t)
 	if testing.Short() {
@@ -251,6 +429,10 @@ func TestDeleteRole(t *testing.T) {
 	// create a new role
 	role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
 	assert.NoError(t, err)
+	permission, err := db.Permissions().Create(ctx, database.CreatePermissionOpts{Namespace: types.BatchChangesNamespace, Action: "READ"})
+	assert.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: permission.ID})
+	assert.NoError(t, err)
 
 	t.Run("as non site-admin", func(t *testing.T) {
 		roleID := string(marshalRoleID(role.ID))
@@ -285,6 +467,30 @@ func TestDeleteRole(t *testing.T) {
 		if have, want := errs[0].Message, fmt.Sprintf("failed to delete role: role with ID %d not found", role.ID); have != want {
 			t.Fatalf("wrong error code. want=%q, have=%q", want, have)
 		}
+
+		// The permissions of the role are no longer assigned.
+		rolePermissions, err := db.RolePermissions().GetByPermissionID(ctx, database.GetRolePermissionOpts{PermissionID: permission.ID})
+		assert.NoError(t, err)
+		assert.Empty(t, rolePermissions)
+	})
+
+	t.Run("system role", func(t *testing.T) {
+		systemRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.UserSystemRole)})
+		assert.NoError(t, err)
+		input := map[string]any{"role": string(marshalRoleID(systemRole.ID))}
+
+		var response struct{ DeleteRole apitest.EmptyResponse }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, deleteRoleMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, (ErrCannotDeleteSystemRole{Name: systemRole.Name}).Error(); have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+
+		_, err = db.Roles().Get(ctx, database.GetRoleOpts{ID: systemRole.ID})
+		assert.NoError(t, err)
 	})
 }
 
@@ -341,18 +547,1259 @@ func TestCreateRole(t *testing.T) {
 		if len(errs) != 1 {
 			t.Fatalf("expected a single error, but got %d", len(errs))
 		}
-		if have, want := errs[0].Message, "cannot create role: err_name_exists"; have != want {
+		if have, want := errs[0].Message, (ErrDuplicateRoleName{Name: "TEST-ROLE"}).Error(); have != want {
 			t.Fatalf("wrong error code. want=%q, have=%q", want, have)
 		}
 	})
+
+	t.Run("with an empty name", func(t *testing.T) {
+		input := map[string]any{"name": " "}
+
+		var response struct{ CreateRole apitest.Role }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, createRoleMutation)
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, (ErrRoleNameIsEmpty{}).Error(); have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("with permissions", func(t *testing.T) {
+		ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+			{Namespace: types.BatchChangesNamespace, Action: "READ"},
+			{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+		})
+		assert.NoError(t, err)
+
+		input := map[string]any{
+			"name":        "BATCH-CHANGES-ROLE",
+			"permissions": []string{string(marshalPermissionID(ps[0].ID)), string(marshalPermissionID(ps[1].ID))},
+		}
+
+		var response struct{ CreateRole apitest.Role }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, createRoleMutation)
+
+		assert.Equal(t, "BATCH-CHANGES-ROLE", response.CreateRole.Name)
+		assert.False(t, response.CreateRole.System)
+		assert.Equal(t, 2, response.CreateRole.Permissions.TotalCount)
+		var displayNames []string
+		for _, p := range response.CreateRole.Permissions.Nodes {
+			displayNames = append(displayNames, p.DisplayName)
+		}
+		assert.ElementsMatch(t, []string{"BATCH_CHANGES#READ", "BATCH_CHANGES#WRITE"}, displayNames)
+	})
+
+	t.Run("with an unknown permission", func(t *testing.T) {
+		input := map[string]any{
+			"name":        "UNKNOWN-PERMISSION-ROLE",
+			"permissions": []string{string(marshalPermissionID(1000))},
+		}
+
+		var response struct{ CreateRole apitest.Role }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, createRoleMutation)
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+
+		// The role isn't created without its permissions.
+		_, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: "UNKNOWN-PERMISSION-ROLE"})
+		assert.Error(t, err)
+	})
 }
 
 const createRoleMutation = `
-mutation CreateRole($name: String!) {
-	createRole(name: $name) {
+mutation CreateRole($name: String!, $permissions: [ID!]) {
+	createRole(name: $name, permissions: $permissions) {
 		id
 		name
 		system
+		permissions(first: 50) {
+			nodes {
+				displayName
+			}
+			totalCount
+		}
+	}
+}
+`
+
+func TestAssignRoleToUser(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
+	assert.NoError(t, err)
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		input := map[string]any{
+			"user": string(gql.MarshalUserID(userID)),
+			"role": string(marshalRoleID(role.ID)),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("with expiry in the past", func(t *testing.T) {
+		input := map[string]any{
+			"user":      string(gql.MarshalUserID(userID)),
+			"role":      string(marshalRoleID(role.ID)),
+			"expiresAt": time.Now().Add(-time.Hour).Format(time.RFC3339),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "expiresAt must be in the future"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		input := map[string]any{
+			"user":      string(gql.MarshalUserID(userID)),
+			"role":      string(marshalRoleID(role.ID)),
+			"expiresAt": expiresAt.Format(time.RFC3339),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		ur, err := db.UserRoles().GetByRoleIDAndUserID(ctx, database.GetUserRoleOpts{UserID: userID, RoleID: role.ID})
+		assert.NoError(t, err)
+		assert.True(t, expiresAt.Equal(ur.ExpiresAt))
+
+		// Reassigning the role without an expiresAt keeps the assignment temporary.
+		delete(input, "expiresAt")
+		apitest.MustExec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		ur, err = db.UserRoles().GetByRoleIDAndUserID(ctx, database.GetUserRoleOpts{UserID: userID, RoleID: role.ID})
+		assert.NoError(t, err)
+		assert.True(t, expiresAt.Equal(ur.ExpiresAt))
+	})
+
+	t.Run("system role", func(t *testing.T) {
+		for _, name := range []types.SystemRole{types.UserSystemRole, types.SiteAdministratorSystemRole} {
+			systemRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(name)})
+			require.NoError(t, err)
+
+			input := map[string]any{
+				"user": string(gql.MarshalUserID(userID)),
+				"role": string(marshalRoleID(systemRole.ID)),
+			}
+
+			var response struct{ AssignRoleToUser apitest.EmptyResponse }
+			errs := apitest.Exec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+			require.Len(t, errs, 1)
+			require.Equal(t, fmt.Sprintf("the system role %s cannot be assigned", name), errs[0].Message)
+
+			urs, err := db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: userID})
+			require.NoError(t, err)
+			for _, ur := range urs {
+				require.NotEqual(t, systemRole.ID, ur.RoleID)
+			}
+		}
+	})
+}
+
+func TestCopyRolesFromUser(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	sourceID := createTestUser(t, db, false).ID
+	sourceCtx := actor.WithActor(ctx, actor.FromUser(sourceID))
+
+	targetID := createTestUser(t, db, false).ID
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	require.NoError(t, err)
+
+	sharedRole, err := db.Roles().Create(ctx, "SHARED-ROLE", false)
+	require.NoError(t, err)
+	sourceOnlyRole, err := db.Roles().Create(ctx, "SOURCE-ONLY-ROLE", false)
+	require.NoError(t, err)
+	targetOnlyRole, err := db.Roles().Create(ctx, "TARGET-ONLY-ROLE", false)
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	for _, opts := range []database.AssignUserRoleOpts{
+		{UserID: sourceID, RoleID: sharedRole.ID},
+		{UserID: sourceID, RoleID: sourceOnlyRole.ID, ExpiresAt: expiresAt},
+		{UserID: targetID, RoleID: sharedRole.ID},
+		{UserID: targetID, RoleID: targetOnlyRole.ID},
+	} {
+		_, err := db.UserRoles().Assign(ctx, opts)
+		require.NoError(t, err)
+	}
+
+	input := map[string]any{
+		"source": string(gql.MarshalUserID(sourceID)),
+		"target": string(gql.MarshalUserID(targetID)),
+	}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ CopyRolesFromUser apitest.EmptyResponse }
+		errs := apitest.Exec(sourceCtx, t, s, input, &response, copyRolesFromUserMutation)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "must be site admin", errs[0].Message)
+	})
+
+	t.Run("same user", func(t *testing.T) {
+		input := map[string]any{
+			"source": string(gql.MarshalUserID(sourceID)),
+			"target": string(gql.MarshalUserID(sourceID)),
+		}
+
+		var response struct{ CopyRolesFromUser apitest.EmptyResponse }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, copyRolesFromUserMutation)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "source and target must be different users", errs[0].Message)
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ CopyRolesFromUser apitest.EmptyResponse }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, copyRolesFromUserMutation)
+
+		urs, err := db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: targetID})
+		require.NoError(t, err)
+
+		roleIDs := make([]int32, 0, len(urs))
+		for _, ur := range urs {
+			roleIDs = append(roleIDs, ur.RoleID)
+			// The copied role keeps its expiration.
+			if ur.RoleID == sourceOnlyRole.ID {
+				assert.True(t, expiresAt.Equal(ur.ExpiresAt))
+			}
+		}
+		// The target gains the source's roles, and keeps their own.
+		assert.ElementsMatch(t, []int32{sharedRole.ID, sourceOnlyRole.ID, targetOnlyRole.ID}, roleIDs)
+
+		// The source's roles are unchanged.
+		urs, err = db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: sourceID})
+		require.NoError(t, err)
+		assert.Len(t, urs, 2)
+	})
+
+	t.Run("from a site admin", func(t *testing.T) {
+		siteAdminID := createTestUser(t, db, true).ID
+		for _, role := range []types.SystemRole{types.UserSystemRole, types.SiteAdministratorSystemRole} {
+			_, err := db.UserRoles().AssignSystemRole(ctx, database.AssignSystemRoleOpts{UserID: siteAdminID, Role: role})
+			require.NoError(t, err)
+		}
+		_, err := db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: siteAdminID, RoleID: sharedRole.ID})
+		require.NoError(t, err)
+
+		newTargetID := createTestUser(t, db, false).ID
+		input := map[string]any{
+			"source": string(gql.MarshalUserID(siteAdminID)),
+			"target": string(gql.MarshalUserID(newTargetID)),
+		}
+
+		var response struct{ CopyRolesFromUser apitest.EmptyResponse }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, copyRolesFromUserMutation)
+
+		// Only the non-system role is copied.
+		urs, err := db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: newTargetID})
+		require.NoError(t, err)
+		require.Len(t, urs, 1)
+		assert.Equal(t, sharedRole.ID, urs[0].RoleID)
+		require.ErrorIs(t, auth.CheckUserIsSiteAdmin(ctx, db, newTargetID), auth.ErrMustBeSiteAdmin)
+	})
+}
+
+const copyRolesFromUserMutation = `
+mutation CopyRolesFromUser($source: ID!, $target: ID!) {
+	copyRolesFromUser(source: $source, target: $target) {
+		alwaysNil
+	}
+}
+`
+
+func TestSetRoles(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	require.NoError(t, err)
+
+	keptRole, err := db.Roles().Create(ctx, "KEPT-ROLE", false)
+	require.NoError(t, err)
+	revokedRole, err := db.Roles().Create(ctx, "REVOKED-ROLE", false)
+	require.NoError(t, err)
+	addedRole, err := db.Roles().Create(ctx, "ADDED-ROLE", false)
+	require.NoError(t, err)
+	userRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.UserSystemRole)})
+	require.NoError(t, err)
+
+	setRoles := func(t *testing.T, ctx context.Context, roles ...int32) (apitest.User, []*gqlerrors.QueryError) {
+		t.Helper()
+		roleIDs := make([]string, 0, len(roles))
+		for _, id := range roles {
+			roleIDs = append(roleIDs, string(marshalRoleID(id)))
+		}
+		input := map[string]any{
+			"user":  string(gql.MarshalUserID(userID)),
+			"roles": roleIDs,
+		}
+
+		var response struct{ SetRoles apitest.User }
+		errs := apitest.Exec(ctx, t, s, input, &response, setRolesMutation)
+		return response.SetRoles, errs
+	}
+
+	roleNames := func(user apitest.User) []string {
+		names := make([]string, 0, len(user.Roles.Nodes))
+		for _, role := range user.Roles.Nodes {
+			names = append(names, role.Name)
+		}
+		return names
+	}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		_, errs := setRoles(t, actorCtx, keptRole.ID)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "must be site admin", errs[0].Message)
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		user, errs := setRoles(t, adminActorCtx, keptRole.ID, revokedRole.ID)
+		require.Empty(t, errs)
+		// The user keeps their system role.
+		assert.ElementsMatch(t, []string{userRole.Name, keptRole.Name, revokedRole.Name}, roleNames(user))
+
+		user, errs = setRoles(t, adminActorCtx, keptRole.ID, addedRole.ID)
+		require.Empty(t, errs)
+		assert.ElementsMatch(t, []string{userRole.Name, keptRole.Name, addedRole.Name}, roleNames(user))
+
+		user, errs = setRoles(t, adminActorCtx)
+		require.Empty(t, errs)
+		assert.ElementsMatch(t, []string{userRole.Name}, roleNames(user))
+	})
+
+	t.Run("system role", func(t *testing.T) {
+		_, errs := setRoles(t, adminActorCtx, keptRole.ID, userRole.ID)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, fmt.Sprintf("the system role %s cannot be set", userRole.Name), errs[0].Message)
+
+		// Nothing is assigned when the input is rejected.
+		urs, err := db.UserRoles().GetByUserID(ctx, database.GetUserRoleOpts{UserID: userID})
+		require.NoError(t, err)
+		require.Len(t, urs, 1)
+		assert.Equal(t, userRole.ID, urs[0].RoleID)
+	})
+}
+
+const setRolesMutation = `
+mutation SetRoles($user: ID!, $roles: [ID!]!) {
+	setRoles(user: $user, roles: $roles) {
+		id
+		roles(first: 50) {
+			nodes {
+				name
+			}
+		}
+	}
+}
+`
+
+func TestTemporaryAdminRole(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	// Each check gets a fresh actor, since actors cache the user.
+	userCtx := func() context.Context { return actor.WithActor(ctx, actor.FromUser(userID)) }
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	require.NoError(t, err)
+
+	adminRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.AdminSystemRole)})
+	require.NoError(t, err)
+	require.True(t, adminRole.System)
+
+	t.Run("without expiry", func(t *testing.T) {
+		input := map[string]any{
+			"user": string(gql.MarshalUserID(userID)),
+			"role": string(marshalRoleID(adminRole.ID)),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		errs := apitest.Exec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "the ADMIN role can only be assigned with an expiresAt", errs[0].Message)
+		require.ErrorIs(t, auth.CheckCurrentUserIsSiteAdmin(userCtx(), db), auth.ErrMustBeSiteAdmin)
+	})
+
+	t.Run("active assignment", func(t *testing.T) {
+		input := map[string]any{
+			"user":      string(gql.MarshalUserID(userID)),
+			"role":      string(marshalRoleID(adminRole.ID)),
+			"expiresAt": time.Now().Add(time.Hour).Format(time.RFC3339),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, assignRoleToUserMutation)
+
+		require.NoError(t, auth.CheckCurrentUserIsSiteAdmin(userCtx(), db))
+		require.NoError(t, auth.CheckUserIsSiteAdmin(ctx, db, userID))
+		require.ErrorIs(t, auth.CheckCurrentUserIsPermanentSiteAdmin(userCtx(), db), auth.ErrMustBeSiteAdmin)
+	})
+
+	t.Run("temporary admin extending their own access", func(t *testing.T) {
+		input := map[string]any{
+			"user":      string(gql.MarshalUserID(userID)),
+			"role":      string(marshalRoleID(adminRole.ID)),
+			"expiresAt": time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		}
+
+		var response struct{ AssignRoleToUser apitest.EmptyResponse }
+		errs := apitest.Exec(userCtx(), t, s, input, &response, assignRoleToUserMutation)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "must be site admin", errs[0].Message)
+	})
+
+	t.Run("expired assignment", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "UPDATE user_roles SET expires_at = NOW() - INTERVAL '1 minute' WHERE user_id = $1 AND role_id = $2", userID, adminRole.ID)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, auth.CheckCurrentUserIsSiteAdmin(userCtx(), db), auth.ErrMustBeSiteAdmin)
+		require.ErrorIs(t, auth.CheckUserIsSiteAdmin(ctx, db, userID), auth.ErrMustBeSiteAdmin)
+	})
+}
+
+const assignRoleToUserMutation = `
+mutation AssignRoleToUser($user: ID!, $role: ID!, $expiresAt: DateTime) {
+	assignRoleToUser(user: $user, role: $role, expiresAt: $expiresAt) {
+		alwaysNil
+	}
+}
+`
+
+func TestDeleteRoles(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	systemRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.UserSystemRole)})
+	assert.NoError(t, err)
+
+	unusedRole, err := db.Roles().Create(ctx, "UNUSED-ROLE", false)
+	assert.NoError(t, err)
+	assignedRole, err := db.Roles().Create(ctx, "ASSIGNED-ROLE", false)
+	assert.NoError(t, err)
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: userID, RoleID: assignedRole.ID})
+	assert.NoError(t, err)
+
+	input := map[string]any{"roles": []string{
+		string(marshalRoleID(unusedRole.ID)),
+		string(marshalRoleID(assignedRole.ID)),
+		string(marshalRoleID(systemRole.ID)),
+	}}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ DeleteRoles apitest.DeleteRolesResult }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, deleteRolesMutation)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ DeleteRoles apitest.DeleteRolesResult }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, deleteRolesMutation)
+
+		want := apitest.DeleteRolesResult{
+			DeletedRoles: []string{string(marshalRoleID(unusedRole.ID))},
+			SkippedRoles: []apitest.SkippedRole{
+				{Role: string(marshalRoleID(assignedRole.ID)), Reason: "role is assigned to 1 user(s)"},
+				{Role: string(marshalRoleID(systemRole.ID)), Reason: "system roles cannot be deleted"},
+			},
+		}
+		if diff := cmp.Diff(want, response.DeleteRoles); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+
+		_, err := db.Roles().Get(ctx, database.GetRoleOpts{ID: unusedRole.ID})
+		assert.Error(t, err)
+		_, err = db.Roles().Get(ctx, database.GetRoleOpts{ID: systemRole.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("with force", func(t *testing.T) {
+		forceInput := map[string]any{
+			"roles": []string{string(marshalRoleID(assignedRole.ID))},
+			"force": true,
+		}
+
+		var response struct{ DeleteRoles apitest.DeleteRolesResult }
+		apitest.MustExec(adminActorCtx, t, s, forceInput, &response, deleteRolesMutation)
+
+		want := apitest.DeleteRolesResult{
+			DeletedRoles: []string{string(marshalRoleID(assignedRole.ID))},
+			SkippedRoles: []apitest.SkippedRole{},
+		}
+		if diff := cmp.Diff(want, response.DeleteRoles); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const deleteRolesMutation = `
+mutation DeleteRoles($roles: [ID!]!, $force: Boolean) {
+	deleteRoles(roles: $roles, force: $force) {
+		deletedRoles
+		skippedRoles {
+			role
+			reason
+		}
+	}
+}
+`
+
+func TestPreviewRoleDeletion(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	assert.NoError(t, err)
+	readPermission, writePermission := ps[0], ps[1]
+
+	// The deleted role grants both permissions, but READ is also granted by another role that
+	// both users have, so only WRITE is lost.
+	deletedRole, err := db.Roles().Create(ctx, "DELETED-ROLE", false)
+	assert.NoError(t, err)
+	otherRole, err := db.Roles().Create(ctx, "OTHER-ROLE", false)
+	assert.NoError(t, err)
+	for _, opts := range []database.AssignRolePermissionOpts{
+		{RoleID: deletedRole.ID, PermissionID: readPermission.ID},
+		{RoleID: deletedRole.ID, PermissionID: writePermission.ID},
+		{RoleID: otherRole.ID, PermissionID: readPermission.ID},
+	} {
+		_, err := db.RolePermissions().Assign(ctx, opts)
+		assert.NoError(t, err)
+	}
+
+	otherUserID := createTestUser(t, db, false).ID
+	for _, uid := range []int32{userID, otherUserID} {
+		_, err := db.UserRoles().BulkAssignToUser(ctx, database.BulkAssignToUserOpts{
+			UserID:  uid,
+			RoleIDs: []int32{deletedRole.ID, otherRole.ID},
+		})
+		assert.NoError(t, err)
+	}
+
+	input := map[string]any{"role": string(marshalRoleID(deletedRole.ID))}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ PreviewRoleDeletion apitest.RoleDeletionPreview }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, previewRoleDeletionQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ PreviewRoleDeletion apitest.RoleDeletionPreview }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, previewRoleDeletionQuery)
+
+		want := apitest.RoleDeletionPreview{
+			AffectedUserCount: 2,
+			LostPermissions: []apitest.Permission{
+				{ID: string(marshalPermissionID(writePermission.ID))},
+			},
+		}
+		if diff := cmp.Diff(want, response.PreviewRoleDeletion); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+
+		// Previewing doesn't delete anything.
+		_, err := db.Roles().Get(ctx, database.GetRoleOpts{ID: deletedRole.ID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("permission granted exclusively by the role", func(t *testing.T) {
+		// A user who only has the deleted role loses READ as well.
+		exclusiveUserID := createTestUser(t, db, false).ID
+		_, err := db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: exclusiveUserID, RoleID: deletedRole.ID})
+		assert.NoError(t, err)
+
+		var response struct{ PreviewRoleDeletion apitest.RoleDeletionPreview }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, previewRoleDeletionQuery)
+
+		want := apitest.RoleDeletionPreview{
+			AffectedUserCount: 3,
+			LostPermissions: []apitest.Permission{
+				{ID: string(marshalPermissionID(readPermission.ID))},
+				{ID: string(marshalPermissionID(writePermission.ID))},
+			},
+		}
+		if diff := cmp.Diff(want, response.PreviewRoleDeletion); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const previewRoleDeletionQuery = `
+query PreviewRoleDeletion($role: ID!) {
+	previewRoleDeletion(role: $role) {
+		affectedUserCount
+		lostPermissions {
+			id
+		}
+	}
+}
+`
+
+func TestRoleBlastRadius(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	// The role grants 3 permissions and is assigned to 2 users.
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+		{Namespace: types.RBACNamespace, Action: "READ"},
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+	})
+	assert.NoError(t, err)
+
+	role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
+	assert.NoError(t, err)
+	for _, p := range ps[:3] {
+		_, err := db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: role.ID, PermissionID: p.ID})
+		assert.NoError(t, err)
+	}
+	for _, uid := range []int32{userID, createTestUser(t, db, false).ID} {
+		_, err := db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: uid, RoleID: role.ID})
+		assert.NoError(t, err)
+	}
+
+	input := map[string]any{"role": string(marshalRoleID(role.ID))}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ RoleBlastRadius apitest.RoleBlastRadius }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, roleBlastRadiusQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ RoleBlastRadius apitest.RoleBlastRadius }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, roleBlastRadiusQuery)
+
+		want := apitest.RoleBlastRadius{UserCount: 2, PermissionCount: 3, BlastRadius: 6}
+		if diff := cmp.Diff(want, response.RoleBlastRadius); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestRoleBlastRadiusResolver(t *testing.T) {
+	r := &roleBlastRadiusResolver{userCount: 2, permissionCount: 3}
+	assert.Equal(t, int32(6), r.BlastRadius())
+
+	// The product doesn't fit in an Int.
+	r = &roleBlastRadiusResolver{userCount: 100000, permissionCount: 100000}
+	assert.Equal(t, int32(math.MaxInt32), r.BlastRadius())
+}
+
+const roleBlastRadiusQuery = `
+query RoleBlastRadius($role: ID!) {
+	roleBlastRadius(role: $role) {
+		userCount
+		permissionCount
+		blastRadius
+	}
+}
+`
+
+func TestRolesWithPrivilegeEscalationRisk(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.RBACNamespace, Action: "WRITE"},
+		{Namespace: types.RBACNamespace, Action: "READ"},
+		{Namespace: types.BatchChangesNamespace, Action: "WRITE"},
+	})
+	assert.NoError(t, err)
+	rbacWritePermission, rbacReadPermission, batchChangesWritePermission := ps[0], ps[1], ps[2]
+
+	// Only the role that can manage roles and permissions is a risk. Being able
+	// to read them, or to write anything else, is not.
+	riskyRole, err := db.Roles().Create(ctx, "RBAC-MANAGER", false)
+	assert.NoError(t, err)
+	safeRole, err := db.Roles().Create(ctx, "BATCH-CHANGES-WRITER", false)
+	assert.NoError(t, err)
+	for _, opts := range []database.AssignRolePermissionOpts{
+		{RoleID: riskyRole.ID, PermissionID: rbacWritePermission.ID},
+		{RoleID: safeRole.ID, PermissionID: rbacReadPermission.ID},
+		{RoleID: safeRole.ID, PermissionID: batchChangesWritePermission.ID},
+	} {
+		_, err := db.RolePermissions().Assign(ctx, opts)
+		assert.NoError(t, err)
+	}
+	// The seeded system roles are granted every permission, like UpdatePermissions does.
+	for _, p := range ps {
+		_, err := db.RolePermissions().BulkAssignPermissionsToSystemRoles(ctx, database.BulkAssignPermissionsToSystemRolesOpts{
+			Roles:        rbac.DefaultSystemRoles,
+			PermissionID: p.ID,
+		})
+		assert.NoError(t, err)
+	}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ RolesWithPrivilegeEscalationRisk []apitest.Role }
+		errs := apitest.Exec(actorCtx, t, s, nil, &response, rolesWithPrivilegeEscalationRiskQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ RolesWithPrivilegeEscalationRisk []apitest.Role }
+		apitest.MustExec(adminActorCtx, t, s, nil, &response, rolesWithPrivilegeEscalationRiskQuery)
+
+		want := []apitest.Role{{ID: string(marshalRoleID(riskyRole.ID)), Name: riskyRole.Name}}
+		if diff := cmp.Diff(want, response.RolesWithPrivilegeEscalationRisk); diff != "" {
+			t.Fatalf("wrong roles (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const rolesWithPrivilegeEscalationRiskQuery = `
+query {
+	rolesWithPrivilegeEscalationRisk {
+		id
+		name
+	}
+}
+`
+
+func TestEmptyRoles(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	require.NoError(t, err)
+
+	permission, err := db.Permissions().Create(ctx, database.CreatePermissionOpts{Namespace: types.BatchChangesNamespace, Action: "READ"})
+	require.NoError(t, err)
+
+	emptyRole, err := db.Roles().Create(ctx, "EMPTY-ROLE", false)
+	require.NoError(t, err)
+	populatedRole, err := db.Roles().Create(ctx, "POPULATED-ROLE", false)
+	require.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: populatedRole.ID, PermissionID: permission.ID})
+	require.NoError(t, err)
+
+	input := map[string]any{"first": 50}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ EmptyRoles apitest.RoleConnection }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, emptyRolesQuery)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, "must be site admin", errs[0].Message)
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ EmptyRoles apitest.RoleConnection }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, emptyRolesQuery)
+
+		var names []string
+		for _, role := range response.EmptyRoles.Nodes {
+			names = append(names, role.Name)
+		}
+		assert.Equal(t, len(names), response.EmptyRoles.TotalCount)
+		assert.Contains(t, names, emptyRole.Name)
+		assert.NotContains(t, names, populatedRole.Name)
+	})
+}
+
+const emptyRolesQuery = `
+query($first: Int!) {
+	emptyRoles(first: $first) {
+		totalCount
+		nodes {
+			id
+			name
+		}
+	}
+}
+`
+
+func TestRecentRoleChanges(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	updatedRole, err := db.Roles().Create(ctx, "UPDATED-ROLE", false)
+	assert.NoError(t, err)
+	createdRole, err := db.Roles().Create(ctx, "CREATED-ROLE", false)
+	assert.NoError(t, err)
+
+	// The role that was created first is updated last, so it sorts first.
+	updatedRole.Name = "RENAMED-ROLE"
+	_, err = db.Roles().Update(ctx, updatedRole)
+	assert.NoError(t, err)
+
+	input := map[string]any{"first": 2}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ RecentRoleChanges []apitest.RoleChange }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, recentRoleChangesQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ RecentRoleChanges []apitest.RoleChange }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, recentRoleChangesQuery)
+
+		want := []apitest.RoleChange{
+			{Role: apitest.Role{ID: string(marshalRoleID(updatedRole.ID)), Name: "RENAMED-ROLE"}, ChangeType: "UPDATED"},
+			{Role: apitest.Role{ID: string(marshalRoleID(createdRole.ID)), Name: "CREATED-ROLE"}, ChangeType: "CREATED"},
+		}
+		if diff := cmp.Diff(want, response.RecentRoleChanges); diff != "" {
+			t.Fatalf("wrong role changes (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const recentRoleChangesQuery = `
+query RecentRoleChanges($first: Int) {
+	recentRoleChanges(first: $first) {
+		role {
+			id
+			name
+		}
+		changeType
+	}
+}
+`
+
+func TestRecentAssignmentsForManagedUsers(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	require.NoError(t, err)
+
+	// The admin, alice and dave are members of acme, bob is only a member of globex, and carol and the
+	// other admin aren't members of any organization.
+	admin := createTestUser(t, db, true)
+	otherAdmin := createTestUser(t, db, true)
+	alice := createTestUser(t, db, false)
+	bob := createTestUser(t, db, false)
+	carol := createTestUser(t, db, false)
+	dave := createTestUser(t, db, false)
+	acme, err := db.Orgs().Create(ctx, "acme", nil)
+	require.NoError(t, err)
+	globex, err := db.Orgs().Create(ctx, "globex", nil)
+	require.NoError(t, err)
+	for _, membership := range []struct{ orgID, userID int32 }{
+		{acme.ID, admin.ID},
+		{acme.ID, alice.ID},
+		{acme.ID, dave.ID},
+		{globex.ID, bob.ID},
+	} {
+		_, err := db.OrgMembers().Create(ctx, membership.orgID, membership.userID)
+		require.NoError(t, err)
+	}
+
+	manager, err := db.Roles().Create(ctx, "MANAGER", false)
+	require.NoError(t, err)
+	reader, err := db.Roles().Create(ctx, "READER", false)
+	require.NoError(t, err)
+	writer, err := db.Roles().Create(ctx, "WRITER", false)
+	require.NoError(t, err)
+	for _, assignment := range []database.AssignUserRoleOpts{
+		{UserID: dave.ID, RoleID: manager.ID},
+		{UserID: alice.ID, RoleID: reader.ID},
+		{UserID: bob.ID, RoleID: reader.ID},
+		{UserID: carol.ID, RoleID: writer.ID},
+		{UserID: admin.ID, RoleID: writer.ID},
+		{UserID: alice.ID, RoleID: writer.ID},
+	} {
+		_, err := db.UserRoles().Assign(ctx, assignment)
+		require.NoError(t, err)
+	}
+
+	assignments := func(response []apitest.RoleAssignment) []string {
+		var assignments []string
+		for _, a := range response {
+			assignments = append(assignments, fmt.Sprintf("%s:%s", a.User.Username, a.Role.Name))
+		}
+		return assignments
+	}
+
+	t.Run("without organizations", func(t *testing.T) {
+		var response struct{ RecentAssignmentsForManagedUsers []apitest.RoleAssignment }
+		errs := apitest.Exec(actor.WithActor(ctx, actor.FromUser(carol.ID)), t, s, map[string]any{}, &response, recentAssignmentsForManagedUsersQuery)
+
+		require.Len(t, errs, 1)
+		require.Equal(t, errs[0].Message, "must be site admin")
+	})
+
+	allAssignments := []string{
+		alice.Username + ":WRITER",
+		admin.Username + ":WRITER",
+		carol.Username + ":WRITER",
+		bob.Username + ":READER",
+		alice.Username + ":READER",
+		dave.Username + ":MANAGER",
+	}
+	// The assignments of bob and carol aren't returned to members of acme, because they aren't members of acme.
+	acmeAssignments := []string{
+		alice.Username + ":WRITER",
+		admin.Username + ":WRITER",
+		alice.Username + ":READER",
+		dave.Username + ":MANAGER",
+	}
+	for _, tc := range []struct {
+		name string
+		user *types.User
+		want []string
+	}{
+		{
+			name: "site admin",
+			user: admin,
+			want: allAssignments,
+		},
+		{
+			// Site admins administer all users, even if they aren't members of any organization.
+			name: "site admin without organizations",
+			user: otherAdmin,
+			want: allAssignments,
+		},
+		{
+			name: "organization admin",
+			user: dave,
+			want: acmeAssignments,
+		},
+		{
+			name: "other organization admin",
+			user: alice,
+			want: acmeAssignments,
+		},
+		{
+			name: "organization admin of another organization",
+			user: bob,
+			want: []string{bob.Username + ":READER"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			userCtx := actor.WithActor(ctx, actor.FromUser(tc.user.ID))
+
+			var response struct{ RecentAssignmentsForManagedUsers []apitest.RoleAssignment }
+			apitest.MustExec(userCtx, t, s, map[string]any{}, &response, recentAssignmentsForManagedUsersQuery)
+			if diff := cmp.Diff(tc.want, assignments(response.RecentAssignmentsForManagedUsers)); diff != "" {
+				t.Fatalf("wrong assignments (-want +got):\n%s", diff)
+			}
+
+			apitest.MustExec(userCtx, t, s, map[string]any{"first": 1}, &response, recentAssignmentsForManagedUsersQuery)
+			if diff := cmp.Diff(tc.want[:1], assignments(response.RecentAssignmentsForManagedUsers)); diff != "" {
+				t.Fatalf("wrong assignments (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const recentAssignmentsForManagedUsersQuery = `
+query RecentAssignmentsForManagedUsers($first: Int) {
+	recentAssignmentsForManagedUsers(first: $first) {
+		user {
+			username
+		}
+		role {
+			name
+		}
+		assignedAt
+	}
+}
+`
+
+func TestValidateSystemRoles(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	var opts []database.CreatePermissionOpts
+	for _, p := range rbac.RBACSchema.Permissions() {
+		opts = append(opts, database.CreatePermissionOpts{Namespace: p.Namespace, Action: p.Action})
+	}
+	// A permission that no system role is expected to grant.
+	opts = append(opts, database.CreatePermissionOpts{Namespace: types.BatchChangesNamespace, Action: "PUBLISH"})
+	ps, err := db.Permissions().BulkCreate(ctx, opts)
+	assert.NoError(t, err)
+	expected, unexpected := ps[:len(ps)-1], ps[len(ps)-1]
+
+	// The USER role is missing the first expected permission, and the ADMIN
+	// role grants an unexpected one.
+	for i, p := range expected {
+		roles := rbac.DefaultSystemRoles
+		if i == 0 {
+			roles = []types.SystemRole{types.SiteAdministratorSystemRole}
+		}
+		_, err := db.RolePermissions().BulkAssignPermissionsToSystemRoles(ctx, database.BulkAssignPermissionsToSystemRolesOpts{
+			Roles:        roles,
+			PermissionID: p.ID,
+		})
+		assert.NoError(t, err)
+	}
+	adminRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.AdminSystemRole)})
+	assert.NoError(t, err)
+	_, err = db.RolePermissions().Assign(ctx, database.AssignRolePermissionOpts{RoleID: adminRole.ID, PermissionID: unexpected.ID})
+	assert.NoError(t, err)
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ ValidateSystemRoles []apitest.SystemRoleValidation }
+		errs := apitest.Exec(actorCtx, t, s, nil, &response, validateSystemRolesQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ ValidateSystemRoles []apitest.SystemRoleValidation }
+		apitest.MustExec(adminActorCtx, t, s, nil, &response, validateSystemRolesQuery)
+
+		want := []apitest.SystemRoleValidation{
+			{
+				Role:               apitest.Role{Name: string(types.UserSystemRole)},
+				MissingPermissions: []apitest.Permission{{DisplayName: expected[0].DisplayName()}},
+				ExtraPermissions:   []apitest.Permission{},
+			},
+			{
+				Role:               apitest.Role{Name: string(types.SiteAdministratorSystemRole)},
+				Valid:              true,
+				MissingPermissions: []apitest.Permission{},
+				ExtraPermissions:   []apitest.Permission{},
+			},
+			{
+				Role:               apitest.Role{Name: string(types.AdminSystemRole)},
+				MissingPermissions: []apitest.Permission{},
+				ExtraPermissions:   []apitest.Permission{{DisplayName: unexpected.DisplayName()}},
+			},
+		}
+		if diff := cmp.Diff(want, response.ValidateSystemRoles); diff != "" {
+			t.Fatalf("wrong validations (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const validateSystemRolesQuery = `
+query {
+	validateSystemRoles {
+		role {
+			name
+		}
+		valid
+		missingPermissions {
+			displayName
+		}
+		extraPermissions {
+			displayName
+		}
 	}
 }
 `
@@ -0,0 +1,65 @@
+package resolvers
+
+import (
+	"context"
+	"strings"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func (r *Resolver) RBACSearch(ctx context.Context, args *gql.RBACSearchArgs) (gql.RBACSearchResultResolver, error) {
+	// 🚨 SECURITY: Only site administrators can search roles and permissions.
+	if err := auth.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return nil, errors.New("query must not be empty")
+	}
+
+	roles, err := r.db.Roles().List(ctx, database.RolesListOptions{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+		Query:          query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.db.Permissions().List(ctx, database.PermissionListOpts{
+		PaginationArgs: &database.PaginationArgs{Ascending: true},
+		Query:          query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &rbacSearchResultResolver{
+		roles:       make([]gql.RoleResolver, 0, len(roles)),
+		permissions: make([]gql.PermissionResolver, 0, len(permissions)),
+	}
+	for _, role := range roles {
+		result.roles = append(result.roles, &roleResolver{role: role, db: r.db})
+	}
+	for _, permission := range permissions {
+		result.permissions = append(result.permissions, &permissionResolver{permission: permission})
+	}
+
+	return result, nil
+}
+
+type rbacSearchResultResolver struct {
+	roles       []gql.RoleResolver
+	permissions []gql.PermissionResolver
+}
+
+func (r *rbacSearchResultResolver) Roles() []gql.RoleResolver {
+	return r.roles
+}
+
+func (r *rbacSearchResultResolver) Permissions() []gql.PermissionResolver {
+	return r.permissions
+}
@@ -0,0 +1,95 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/log/logtest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/rbac/resolvers/apitest"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestRBACSearch(t *testing.T) {
+	logger := logtest.Scoped(t)
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := database.NewDB(logger, dbtest.NewDB(logger, t))
+
+	userID := createTestUser(t, db, false).ID
+	actorCtx := actor.WithActor(ctx, actor.FromUser(userID))
+
+	adminUserID := createTestUser(t, db, true).ID
+	adminActorCtx := actor.WithActor(ctx, actor.FromUser(adminUserID))
+
+	r := &Resolver{logger: logger, db: db}
+	s, err := newSchema(db, r)
+	assert.NoError(t, err)
+
+	publisherRole, err := db.Roles().Create(ctx, "PUBLISHER", false)
+	assert.NoError(t, err)
+	_, err = db.Roles().Create(ctx, "VIEWER", false)
+	assert.NoError(t, err)
+
+	ps, err := db.Permissions().BulkCreate(ctx, []database.CreatePermissionOpts{
+		{Namespace: types.BatchChangesNamespace, Action: "PUBLISH"},
+		{Namespace: types.BatchChangesNamespace, Action: "READ"},
+	})
+	assert.NoError(t, err)
+	publishPermission := ps[0]
+
+	input := map[string]any{"query": "publish"}
+
+	t.Run("as non site-admin", func(t *testing.T) {
+		var response struct{ RBACSearch apitest.RBACSearchResult }
+		errs := apitest.Exec(actorCtx, t, s, input, &response, rbacSearchQuery)
+
+		if len(errs) != 1 {
+			t.Fatalf("expected a single error, but got %d", len(errs))
+		}
+		if have, want := errs[0].Message, "must be site admin"; have != want {
+			t.Fatalf("wrong error. want=%q, have=%q", want, have)
+		}
+	})
+
+	t.Run("as site-admin", func(t *testing.T) {
+		var response struct{ RBACSearch apitest.RBACSearchResult }
+		apitest.MustExec(adminActorCtx, t, s, input, &response, rbacSearchQuery)
+
+		// The role matches by name and the permission matches by action.
+		want := apitest.RBACSearchResult{
+			Roles: []apitest.Role{
+				{ID: string(marshalRoleID(publisherRole.ID)), Name: "PUBLISHER"},
+			},
+			Permissions: []apitest.Permission{
+				{ID: string(marshalPermissionID(publishPermission.ID)), Action: "PUBLISH"},
+			},
+		}
+		if diff := cmp.Diff(want, response.RBACSearch); diff != "" {
+			t.Fatalf("wrong result (-want +got):\n%s", diff)
+		}
+	})
+}
+
+const rbacSearchQuery = `
+query RBACSearch($query: String!) {
+	rbacSearch(query: $query) {
+		roles {
+			id
+			name
+		}
+		permissions {
+			id
+			action
+		}
+	}
+}
+`
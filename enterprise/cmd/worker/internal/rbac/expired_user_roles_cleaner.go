@@ -0,0 +1,89 @@
+package rbac
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/cmd/worker/job"
+	workerdb "github.com/sourcegraph/sourcegraph/cmd/worker/shared/init/db"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/rbac"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+var _ job.Job = (*expiredUserRolesCleaner)(nil)
+
+// expiredUserRolesCleaner is a worker responsible for removing role
+// assignments that have expired.
+type expiredUserRolesCleaner struct{}
+
+func NewExpiredUserRolesCleaner() job.Job {
+	return &expiredUserRolesCleaner{}
+}
+
+func (j *expiredUserRolesCleaner) Description() string {
+	return "Removes expired role assignments."
+}
+
+func (j *expiredUserRolesCleaner) Config() []env.Config {
+	return nil
+}
+
+func (j *expiredUserRolesCleaner) Routines(_ context.Context, observationCtx *observation.Context) ([]goroutine.BackgroundRoutine, error) {
+	db, err := workerdb.InitDB(observationCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "init DB")
+	}
+
+	return []goroutine.BackgroundRoutine{
+		goroutine.NewPeriodicGoroutine(
+			context.Background(),
+			"rbac.expired-user-roles-cleaner",
+			"deletes expired role assignments",
+			time.Minute,
+			newExpiredUserRolesCleanHandler(observationCtx.Logger, db),
+		),
+	}, nil
+}
+
+func newExpiredUserRolesCleanHandler(logger log.Logger, db database.DB) goroutine.Handler {
+	// Expired assignments are already ignored when evaluating permissions, so
+	// this only keeps the table from accumulating stale rows. The removals are
+	// still recorded so that they show up in the RBAC audit trail.
+	return goroutine.HandlerFunc(func(ctx context.Context) error {
+		deleted, err := db.UserRoles().DeleteExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
+
+		adminRole, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: string(types.AdminSystemRole)})
+		if err != nil {
+			return errors.Wrap(err, "getting the ADMIN role")
+		}
+		for _, ur := range deleted {
+			rbac.LogDestructiveEvent(ctx, logger, db, database.SecurityEventNameRBACUserRoleRevoked, map[string]any{"userID": ur.UserID, "roleID": ur.RoleID})
+			// 🚨 SECURITY: Temporary admin access is audited like its assignment.
+			if ur.RoleID == adminRole.ID {
+				audit.Log(ctx, logger, audit.Record{
+					Entity: "user roles",
+					Action: "temporary ADMIN role expired",
+					Fields: []log.Field{
+						log.Int32("userID", ur.UserID),
+						log.Time("expiresAt", ur.ExpiresAt),
+					},
+				})
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	mockassert "github.com/derision-test/go-mockgen/testutil/assert"
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestExpiredUserRolesCleanHandler(t *testing.T) {
+	t.Run("nothing expired", func(t *testing.T) {
+		userRoles := database.NewMockUserRoleStore()
+		roles := database.NewMockRoleStore()
+
+		db := database.NewMockDB()
+		db.UserRolesFunc.SetDefaultReturn(userRoles)
+		db.RolesFunc.SetDefaultReturn(roles)
+
+		if err := newExpiredUserRolesCleanHandler(logtest.Scoped(t), db).Handle(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		mockassert.CalledOnce(t, userRoles.DeleteExpiredFunc)
+		mockassert.NotCalled(t, roles.GetFunc)
+	})
+
+	t.Run("expired assignments are recorded", func(t *testing.T) {
+		userRoles := database.NewMockUserRoleStore()
+		userRoles.DeleteExpiredFunc.SetDefaultReturn([]*types.UserRole{
+			{UserID: 1, RoleID: 2},
+			// An expired temporary admin assignment.
+			{UserID: 3, RoleID: 3},
+		}, nil)
+		roles := database.NewMockRoleStore()
+		roles.GetFunc.SetDefaultReturn(&types.Role{ID: 3, Name: string(types.AdminSystemRole), System: true}, nil)
+		securityEventLogs := database.NewMockSecurityEventLogsStore()
+
+		db := database.NewMockDB()
+		db.UserRolesFunc.SetDefaultReturn(userRoles)
+		db.RolesFunc.SetDefaultReturn(roles)
+		db.SecurityEventLogsFunc.SetDefaultReturn(securityEventLogs)
+
+		if err := newExpiredUserRolesCleanHandler(logtest.Scoped(t), db).Handle(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		mockassert.CalledOnce(t, userRoles.DeleteExpiredFunc)
+		mockassert.CalledOnce(t, roles.GetFunc)
+		mockassert.CalledN(t, securityEventLogs.LogEventFunc, 2)
+		for i, call := range securityEventLogs.LogEventFunc.History() {
+			event := call.Arg1
+			if event.Name != database.SecurityEventNameRBACUserRoleRevoked {
+				t.Errorf("event %d: unexpected name %q", i, event.Name)
+			}
+			if event.AnonymousUserID != "backend" {
+				t.Errorf("event %d: expected the backend to be recorded as the actor, got %q", i, event.AnonymousUserID)
+			}
+		}
+	})
+}
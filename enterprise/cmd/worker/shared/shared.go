@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/worker/internal/executors"
 	workerinsights "github.com/sourcegraph/sourcegraph/enterprise/cmd/worker/internal/insights"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/worker/internal/permissions"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/worker/internal/rbac"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/worker/internal/telemetry"
 	eiauthz "github.com/sourcegraph/sourcegraph/enterprise/internal/authz"
 	srp "github.com/sourcegraph/sourcegraph/enterprise/internal/authz/subrepoperms"
@@ -60,6 +61,8 @@ var additionalJobs = map[string]job.Job{
 	"auth-permission-sync-job-cleaner":   auth.NewPermissionSyncJobCleaner(),
 	"auth-permission-sync-job-scheduler": auth.NewPermissionSyncJobScheduler(),
 
+	"rbac-expired-user-roles-cleaner": rbac.NewExpiredUserRolesCleaner(),
+
 	// Note: experimental (not documented)
 	"codeintel-ranking-sourcer": codeintel.NewRankingSourcerJob(),
 }
@@ -0,0 +1,45 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+)
+
+// afterIDFromRequest returns the ID of the user after which the users listed in response to the given request start,
+// based on the "afterId" query parameter. It returns 0 if the parameter isn't set.
+//
+// Clients fetching sequential pages set "afterId" to the "id" of the last resource of the previous page. The page
+// then continues after that user (keyset pagination) instead of at "startIndex", so no state is kept between requests
+// and users created or deleted between two page fetches don't cause results to be skipped or returned twice. The
+// resource may have been deleted in the meantime, so soft-deleted users are looked up too.
+// The SCIM library doesn't know about this parameter, so it is read from the request directly.
+func (h *UserResourceHandler) afterIDFromRequest(r *http.Request) (int32, *scimerrors.ScimError) {
+	if r.URL == nil {
+		return 0, nil
+	}
+	afterID := r.URL.Query().Get("afterId")
+	if afterID == "" {
+		return 0, nil
+	}
+
+	if id, err := strconv.ParseInt(afterID, 10, 32); err == nil {
+		return int32(id), nil
+	}
+	users, err := h.db.Users().ListForSCIM(r.Context(), &database.UsersListOptions{SCIMResourceID: afterID, IncludeDeleted: true})
+	if err != nil {
+		return 0, &scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	if len(users) == 0 {
+		return 0, &scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeInvalidValue,
+			Detail:   fmt.Sprintf("The user %q to continue after doesn't exist.", afterID),
+			Status:   http.StatusBadRequest,
+		}
+	}
+	return users[0].ID, nil
+}
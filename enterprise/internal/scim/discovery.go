@@ -0,0 +1,102 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/optional"
+)
+
+// maxResults is the maximum number of resources returned in one list response. Clients requesting more get at most
+// this many, see Section 3.4.2.4 of RFC 7644.
+const maxResults = 100
+
+// sortSupported is whether list responses can be sorted with the "sortBy" and "sortOrder" parameters.
+const sortSupported = true
+
+// newServiceProviderConfig returns the configuration of the SCIM server, which is also used to build the
+// /ServiceProviderConfig document, so that the advertised features are the ones the server implements.
+func newServiceProviderConfig() scim.ServiceProviderConfig {
+	return scim.ServiceProviderConfig{
+		DocumentationURI: optional.NewString("docs.sourcegraph.com/admin/scim"),
+		MaxResults:       maxResults,
+		SupportFiltering: true,
+		SupportPatch:     true,
+		AuthenticationSchemes: []scim.AuthenticationScheme{
+			{
+				Type:             scim.AuthenticationTypeOauthBearerToken,
+				Name:             "OAuth Bearer Token",
+				Description:      "Authentication scheme using the Bearer Token standard – use the key 'scim.authToken' in the site config to set the token.",
+				SpecURI:          optional.NewString("https://tools.ietf.org/html/rfc6750"),
+				DocumentationURI: optional.NewString("docs.sourcegraph.com/admin/scim"),
+				Primary:          true,
+			},
+		},
+	}
+}
+
+// isServiceProviderConfigRequest returns true if the request is for the /ServiceProviderConfig document.
+func isServiceProviderConfigRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.TrimPrefix(r.URL.Path, "/v2") == "/ServiceProviderConfig"
+}
+
+// serveServiceProviderConfig writes the /ServiceProviderConfig document, see Section 5 of RFC 7643.
+// The SCIM library has its own handler for it, but that one always advertises sorting as unsupported and doesn't
+// describe pagination.
+func serveServiceProviderConfig(w http.ResponseWriter, config scim.ServiceProviderConfig) {
+	raw, err := json.Marshal(serviceProviderConfigDocument(config))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	_, _ = w.Write(raw)
+}
+
+// serviceProviderConfigDocument returns the /ServiceProviderConfig document for the given configuration.
+func serviceProviderConfigDocument(config scim.ServiceProviderConfig) map[string]interface{} {
+	authenticationSchemes := make([]map[string]interface{}, 0, len(config.AuthenticationSchemes))
+	for _, scheme := range config.AuthenticationSchemes {
+		authenticationSchemes = append(authenticationSchemes, map[string]interface{}{
+			"type":             scheme.Type,
+			"name":             scheme.Name,
+			"description":      scheme.Description,
+			"specUri":          scheme.SpecURI.Value(),
+			"documentationUri": scheme.DocumentationURI.Value(),
+			"primary":          scheme.Primary,
+		})
+	}
+
+	return map[string]interface{}{
+		"schemas":          []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"documentationUri": config.DocumentationURI.Value(),
+		"patch":            map[string]interface{}{"supported": config.SupportPatch},
+		"bulk": map[string]interface{}{
+			"supported":      false,
+			"maxOperations":  0,
+			"maxPayloadSize": 0,
+		},
+		"filter": map[string]interface{}{
+			"supported":  config.SupportFiltering,
+			"maxResults": config.MaxResults,
+		},
+		"changePassword": map[string]interface{}{"supported": false},
+		"sort":           map[string]interface{}{"supported": sortSupported},
+		"etag":           map[string]interface{}{"supported": false},
+		// Lists are paginated with the "startIndex" and "count" parameters. The "afterId" parameter isn't a cursor
+		// in the sense of RFC 9865, as no "nextCursor" is returned, see afterIDFromRequest.
+		"pagination": map[string]interface{}{
+			"cursor":                  false,
+			"index":                   true,
+			"defaultPaginationMethod": "index",
+			"defaultPageSize":         config.MaxResults,
+			"maxPageSize":             config.MaxResults,
+		},
+		"authenticationSchemes": authenticationSchemes,
+		"meta": map[string]interface{}{
+			"resourceType": "ServiceProviderConfig",
+		},
+	}
+}
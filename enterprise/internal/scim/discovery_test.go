@@ -0,0 +1,132 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestServiceProviderConfig(t *testing.T) {
+	handler := newTestHandler(t, database.NewMockDB())
+
+	var config struct {
+		Patch  struct{ Supported bool }
+		Bulk   struct{ Supported bool }
+		Sort   struct{ Supported bool }
+		Filter struct {
+			Supported  bool
+			MaxResults int
+		}
+		Pagination struct {
+			Index       bool
+			MaxPageSize int
+		}
+	}
+	serveJSON(t, handler, "/.api/scim/v2/ServiceProviderConfig", &config)
+
+	assert.True(t, config.Patch.Supported)
+	assert.False(t, config.Bulk.Supported)
+	assert.Equal(t, sortSupported, config.Sort.Supported)
+	assert.True(t, config.Filter.Supported)
+	assert.Equal(t, maxResults, config.Filter.MaxResults)
+	assert.True(t, config.Pagination.Index)
+	assert.Equal(t, maxResults, config.Pagination.MaxPageSize)
+}
+
+func TestServiceProviderConfig_MaxResultsIsEnforced(t *testing.T) {
+	// More users than fit in one response.
+	var users []*types.UserForSCIM
+	for i := 1; i <= 2*maxResults; i++ {
+		users = append(users, &types.UserForSCIM{User: types.User{ID: int32(i), Username: fmt.Sprintf("user%d", i)}})
+	}
+	userStore := database.NewMockUserStore()
+	userStore.ListForSCIMFunc.SetDefaultHook(func(ctx context.Context, opt *database.UsersListOptions) ([]*types.UserForSCIM, error) {
+		return applyLimitOffset(users, opt.LimitOffset)
+	})
+	userStore.CountFunc.SetDefaultReturn(len(users), nil)
+	db := database.NewMockDB()
+	db.UsersFunc.SetDefaultReturn(userStore)
+	handler := newTestHandler(t, db)
+
+	var config struct {
+		Filter struct{ MaxResults int }
+	}
+	serveJSON(t, handler, "/.api/scim/v2/ServiceProviderConfig", &config)
+
+	for _, query := range []string{
+		"count=1000",
+		"count=1000&filter=" + url.QueryEscape(`userName sw "user"`),
+	} {
+		t.Run(query, func(t *testing.T) {
+			var page struct {
+				TotalResults int
+				ItemsPerPage int
+				Resources    []interface{}
+			}
+			serveJSON(t, handler, "/.api/scim/v2/Users?"+query, &page)
+
+			assert.Equal(t, len(users), page.TotalResults)
+			assert.Equal(t, config.Filter.MaxResults, page.ItemsPerPage)
+			assert.Len(t, page.Resources, config.Filter.MaxResults)
+		})
+	}
+}
+
+func TestResourceTypes(t *testing.T) {
+	handler := newTestHandler(t, database.NewMockDB())
+
+	var page struct {
+		Resources []struct {
+			ID               string
+			Endpoint         string
+			Schema           string
+			SchemaExtensions []struct{ Schema string }
+		}
+	}
+	serveJSON(t, handler, "/.api/scim/v2/ResourceTypes", &page)
+
+	require.Len(t, page.Resources, 2)
+	user, group := page.Resources[0], page.Resources[1]
+	assert.Equal(t, "User", user.ID)
+	assert.Equal(t, "/Users", user.Endpoint)
+	assert.Equal(t, "urn:ietf:params:scim:schemas:core:2.0:User", user.Schema)
+	if assert.Len(t, user.SchemaExtensions, 1) {
+		assert.Equal(t, enterpriseUserSchemaURN, user.SchemaExtensions[0].Schema)
+	}
+	assert.Equal(t, "Group", group.ID)
+	assert.Equal(t, "/Groups", group.Endpoint)
+	assert.Equal(t, "urn:ietf:params:scim:schemas:core:2.0:Group", group.Schema)
+}
+
+// newTestHandler returns the SCIM handler for the given DB, with an auth token configured.
+func newTestHandler(t *testing.T, db database.DB) http.Handler {
+	t.Helper()
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimAuthToken: "token"}})
+	t.Cleanup(func() { conf.Mock(nil) })
+	return NewHandler(context.Background(), db, &observation.TestContext)
+}
+
+// serveJSON sends an authenticated GET request for the given path to the handler, and decodes the response into v.
+func serveJSON(t *testing.T, handler http.Handler, path string, v any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+}
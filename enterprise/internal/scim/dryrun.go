@@ -0,0 +1,28 @@
+package scim
+
+import (
+	"net/http"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// isDryRun returns whether requests only log the changes to users they would make instead of making them, see
+// scim.dryRun.
+func isDryRun() bool {
+	return conf.Get().ScimDryRun
+}
+
+// logDryRun logs a change that a request would have made if scim.dryRun wasn't enabled.
+func logDryRun(logger log.Logger, change string, fields ...log.Field) {
+	logger.Info("SCIM dry run: "+change, fields...)
+}
+
+// dryRunGroupsError is returned for requests that change groups while scim.dryRun is enabled, which only previews
+// changes to users.
+var dryRunGroupsError = scimerrors.ScimError{
+	Status: http.StatusNotImplemented,
+	Detail: "Groups can't be changed while scim.dryRun is enabled.",
+}
@@ -0,0 +1,159 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	mockassert "github.com/derision-test/go-mockgen/testutil/assert"
+	"github.com/elimity-com/scim"
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestDryRun(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimDryRun: true}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	assertNoUserChanges := func(t *testing.T, db *database.MockDB) {
+		t.Helper()
+		users := db.Users().(*database.MockUserStore)
+		mockassert.NotCalled(t, users.CreateFunc)
+		mockassert.NotCalled(t, users.UpdateFunc)
+		mockassert.NotCalled(t, users.DeleteFunc)
+		mockassert.NotCalled(t, users.HardDeleteFunc)
+		mockassert.NotCalled(t, users.InvalidateSessionsByIDFunc)
+		mockassert.NotCalled(t, users.SetSCIMEnterpriseAttributesFunc)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		attributes := scim.ResourceAttributes{
+			"userName":   "user5",
+			"externalId": "external5",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "e@example.com", "primary": true},
+			},
+		}
+		user, err := userResourceHandler.Create(&http.Request{}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The resource stands in for a user that isn't created.
+		_, err = uuid.Parse(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, attributes, user.Attributes)
+		assert.Equal(t, "external5", user.ExternalID.Value())
+		assertNoUserChanges(t, db)
+	})
+
+	t.Run("create with a taken username", func(t *testing.T) {
+		db := getMockDB()
+		db.Namespaces().(*database.MockNamespaceStore).GetByNameFunc.SetDefaultReturn(&database.Namespace{Name: "user1", User: 1}, nil)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+			"userName": "user1",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "e@example.com", "primary": true},
+			},
+		})
+
+		// The request fails like it would without a dry run.
+		var scimErr scimerrors.ScimError
+		if assert.True(t, errors.As(err, &scimErr)) {
+			assert.Equal(t, http.StatusConflict, scimErr.Status)
+		}
+		assertNoUserChanges(t, db)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		db := getMockDB()
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+		userEmails := database.NewMockUserEmailsStore()
+		db.UserEmailsFunc.SetDefaultReturn(userEmails)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		user, err := userResourceHandler.Replace(&http.Request{}, "1", scim.ResourceAttributes{
+			"userName":    "renamed",
+			"displayName": "New Name",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "new@example.com", "primary": true},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "1", user.ID)
+		assert.Equal(t, "renamed", user.Attributes["userName"])
+		assertNoUserChanges(t, db)
+		mockassert.NotCalled(t, userEmails.AddFunc)
+		mockassert.NotCalled(t, userEmails.SetPrimaryEmailFunc)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Value: map[string]interface{}{"displayName": "New Name"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "1", user.ID)
+		assert.Equal(t, "New Name", user.Attributes["displayName"])
+		assertNoUserChanges(t, db)
+
+		// The user is unchanged.
+		stored, err := userResourceHandler.Get(&http.Request{}, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "First Last", stored.Attributes["displayName"])
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		if err := userResourceHandler.Delete(&http.Request{}, "1"); err != nil {
+			t.Fatal(err)
+		}
+		assertNoUserChanges(t, db)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).DeleteFunc)
+
+		// Deleting an unknown user still fails.
+		err := userResourceHandler.Delete(&http.Request{}, "100")
+		var scimErr scimerrors.ScimError
+		if assert.True(t, errors.As(err, &scimErr)) {
+			assert.Equal(t, http.StatusNotFound, scimErr.Status)
+		}
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		db := getMockDBWithOrgs()
+		groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := groupResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{"displayName": "marketing"})
+		assert.Equal(t, dryRunGroupsError, err)
+		_, err = groupResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationAdd, Value: map[string]interface{}{"members": []interface{}{}}},
+		})
+		assert.Equal(t, dryRunGroupsError, err)
+		mockassert.NotCalled(t, db.OrgsFunc)
+	})
+}
@@ -231,7 +231,9 @@ func (v Validator) PassesFilter(resource map[string]interface{}) error {
 
 		// If the attribute has a non-empty or non-null value or if it contains a non-empty node for complex attributes, there is a match.
 		if e.Operator == filter.PR {
-			// We already found a value.
+			if isEmptyValue(value) {
+				return errors.Newf("the resource does not have a value for the attribute specified in the filter")
+			}
 			return nil
 		}
 
@@ -334,3 +336,25 @@ func (v Validator) referenceContains(attrPath filter.AttributePath) (schema.Sche
 	}
 	return schema.Schema{}, schema.CoreAttribute{}, false
 }
+
+// isEmptyValue returns true if the given attribute value is null, an empty string, or a complex or multi-valued
+// attribute without any values, i.e. if it doesn't match the "pr" (present) operator.
+func isEmptyValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		for _, v := range value {
+			if !isEmptyValue(v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
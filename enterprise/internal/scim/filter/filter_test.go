@@ -91,6 +91,41 @@ func TestValidator_PassesFilter(t *testing.T) {
 					},
 				},
 			},
+			{
+				filter: `displayName pr`,
+				valid: map[string]interface{}{
+					"displayName": "John Doe",
+				},
+				invalid: map[string]interface{}{
+					"displayName": "",
+				},
+			},
+			{
+				filter: `emails pr`,
+				valid: map[string]interface{}{
+					"emails": []interface{}{
+						map[string]interface{}{
+							"value": "john@example.com",
+						},
+					},
+				},
+				invalid: map[string]interface{}{
+					"emails": []interface{}{},
+				},
+			},
+			{
+				filter: `name pr`,
+				valid: map[string]interface{}{
+					"name": map[string]interface{}{
+						"givenName": "John",
+					},
+				},
+				invalid: map[string]interface{}{
+					"name": map[string]interface{}{
+						"givenName": "",
+					},
+				},
+			},
 		} {
 			validator, err := NewValidator(test.filter, schema.CoreUserSchema())
 			if err != nil {
@@ -0,0 +1,489 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elimity-com/scim"
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/elimity-com/scim/optional"
+	"github.com/elimity-com/scim/schema"
+	scimfilter "github.com/scim2/filter-parser/v2"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/scim/filter"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// GroupResourceHandler implements the scim.ResourceHandler interface for groups.
+// Groups are mapped to organizations: the display name of a group is the name of the organization, and its members
+// are the members of the organization.
+type GroupResourceHandler struct {
+	ctx            context.Context
+	observationCtx *observation.Context
+	db             database.DB
+	coreSchema     schema.Schema
+}
+
+// NewGroupResourceHandler returns a new GroupResourceHandler.
+func NewGroupResourceHandler(ctx context.Context, observationCtx *observation.Context, db database.DB) *GroupResourceHandler {
+	return &GroupResourceHandler{
+		ctx:            ctx,
+		observationCtx: observationCtx,
+		db:             db,
+		coreSchema:     createGroupCoreSchema(),
+	}
+}
+
+// Create creates an organization named after the display name of the group, and adds the members of the group to it.
+func (h *GroupResourceHandler) Create(r *http.Request, attributes scim.ResourceAttributes) (scim.Resource, error) {
+	if isDryRun() {
+		return scim.Resource{}, dryRunGroupsError
+	}
+	name, _ := attributes["displayName"].(string)
+	if name == "" {
+		return scim.Resource{}, scimerrors.ScimErrorBadParams([]string{"displayName missing"})
+	}
+	if normalized, err := auth.NormalizeUsername(name); err != nil || normalized != name {
+		return scim.Resource{}, scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeInvalidValue,
+			Detail:   fmt.Sprintf("The group name %q is not a valid organization name.", name),
+			Status:   http.StatusBadRequest,
+		}
+	}
+
+	var org *types.Org
+	err := h.db.WithTransact(r.Context(), func(tx database.DB) (err error) {
+		// Organizations share their namespace with users.
+		if _, err := tx.Namespaces().GetByName(r.Context(), name); err != database.ErrNamespaceNotFound {
+			if err != nil {
+				return err
+			}
+			return groupNameTakenError(name)
+		}
+
+		org, err = tx.Orgs().Create(r.Context(), name, &name)
+		if err != nil {
+			return err
+		}
+		return addGroupMembers(r.Context(), tx, org.ID, map[int32]struct{}{}, attributes["members"])
+	})
+	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) {
+			return scim.Resource{}, scimErr
+		}
+		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+
+	return h.Get(r, strconv.FormatInt(int64(org.ID), 10))
+}
+
+func groupNameTakenError(name string) scimerrors.ScimError {
+	return scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeUniqueness,
+		Status:   http.StatusConflict,
+		Detail:   fmt.Sprintf("The name %q is already in use.", name),
+	}
+}
+
+// Get returns the resource corresponding with the given identifier.
+func (h *GroupResourceHandler) Get(r *http.Request, idStr string) (scim.Resource, error) {
+	org, err := h.getOrg(r.Context(), idStr)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	resource, err := h.convertOrgToSCIMResource(r.Context(), org)
+	if err != nil {
+		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	return resource, nil
+}
+
+// getOrg returns the organization with the given SCIM resource ID, which is the ID of the organization.
+func (h *GroupResourceHandler) getOrg(ctx context.Context, idStr string) (*types.Org, error) {
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		return nil, scimerrors.ScimErrorResourceNotFound(idStr)
+	}
+	org, err := h.db.Orgs().GetByID(ctx, int32(id))
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return nil, scimerrors.ScimErrorResourceNotFound(idStr)
+		}
+		return nil, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	return org, nil
+}
+
+// GetAll returns a paginated list of resources.
+// An empty list of resources will be represented as `null` in the JSON response if `nil` is assigned to the
+// Page.Resources. Otherwise, if an empty slice is assigned, an empty list will be represented as `[]`.
+func (h *GroupResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
+	var totalCount int
+	var orgs []*types.Org
+	var err error
+
+	if params.Filter == nil {
+		var offset int
+		if params.StartIndex > 0 {
+			offset = params.StartIndex - 1
+		}
+		orgs, err = h.db.Orgs().List(r.Context(), &database.OrgsListOptions{
+			LimitOffset: &database.LimitOffset{Limit: params.Count, Offset: offset},
+		})
+		if err == nil {
+			totalCount, err = h.db.Orgs().Count(r.Context(), database.OrgsListOptions{})
+		}
+	} else {
+		if scimErr := checkFilterAttributes(params.Filter, h.coreSchema.ID, filterableGroupAttributes); scimErr != nil {
+			return scim.Page{}, *scimErr
+		}
+		validator := filter.NewFilterValidator(params.Filter, h.coreSchema)
+
+		// Like for users, fetch all organizations from the DB and then filter them here. Only the display name can
+		// be filtered by, so the members are only fetched for the organizations on the requested page.
+		var allOrgs []*types.Org
+		allOrgs, err = h.db.Orgs().List(r.Context(), &database.OrgsListOptions{})
+		for _, org := range allOrgs {
+			if err := validator.PassesFilter(map[string]interface{}{"displayName": org.Name}); err != nil {
+				continue
+			}
+
+			// Every match counts towards the total, not just the ones on the requested page.
+			totalCount++
+			if totalCount < params.StartIndex {
+				continue
+			}
+			if len(orgs) < params.Count {
+				orgs = append(orgs, org)
+			}
+		}
+	}
+	if err != nil {
+		return scim.Page{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+
+	resources := make([]scim.Resource, 0, len(orgs))
+	for _, org := range orgs {
+		resource, err := h.convertOrgToSCIMResource(r.Context(), org)
+		if err != nil {
+			return scim.Page{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+		resources = append(resources, resource)
+	}
+
+	return scim.Page{
+		TotalResults: totalCount,
+		Resources:    resources,
+	}, nil
+}
+
+// filterableGroupAttributes are the (lowercase) names of the group attributes that filters can be applied to.
+var filterableGroupAttributes = map[string]struct{}{
+	"displayname": {},
+}
+
+// convertOrgToSCIMResource converts a Sourcegraph organization to a SCIM resource.
+func (h *GroupResourceHandler) convertOrgToSCIMResource(ctx context.Context, org *types.Org) (scim.Resource, error) {
+	memberships, err := h.db.OrgMembers().GetByOrgID(ctx, org.ID)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	members := make([]interface{}, 0, len(memberships))
+	if len(memberships) > 0 {
+		userIDs := make([]int32, 0, len(memberships))
+		for _, membership := range memberships {
+			userIDs = append(userIDs, membership.UserID)
+		}
+		users, err := h.db.Users().ListForSCIM(ctx, &database.UsersListOptions{UserIDs: userIDs})
+		if err != nil {
+			return scim.Resource{}, err
+		}
+		for _, user := range users {
+			members = append(members, map[string]interface{}{
+				"value":   userResourceID(user),
+				"display": user.Username,
+			})
+		}
+	}
+
+	return scim.Resource{
+		ID: strconv.FormatInt(int64(org.ID), 10),
+		Attributes: scim.ResourceAttributes{
+			"displayName": org.Name,
+			"members":     members,
+		},
+	}, nil
+}
+
+// Replace is not supported for groups, identity providers can use Patch to update the members of a group instead.
+func (h *GroupResourceHandler) Replace(_ *http.Request, _ string, _ scim.ResourceAttributes) (scim.Resource, error) {
+	return scim.Resource{}, scimerrors.ScimError{
+		Status: http.StatusNotImplemented,
+		Detail: "Replacing groups is not supported, use PATCH to update their members.",
+	}
+}
+
+// Delete is not supported for groups, organizations have to be deleted by a site admin.
+func (h *GroupResourceHandler) Delete(_ *http.Request, _ string) error {
+	return scimerrors.ScimError{
+		Status: http.StatusNotImplemented,
+		Detail: "Deleting groups is not supported.",
+	}
+}
+
+// Patch update one or more attributes of a SCIM resource using a sequence of
+// operations to "add", "remove", or "replace" values.
+// Only the members of a group can be changed, which adds users to or removes them from the organization.
+func (h *GroupResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
+	if isDryRun() {
+		return scim.Resource{}, dryRunGroupsError
+	}
+	if err := checkPatchOperationCount(len(operations)); err != nil {
+		return scim.Resource{}, err
+	}
+
+	org, err := h.getOrg(r.Context(), id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	err = h.db.WithTransact(r.Context(), func(tx database.DB) error {
+		memberships, err := tx.OrgMembers().GetByOrgID(r.Context(), org.ID)
+		if err != nil {
+			return err
+		}
+		members := make(map[int32]struct{}, len(memberships))
+		for _, membership := range memberships {
+			members[membership.UserID] = struct{}{}
+		}
+
+		for _, operation := range operations {
+			if operation.Path != nil {
+				if err := applyGroupPatchOperation(r.Context(), tx, org, members, operation.Op, *operation.Path, operation.Value); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Without a path, the value maps attribute paths to their new values.
+			values, ok := operation.Value.(map[string]interface{})
+			if !ok || strings.EqualFold(operation.Op, scim.PatchOperationRemove) {
+				return scimerrors.ScimErrorInvalidValue
+			}
+			for rawPath, value := range values {
+				path, err := scimfilter.ParsePath([]byte(rawPath))
+				if err != nil {
+					return scimerrors.ScimErrorInvalidPath
+				}
+				if err := applyGroupPatchOperation(r.Context(), tx, org, members, operation.Op, path, value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) {
+			return scim.Resource{}, scimErr
+		}
+		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+
+	return h.Get(r, id)
+}
+
+// applyGroupPatchOperation applies a single PATCH operation to the given organization. members is the set of IDs of
+// the users in the organization, and is kept up to date.
+func applyGroupPatchOperation(ctx context.Context, db database.DB, org *types.Org, members map[int32]struct{}, op string, path scimfilter.Path, value interface{}) error {
+	switch strings.ToLower(path.AttributePath.AttributeName) {
+	case "members":
+		if path.AttributePath.SubAttribute != nil || path.SubAttribute != nil {
+			return unsupportedPatchPathError(path)
+		}
+		switch strings.ToLower(op) {
+		case scim.PatchOperationAdd:
+			return addGroupMembers(ctx, db, org.ID, members, value)
+		case scim.PatchOperationRemove:
+			// e.g. members[value eq "2"]
+			if path.ValueExpression != nil {
+				memberID, ok := memberIDFromFilter(path.ValueExpression)
+				if !ok {
+					return unsupportedPatchPathError(path)
+				}
+				return removeGroupMembers(ctx, db, org.ID, members, []interface{}{map[string]interface{}{"value": memberID}})
+			}
+			// Without a value, all members are removed.
+			return removeGroupMembers(ctx, db, org.ID, members, value)
+		case scim.PatchOperationReplace:
+			if path.ValueExpression != nil {
+				return unsupportedPatchPathError(path)
+			}
+			if err := removeGroupMembers(ctx, db, org.ID, members, nil); err != nil {
+				return err
+			}
+			return addGroupMembers(ctx, db, org.ID, members, value)
+		}
+		return scimerrors.ScimErrorInvalidValue
+	case "displayname":
+		// Organizations can't be renamed, so only allow setting the name they already have.
+		if name, ok := value.(string); ok && name == org.Name && !strings.EqualFold(op, scim.PatchOperationRemove) {
+			return nil
+		}
+		return scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeMutability,
+			Detail:   "The displayName of a group can't be changed.",
+			Status:   http.StatusBadRequest,
+		}
+	}
+	return unsupportedPatchPathError(path)
+}
+
+// memberIDFromFilter returns the member ID a filter like `value eq "2"` selects.
+func memberIDFromFilter(expr scimfilter.Expression) (string, bool) {
+	e, ok := expr.(*scimfilter.AttributeExpression)
+	if !ok || e.Operator != scimfilter.EQ || !strings.EqualFold(e.AttributePath.AttributeName, "value") {
+		return "", false
+	}
+	id, ok := e.CompareValue.(string)
+	return id, ok
+}
+
+// addGroupMembers adds the users referenced by the given list of SCIM members to the organization, unless they are
+// already in the given set of members.
+func addGroupMembers(ctx context.Context, db database.DB, orgID int32, members map[int32]struct{}, value interface{}) error {
+	userIDs, err := resolveGroupMembers(ctx, db, value, true)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if _, ok := members[userID]; ok {
+			continue
+		}
+		if _, err := db.OrgMembers().Create(ctx, orgID, userID); err != nil {
+			return err
+		}
+		members[userID] = struct{}{}
+	}
+	return nil
+}
+
+// removeGroupMembers removes the users referenced by the given list of SCIM members from the organization, or all
+// members if the list is nil. Users that aren't members are ignored.
+func removeGroupMembers(ctx context.Context, db database.DB, orgID int32, members map[int32]struct{}, value interface{}) error {
+	var userIDs []int32
+	if value == nil {
+		for userID := range members {
+			userIDs = append(userIDs, userID)
+		}
+	} else {
+		var err error
+		if userIDs, err = resolveGroupMembers(ctx, db, value, false); err != nil {
+			return err
+		}
+	}
+	for _, userID := range userIDs {
+		if _, ok := members[userID]; !ok {
+			continue
+		}
+		if err := db.OrgMembers().Remove(ctx, orgID, userID); err != nil {
+			return err
+		}
+		delete(members, userID)
+	}
+	return nil
+}
+
+// resolveGroupMembers returns the IDs of the users referenced by the given list of SCIM members, whose values are
+// user resource IDs. Unknown users are an error if mustExist is true, and are skipped otherwise.
+func resolveGroupMembers(ctx context.Context, db database.DB, value interface{}, mustExist bool) ([]int32, error) {
+	if value == nil {
+		return nil, nil
+	}
+	rawMembers, ok := value.([]interface{})
+	if !ok {
+		return nil, scimerrors.ScimErrorInvalidValue
+	}
+
+	userIDs := make([]int32, 0, len(rawMembers))
+	for _, rawMember := range rawMembers {
+		member, ok := rawMember.(map[string]interface{})
+		if !ok {
+			return nil, scimerrors.ScimErrorInvalidValue
+		}
+		resourceID, ok := member["value"].(string)
+		if !ok || resourceID == "" {
+			return nil, scimerrors.ScimErrorInvalidValue
+		}
+
+		users, err := db.Users().ListForSCIM(ctx, resourceIDListOptions(resourceID))
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			if !mustExist {
+				continue
+			}
+			return nil, scimerrors.ScimError{
+				ScimType: scimerrors.ScimTypeInvalidValue,
+				Detail:   fmt.Sprintf("No user with the ID %q exists.", resourceID),
+				Status:   http.StatusBadRequest,
+			}
+		}
+		userIDs = append(userIDs, users[0].ID)
+	}
+	return userIDs, nil
+}
+
+// createGroupResourceType creates a SCIM resource type for groups.
+func createGroupResourceType(groupResourceHandler *GroupResourceHandler) scim.ResourceType {
+	return scim.ResourceType{
+		ID:          optional.NewString("Group"),
+		Name:        "Group",
+		Endpoint:    "/Groups",
+		Description: optional.NewString("Group"),
+		Schema:      groupResourceHandler.coreSchema,
+		Handler:     groupResourceHandler,
+	}
+}
+
+// createGroupCoreSchema creates a SCIM core schema for groups.
+func createGroupCoreSchema() schema.Schema {
+	return schema.Schema{
+		ID:          "urn:ietf:params:scim:schemas:core:2.0:Group",
+		Name:        optional.NewString("Group"),
+		Description: optional.NewString("Group"),
+		Attributes: []schema.CoreAttribute{
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name:       "displayName",
+				Required:   true,
+				Uniqueness: schema.AttributeUniquenessServer(),
+			})),
+			schema.ComplexCoreAttribute(schema.ComplexParams{
+				Name:        "members",
+				MultiValued: true,
+				SubAttributes: []schema.SimpleParams{
+					schema.SimpleStringParams(schema.StringParams{
+						Name:       "value",
+						Mutability: schema.AttributeMutabilityImmutable(),
+					}),
+					schema.SimpleStringParams(schema.StringParams{
+						Name:       "display",
+						Mutability: schema.AttributeMutabilityReadOnly(),
+					}),
+				},
+			}),
+		},
+	}
+}
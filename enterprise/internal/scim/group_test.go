@@ -0,0 +1,363 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	mockassert "github.com/derision-test/go-mockgen/testutil/assert"
+	"github.com/elimity-com/scim"
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupResourceHandler_Create(t *testing.T) {
+	db := getMockDBWithOrgs()
+	groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, db)
+	group, err := groupResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+		"displayName": "marketing",
+		"members": []interface{}{
+			map[string]interface{}{"value": "1"},
+			map[string]interface{}{"value": "2"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "4", group.ID)
+	assert.Equal(t, "marketing", group.Attributes["displayName"])
+	assert.Equal(t, []string{"1", "2"}, memberValues(group))
+}
+
+func TestGroupResourceHandler_Create_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		attributes scim.ResourceAttributes
+		wantStatus int
+		wantType   scimerrors.ScimType
+	}{
+		{
+			name:       "invalid name",
+			attributes: scim.ResourceAttributes{"displayName": "Marketing Team"},
+			wantStatus: http.StatusBadRequest,
+			wantType:   scimerrors.ScimTypeInvalidValue,
+		},
+		{
+			name:       "name taken",
+			attributes: scim.ResourceAttributes{"displayName": "taken"},
+			wantStatus: http.StatusConflict,
+			wantType:   scimerrors.ScimTypeUniqueness,
+		},
+		{
+			name: "unknown member",
+			attributes: scim.ResourceAttributes{
+				"displayName": "marketing",
+				"members":     []interface{}{map[string]interface{}{"value": "404"}},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantType:   scimerrors.ScimTypeInvalidValue,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, getMockDBWithOrgs())
+			_, err := groupResourceHandler.Create(&http.Request{}, tc.attributes)
+
+			var scimErr scimerrors.ScimError
+			if !errors.As(err, &scimErr) {
+				t.Fatalf("expected a SCIM error, got %v", err)
+			}
+			assert.Equal(t, tc.wantStatus, scimErr.Status)
+			assert.Equal(t, tc.wantType, scimErr.ScimType)
+		})
+	}
+}
+
+func TestGroupResourceHandler_Get(t *testing.T) {
+	groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, getMockDBWithOrgs())
+
+	group, err := groupResourceHandler.Get(&http.Request{}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "engineering", group.Attributes["displayName"])
+	assert.Equal(t, []string{"1", "2"}, memberValues(group))
+
+	for _, id := range []string{"404", "not-an-id"} {
+		_, err := groupResourceHandler.Get(&http.Request{}, id)
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) || scimErr.Status != http.StatusNotFound {
+			t.Errorf("%s: expected a not found error, got %v", id, err)
+		}
+	}
+}
+
+func TestGroupResourceHandler_GetAll(t *testing.T) {
+	db := getMockDBWithOrgs()
+
+	cases := []struct {
+		name             string
+		count            int
+		startIndex       int
+		filter           string
+		wantTotalResults int
+		wantResults      int
+		wantFirstID      int
+	}{
+		{name: "no filter, count=0", count: 0, startIndex: 1, filter: "", wantTotalResults: 3, wantResults: 0, wantFirstID: 0},
+		{name: "no filter, count=2", count: 2, startIndex: 1, filter: "", wantTotalResults: 3, wantResults: 2, wantFirstID: 1},
+		{name: "no filter, offset=2", count: 999, startIndex: 3, filter: "", wantTotalResults: 3, wantResults: 1, wantFirstID: 3},
+		{name: "no filter, count=999", count: 999, startIndex: 1, filter: "", wantTotalResults: 3, wantResults: 3, wantFirstID: 1},
+		{name: "filter, count=0", count: 0, startIndex: 1, filter: "displayName eq \"sales\"", wantTotalResults: 1, wantResults: 0, wantFirstID: 0},
+		{name: "filter: displayName", count: 999, startIndex: 1, filter: "displayName eq \"sales\"", wantTotalResults: 1, wantResults: 1, wantFirstID: 2},
+		{name: "filter: OR", count: 999, startIndex: 1, filter: "(displayName eq \"sales\") OR (displayName eq \"support\")", wantTotalResults: 2, wantResults: 2, wantFirstID: 2},
+		{name: "filter: OR, count=1, offset=1", count: 1, startIndex: 2, filter: "(displayName eq \"sales\") OR (displayName eq \"support\")", wantTotalResults: 2, wantResults: 1, wantFirstID: 3},
+		{name: "filter: no match", count: 999, startIndex: 1, filter: "displayName eq \"marketing\"", wantTotalResults: 0, wantResults: 0, wantFirstID: 0},
+	}
+
+	groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, db)
+	for _, c := range cases {
+		t.Run("TestGroupResourceHandler_GetAll "+c.name, func(t *testing.T) {
+			var params scim.ListRequestParams
+			if c.filter != "" {
+				filterExpr, err := filter.ParseFilter([]byte(c.filter))
+				if err != nil {
+					t.Fatal(err)
+				}
+				params = scim.ListRequestParams{Count: c.count, StartIndex: c.startIndex, Filter: filterExpr}
+			} else {
+				params = scim.ListRequestParams{Count: c.count, StartIndex: c.startIndex, Filter: nil}
+			}
+			page, err := groupResourceHandler.GetAll(&http.Request{}, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, c.wantTotalResults, page.TotalResults)
+			assert.Equal(t, c.wantResults, len(page.Resources))
+			if c.wantResults > 0 {
+				assert.Equal(t, strconv.Itoa(c.wantFirstID), page.Resources[0].ID)
+			}
+		})
+	}
+}
+
+func TestGroupResourceHandler_GetAll_UnsupportedFilterAttribute(t *testing.T) {
+	groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, getMockDBWithOrgs())
+
+	filterExpr, err := filter.ParseFilter([]byte(`members eq "1"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = groupResourceHandler.GetAll(&http.Request{}, scim.ListRequestParams{Count: 10, StartIndex: 1, Filter: filterExpr})
+
+	var scimErr scimerrors.ScimError
+	if !errors.As(err, &scimErr) {
+		t.Fatalf("expected a SCIM error, got %v", err)
+	}
+	assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+	assert.Equal(t, scimerrors.ScimTypeInvalidFilter, scimErr.ScimType)
+}
+
+func TestGroupResourceHandler_Patch(t *testing.T) {
+	path := func(t *testing.T, rawPath string) *filter.Path {
+		t.Helper()
+		p, err := filter.ParsePath([]byte(rawPath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &p
+	}
+	members := func(ids ...string) []interface{} {
+		values := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			values = append(values, map[string]interface{}{"value": id})
+		}
+		return values
+	}
+
+	for _, tc := range []struct {
+		name        string
+		operations  func(t *testing.T) []scim.PatchOperation
+		wantMembers []string
+	}{
+		{
+			name: "add members",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "add", Path: path(t, "members"), Value: members("2", "3")}}
+			},
+			wantMembers: []string{"1", "2", "3"},
+		},
+		{
+			name: "remove members",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "remove", Path: path(t, "members"), Value: members("1", "3")}}
+			},
+			wantMembers: []string{"2"},
+		},
+		{
+			name: "remove member by filter",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "remove", Path: path(t, `members[value eq "2"]`)}}
+			},
+			wantMembers: []string{"1"},
+		},
+		{
+			name: "remove all members",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "remove", Path: path(t, "members")}}
+			},
+			wantMembers: []string{},
+		},
+		{
+			name: "replace members",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "replace", Path: path(t, "members"), Value: members("3", "4")}}
+			},
+			wantMembers: []string{"3", "4"},
+		},
+		{
+			name: "add without path",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "add", Value: map[string]interface{}{"members": members("4")}}}
+			},
+			wantMembers: []string{"1", "2", "4"},
+		},
+		{
+			name: "unchanged displayName",
+			operations: func(t *testing.T) []scim.PatchOperation {
+				return []scim.PatchOperation{{Op: "replace", Path: path(t, "displayName"), Value: "engineering"}}
+			},
+			wantMembers: []string{"1", "2"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, getMockDBWithOrgs())
+			group, err := groupResourceHandler.Patch(&http.Request{}, "1", tc.operations(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.wantMembers, memberValues(group))
+		})
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		db := getMockDBWithOrgs()
+		groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := groupResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{{Op: "replace", Path: path(t, "displayName"), Value: "platform"}})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeMutability, scimErr.ScimType)
+		mockassert.NotCalled(t, db.Orgs().(*database.MockOrgStore).UpdateFunc)
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		db := getMockDBWithOrgs()
+		groupResourceHandler := NewGroupResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := groupResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{{Op: "add", Path: path(t, "members"), Value: members("404")}})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		mockassert.NotCalled(t, db.OrgMembers().(*database.MockOrgMemberStore).CreateFunc)
+	})
+}
+
+// memberValues returns the values of the members of the given group.
+func memberValues(group scim.Resource) []string {
+	values := []string{}
+	for _, member := range group.Attributes["members"].([]interface{}) {
+		values = append(values, member.(map[string]interface{})["value"].(string))
+	}
+	return values
+}
+
+// getMockDBWithOrgs returns a mock DB with the users of getMockDB and three organizations. Users 1 and 2 are
+// members of the first one.
+func getMockDBWithOrgs() *database.MockDB {
+	orgs := []*types.Org{
+		{ID: 1, Name: "engineering"},
+		{ID: 2, Name: "sales"},
+		{ID: 3, Name: "support"},
+	}
+	members := map[int32][]int32{1: {1, 2}}
+
+	orgStore := database.NewMockOrgStore()
+	orgStore.GetByIDFunc.SetDefaultHook(func(ctx context.Context, id int32) (*types.Org, error) {
+		for _, org := range orgs {
+			if org.ID == id {
+				return org, nil
+			}
+		}
+		return nil, &database.OrgNotFoundError{Message: "not found"}
+	})
+	orgStore.ListFunc.SetDefaultHook(func(ctx context.Context, opt *database.OrgsListOptions) ([]*types.Org, error) {
+		if opt.LimitOffset == nil {
+			return orgs, nil
+		}
+		start := opt.Offset
+		if start > len(orgs) {
+			start = len(orgs)
+		}
+		end := start + opt.Limit
+		if end > len(orgs) {
+			end = len(orgs)
+		}
+		return orgs[start:end], nil
+	})
+	orgStore.CountFunc.SetDefaultHook(func(ctx context.Context, opt database.OrgsListOptions) (int, error) {
+		return len(orgs), nil
+	})
+	orgStore.CreateFunc.SetDefaultHook(func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+		org := &types.Org{ID: int32(len(orgs) + 1), Name: name, DisplayName: displayName}
+		orgs = append(orgs, org)
+		return org, nil
+	})
+
+	orgMembers := database.NewMockOrgMemberStore()
+	orgMembers.GetByOrgIDFunc.SetDefaultHook(func(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+		var memberships []*types.OrgMembership
+		for _, userID := range members[orgID] {
+			memberships = append(memberships, &types.OrgMembership{OrgID: orgID, UserID: userID})
+		}
+		return memberships, nil
+	})
+	orgMembers.CreateFunc.SetDefaultHook(func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		members[orgID] = append(members[orgID], userID)
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	})
+	orgMembers.RemoveFunc.SetDefaultHook(func(ctx context.Context, orgID, userID int32) error {
+		for i, id := range members[orgID] {
+			if id == userID {
+				members[orgID] = append(members[orgID][:i], members[orgID][i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("not a member")
+	})
+
+	namespaces := database.NewMockNamespaceStore()
+	namespaces.GetByNameFunc.SetDefaultHook(func(ctx context.Context, name string) (*database.Namespace, error) {
+		if name == "taken" {
+			return &database.Namespace{Name: name, User: 1}, nil
+		}
+		return nil, database.ErrNamespaceNotFound
+	})
+
+	db := getMockDB()
+	db.OrgsFunc.SetDefaultReturn(orgStore)
+	db.OrgMembersFunc.SetDefaultReturn(orgMembers)
+	db.NamespacesFunc.SetDefaultReturn(namespaces)
+	return db
+}
@@ -2,17 +2,18 @@ package scim
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/elimity-com/scim"
-	"github.com/elimity-com/scim/optional"
 	logger "github.com/sourcegraph/log"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 )
 
@@ -20,30 +21,43 @@ import (
 func Init(ctx context.Context, observationCtx *observation.Context, db database.DB, _ codeintel.Services, _ conftypes.UnifiedWatchable, s *enterprise.Services) error {
 	s.SCIMHandler = NewHandler(ctx, db, observationCtx)
 
+	conf.ContributeValidator(func(cfg conftypes.SiteConfigQuerier) conf.Problems {
+		return validateDefaultRole(ctx, db, cfg.SiteConfig().ScimDefaultRole)
+	})
+
+	return nil
+}
+
+// validateDefaultRole reports a problem if the role that is configured to be assigned to users created through SCIM
+// doesn't exist.
+func validateDefaultRole(ctx context.Context, db database.DB, name string) conf.Problems {
+	if name == "" {
+		return nil
+	}
+	role, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: name})
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return conf.NewSiteProblems(fmt.Sprintf("`scim.defaultRole` is set to %q, but no role with that name exists.", name))
+		}
+		return conf.NewSiteProblems(fmt.Sprintf("Could not check whether the role %q in `scim.defaultRole` exists: %s", name, err))
+	}
+	if role.System {
+		return conf.NewSiteProblems(fmt.Sprintf("`scim.defaultRole` is set to %q, but system roles cannot be assigned to users created through SCIM.", name))
+	}
 	return nil
 }
 
 // NewHandler creates and returns a new SCIM 2.0 handler.
 func NewHandler(ctx context.Context, db database.DB, observationCtx *observation.Context) http.Handler {
-	config := scim.ServiceProviderConfig{
-		DocumentationURI: optional.NewString("docs.sourcegraph.com/admin/scim"),
-		MaxResults:       100,
-		SupportFiltering: true,
-		AuthenticationSchemes: []scim.AuthenticationScheme{
-			{
-				Type:             scim.AuthenticationTypeOauthBearerToken,
-				Name:             "OAuth Bearer Token",
-				Description:      "Authentication scheme using the Bearer Token standard – use the key 'scim.authToken' in the site config to set the token.",
-				SpecURI:          optional.NewString("https://tools.ietf.org/html/rfc6750"),
-				DocumentationURI: optional.NewString("docs.sourcegraph.com/admin/scim"),
-				Primary:          true,
-			},
-		},
-	}
+	config := newServiceProviderConfig()
 
 	var userResourceHandler = NewUserResourceHandler(ctx, observationCtx, db)
+	var groupResourceHandler = NewGroupResourceHandler(ctx, observationCtx, db)
 
-	resourceTypes := []scim.ResourceType{createUserResourceType(userResourceHandler)}
+	resourceTypes := []scim.ResourceType{
+		createUserResourceType(userResourceHandler),
+		createGroupResourceType(groupResourceHandler),
+	}
 
 	server := scim.Server{
 		Config:        config,
@@ -58,6 +72,19 @@ func NewHandler(ctx context.Context, db database.DB, observationCtx *observation
 		}
 		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/.api/scim")
 		observationCtx.Logger.Error("SCIM request", logger.String("method", r.Method), logger.String("path", r.URL.Path)) // TODO for debugging
+		// Reject oversized bodies before they are read completely.
+		if scimErr := checkPatchBodySize(r); scimErr != nil {
+			writeSCIMError(w, scimErr)
+			return
+		}
+		if scimErr := checkIDImmutable(r, db); scimErr != nil {
+			writeSCIMError(w, scimErr)
+			return
+		}
+		if isServiceProviderConfigRequest(r) {
+			serveServiceProviderConfig(w, config)
+			return
+		}
 		server.ServeHTTP(w, r)
 	})
 
@@ -0,0 +1,39 @@
+package scim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestValidateDefaultRole(t *testing.T) {
+	roles := database.NewMockRoleStore()
+	roles.GetFunc.SetDefaultHook(func(_ context.Context, opts database.GetRoleOpts) (*types.Role, error) {
+		switch opts.Name {
+		case "PROVISIONED":
+			return &types.Role{ID: 7, Name: opts.Name}, nil
+		case string(types.SiteAdministratorSystemRole):
+			return &types.Role{ID: 2, Name: opts.Name, System: true}, nil
+		}
+		return nil, &database.RoleNotFoundErr{}
+	})
+	db := database.NewMockDB()
+	db.RolesFunc.SetDefaultReturn(roles)
+
+	assert.Empty(t, validateDefaultRole(context.Background(), db, ""))
+	assert.Empty(t, validateDefaultRole(context.Background(), db, "PROVISIONED"))
+
+	problems := validateDefaultRole(context.Background(), db, "MISSING")
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, "`scim.defaultRole` is set to \"MISSING\", but no role with that name exists.", problems[0].String())
+	}
+
+	problems = validateDefaultRole(context.Background(), db, string(types.SiteAdministratorSystemRole))
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, "`scim.defaultRole` is set to \"SITE_ADMINISTRATOR\", but system roles cannot be assigned to users created through SCIM.", problems[0].String())
+	}
+}
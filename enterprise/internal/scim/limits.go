@@ -0,0 +1,72 @@
+package scim
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+const (
+	// defaultMaxPatchOperations is the maximum number of operations in a PATCH request if scim.maxPatchOperations
+	// is not set.
+	defaultMaxPatchOperations = 100
+	// defaultMaxPatchBodyBytes is the maximum size of the body of a PATCH request if scim.maxPatchBodyBytes is not
+	// set.
+	defaultMaxPatchBodyBytes = 1 << 20
+)
+
+// maxPatchOperations returns the maximum number of operations in a PATCH request.
+func maxPatchOperations() int {
+	if limit := conf.Get().ScimMaxPatchOperations; limit > 0 {
+		return limit
+	}
+	return defaultMaxPatchOperations
+}
+
+// maxPatchBodyBytes returns the maximum size of the body of a PATCH request, in bytes.
+func maxPatchBodyBytes() int {
+	if limit := conf.Get().ScimMaxPatchBodyBytes; limit > 0 {
+		return limit
+	}
+	return defaultMaxPatchBodyBytes
+}
+
+// checkPatchBodySize returns a SCIM error if the body of the given PATCH request is larger than allowed. At most
+// one byte more than the limit is read, so that oversized bodies are rejected without reading them completely.
+func checkPatchBodySize(r *http.Request) *scimerrors.ScimError {
+	if r.Method != http.MethodPatch || r.Body == nil {
+		return nil
+	}
+
+	limit := maxPatchBodyBytes()
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	if err != nil {
+		return nil
+	}
+	if len(body) > limit {
+		return &scimerrors.ScimError{
+			Detail: fmt.Sprintf("The request body exceeds the maximum size of %d bytes.", limit),
+			Status: http.StatusRequestEntityTooLarge,
+		}
+	}
+	// Restore the body so that it can be read again.
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// checkPatchOperationCount returns a SCIM error if there are more PATCH operations than allowed.
+func checkPatchOperationCount(operations int) error {
+	if limit := maxPatchOperations(); operations > limit {
+		return scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeTooMany,
+			Detail:   fmt.Sprintf("The request contains %d operations, but at most %d are allowed.", operations, limit),
+			Status:   http.StatusBadRequest,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package scim
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestCheckPatchBodySize(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimMaxPatchBodyBytes: 20}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	t.Run("body within limit", func(t *testing.T) {
+		body := `{"Operations": []}`
+		r := httptest.NewRequest(http.MethodPatch, "/Users/1", strings.NewReader(body))
+		assert.Nil(t, checkPatchBodySize(r))
+
+		// The body can still be read by the SCIM server.
+		restored, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(restored))
+	})
+
+	t.Run("body exceeding limit", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPatch, "/Users/1", strings.NewReader(strings.Repeat("x", 21)))
+		scimErr := checkPatchBodySize(r)
+		if assert.NotNil(t, scimErr) {
+			assert.Equal(t, http.StatusRequestEntityTooLarge, scimErr.Status)
+		}
+	})
+
+	t.Run("other methods are not limited", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPut, "/Users/1", strings.NewReader(strings.Repeat("x", 21)))
+		assert.Nil(t, checkPatchBodySize(r))
+	})
+}
@@ -0,0 +1,172 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+)
+
+// checkIDImmutable returns a SCIM mutability error if the given PUT or PATCH request to a user resource tries to
+// change the resource's "id". The SCIM library drops attributes that aren't part of the schema before calling the
+// resource handler, so without this check, changes to "id" would be silently ignored.
+// "externalId" is not checked here: it is a read-write attribute and may be changed by the client.
+func checkIDImmutable(r *http.Request, db database.DB) *scimerrors.ScimError {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		return nil
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/Users/")
+	if id == r.URL.Path || id == "" || r.Body == nil {
+		return nil
+	}
+
+	limit := maxPatchBodyBytes()
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	if err != nil {
+		return &scimerrors.ScimError{
+			Detail: fmt.Sprintf("Could not read the request body: %s", err),
+			Status: http.StatusBadRequest,
+		}
+	}
+	if len(body) > limit {
+		return &scimerrors.ScimError{
+			Detail: fmt.Sprintf("The request body exceeds the maximum size of %d bytes.", limit),
+			Status: http.StatusRequestEntityTooLarge,
+		}
+	}
+	// Restore the body so that the SCIM server can read it again.
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	ids := resourceIDsResolver{ctx: r.Context(), db: db, id: id}
+
+	if r.Method == http.MethodPut {
+		var attributes map[string]interface{}
+		if err := json.Unmarshal(body, &attributes); err != nil {
+			// Malformed bodies are rejected by the SCIM server.
+			return nil
+		}
+		return ids.checkUnchanged(attributes)
+	}
+
+	var patch struct {
+		Operations []struct {
+			Op    string
+			Path  string
+			Value interface{}
+		}
+	}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nil
+	}
+	for _, operation := range patch.Operations {
+		if strings.EqualFold(operation.Path, "id") {
+			if strings.EqualFold(operation.Op, "remove") {
+				return idMutabilityError()
+			}
+			if scimErr := ids.checkValue(operation.Value); scimErr != nil {
+				return scimErr
+			}
+			continue
+		}
+		if operation.Path == "" {
+			if value, ok := operation.Value.(map[string]interface{}); ok {
+				if scimErr := ids.checkUnchanged(value); scimErr != nil {
+					return scimErr
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resourceIDsResolver looks up the IDs that identify the user with the given SCIM resource ID. A user is identified
+// by both their numeric ID and, if they were assigned one, their opaque ID. The user is only looked up once an "id"
+// attribute is found.
+type resourceIDsResolver struct {
+	ctx context.Context
+	db  database.DB
+	id  string
+
+	resolved bool
+	ids      []string
+}
+
+// checkUnchanged returns a SCIM mutability error if the given attributes contain an "id" that doesn't identify the
+// user.
+func (r *resourceIDsResolver) checkUnchanged(attributes map[string]interface{}) *scimerrors.ScimError {
+	for name, value := range attributes {
+		if strings.EqualFold(name, "id") {
+			if scimErr := r.checkValue(value); scimErr != nil {
+				return scimErr
+			}
+		}
+	}
+	return nil
+}
+
+// checkValue returns a SCIM mutability error if the given "id" value doesn't identify the user.
+func (r *resourceIDsResolver) checkValue(value interface{}) *scimerrors.ScimError {
+	var id string
+	switch v := value.(type) {
+	case string:
+		id = v
+	case float64:
+		id = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return idMutabilityError()
+	}
+
+	if !r.resolved {
+		users, err := r.db.Users().ListForSCIM(r.ctx, resourceIDListOptions(r.id))
+		if err != nil {
+			return &scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+		r.resolved = true
+		if len(users) == 0 {
+			// The SCIM server responds that the user doesn't exist.
+			return nil
+		}
+		r.ids = []string{strconv.FormatInt(int64(users[0].ID), 10)}
+		if users[0].SCIMResourceID != "" {
+			r.ids = append(r.ids, users[0].SCIMResourceID)
+		}
+	}
+	if r.ids == nil {
+		return nil
+	}
+
+	for _, userID := range r.ids {
+		if id == userID {
+			return nil
+		}
+	}
+	return idMutabilityError()
+}
+
+func idMutabilityError() *scimerrors.ScimError {
+	return &scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeMutability,
+		Detail:   "The \"id\" attribute is immutable.",
+		Status:   http.StatusBadRequest,
+	}
+}
+
+// writeSCIMError writes the given SCIM error as the response.
+func writeSCIMError(w http.ResponseWriter, scimErr *scimerrors.ScimError) {
+	raw, err := json.Marshal(scimErr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(scimErr.Status)
+	_, _ = w.Write(raw)
+}
@@ -0,0 +1,164 @@
+package scim
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestCheckIDImmutable(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        string
+		body          string
+		wantRejection bool
+		// wantExternalID is the external ID that is stored for the user after serving accepted requests.
+		wantExternalID string
+	}{
+		{
+			name:          "PUT changing id",
+			method:        http.MethodPut,
+			body:          `{"id": "2", "userName": "user1"}`,
+			wantRejection: true,
+		},
+		{
+			name:           "PUT with unchanged id and new externalId",
+			method:         http.MethodPut,
+			body:           `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "1", "userName": "user1", "externalId": "new-external-id"}`,
+			wantExternalID: "new-external-id",
+		},
+		{
+			name:          "PATCH replacing id",
+			method:        http.MethodPatch,
+			body:          `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "id", "value": "2"}]}`,
+			wantRejection: true,
+		},
+		{
+			name:          "PATCH replacing id without path",
+			method:        http.MethodPatch,
+			body:          `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "value": {"id": "2"}}]}`,
+			wantRejection: true,
+		},
+		{
+			name:          "PATCH removing id",
+			method:        http.MethodPatch,
+			body:          `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "remove", "path": "id"}]}`,
+			wantRejection: true,
+		},
+		{
+			name:           "PATCH replacing externalId",
+			method:         http.MethodPatch,
+			body:           `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "externalId", "value": "new-external-id"}]}`,
+			wantExternalID: "new-external-id",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, "/Users/1", strings.NewReader(c.body))
+			scimErr := checkIDImmutable(r, getMockDB())
+			if c.wantRejection {
+				if assert.NotNil(t, scimErr) {
+					assert.Equal(t, scimerrors.ScimTypeMutability, scimErr.ScimType)
+					assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+				}
+				return
+			}
+			assert.Nil(t, scimErr)
+
+			// The body must still be readable by the SCIM server.
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, c.body, string(body))
+
+			// The accepted change is stored.
+			db, users := getMockDBWithSCIMExternalAccounts(t)
+			w := httptest.NewRecorder()
+			NewHandler(context.Background(), db, &observation.TestContext).ServeHTTP(w, httptest.NewRequest(c.method, "/Users/1", strings.NewReader(c.body)))
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, c.wantExternalID, users[1].SCIMExternalID)
+		})
+	}
+}
+
+func TestCheckIDImmutable_ResourceIDs(t *testing.T) {
+	db := getMockDB()
+	users, err := db.Users().ListForSCIM(context.Background(), &database.UsersListOptions{UserIDs: []int32{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users[0].SCIMResourceID = "opaque1"
+
+	cases := []struct {
+		name          string
+		path          string
+		method        string
+		body          string
+		wantRejection bool
+	}{
+		{
+			name:   "PUT with the numeric id of a user addressed by their opaque id",
+			path:   "/Users/opaque1",
+			method: http.MethodPut,
+			body:   `{"id": "1", "userName": "user1"}`,
+		},
+		{
+			name:   "PUT with the opaque id of a user addressed by their numeric id",
+			path:   "/Users/1",
+			method: http.MethodPut,
+			body:   `{"id": "opaque1", "userName": "user1"}`,
+		},
+		{
+			name:   "PATCH replacing id with the same number",
+			path:   "/Users/1",
+			method: http.MethodPatch,
+			body:   `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "id", "value": 1}]}`,
+		},
+		{
+			name:          "PUT with the opaque id of another user",
+			path:          "/Users/1",
+			method:        http.MethodPut,
+			body:          `{"id": "opaque2", "userName": "user1"}`,
+			wantRejection: true,
+		},
+		{
+			name:          "PATCH replacing id with an object",
+			path:          "/Users/1",
+			method:        http.MethodPatch,
+			body:          `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "id", "value": {"value": "1"}}]}`,
+			wantRejection: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scimErr := checkIDImmutable(httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)), db)
+			if c.wantRejection {
+				if assert.NotNil(t, scimErr) {
+					assert.Equal(t, scimerrors.ScimTypeMutability, scimErr.ScimType)
+				}
+				return
+			}
+			assert.Nil(t, scimErr)
+		})
+	}
+
+	t.Run("unreadable body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPut, "/Users/1", iotest.ErrReader(errors.New("connection reset")))
+		scimErr := checkIDImmutable(r, db)
+		if assert.NotNil(t, scimErr) {
+			assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		}
+	})
+}
@@ -0,0 +1,512 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/elimity-com/scim"
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/elimity-com/scim/schema"
+	scimfilter "github.com/scim2/filter-parser/v2"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/scim/filter"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Patch update one or more attributes of a SCIM resource using a sequence of
+// operations to "add", "remove", or "replace" values.
+// If you return no Resource.Attributes, a 204 No Content status code will be returned.
+// This case is only valid in the following scenarios:
+// 1. the Add/Replace operation should return No Content only when the value already exists AND is the same.
+// 2. the Remove operation should return No Content when the value to be removed is already absent.
+// More information in Section 3.5.2 of RFC 7644: https://tools.ietf.org/html/rfc7644#section-3.5.2
+// The operations are applied to the current attributes of the user, which are then stored like in Replace. Setting
+// "active" to false deletes the user like Delete does, and returns no resource.
+func (h *UserResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
+	if err := checkPatchOperationCount(len(operations)); err != nil {
+		return scim.Resource{}, err
+	}
+
+	user, err := h.getUser(r.Context(), id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	resource := h.convertUserToSCIMResource(user)
+	attributes := resource.Attributes
+	for _, operation := range operations {
+		if operation.Path != nil {
+			if err := h.applyPatchOperation(attributes, operation.Op, *operation.Path, operation.Value); err != nil {
+				return scim.Resource{}, err
+			}
+			continue
+		}
+
+		// Without a path, the value maps attribute paths to their new values.
+		values, ok := operation.Value.(map[string]interface{})
+		if !ok || strings.EqualFold(operation.Op, scim.PatchOperationRemove) {
+			return scim.Resource{}, scimerrors.ScimErrorInvalidValue
+		}
+		for rawPath, value := range values {
+			// The attributes of the enterprise user extension may also be given as one object.
+			if strings.EqualFold(rawPath, enterpriseUserSchemaURN) {
+				extension, ok := value.(map[string]interface{})
+				if !ok {
+					return scim.Resource{}, scimerrors.ScimErrorInvalidValue
+				}
+				for name, value := range extension {
+					if err := h.applyPatchOperation(attributes, operation.Op, enterpriseAttributePath(name), value); err != nil {
+						return scim.Resource{}, err
+					}
+				}
+				continue
+			}
+
+			path, err := scimfilter.ParsePath([]byte(rawPath))
+			if err != nil {
+				return scim.Resource{}, scimerrors.ScimErrorInvalidPath
+			}
+			if err := h.applyPatchOperation(attributes, operation.Op, path, value); err != nil {
+				return scim.Resource{}, err
+			}
+		}
+	}
+	syncDisplayName(h.convertUserToSCIMResource(user).Attributes, attributes)
+
+	active, ok := attributes["active"].(bool)
+	deactivate := ok && !active
+
+	if isDryRun() {
+		if err := h.logPlannedUpdate(r.Context(), "patch user", user, attributes); err != nil {
+			return scim.Resource{}, err
+		}
+		if deactivate {
+			h.logPlannedDelete(user)
+		}
+		return resource, nil
+	}
+
+	// Apply the patch and the deactivation atomically, so that a failed deletion doesn't leave a half-patched user.
+	err = h.db.WithTransact(r.Context(), func(tx database.DB) error {
+		txHandler := h.withDB(tx)
+		if err := txHandler.updateUser(r.Context(), user, attributes); err != nil {
+			return err
+		}
+		if err := txHandler.patchEmails(r.Context(), user, attributes); err != nil {
+			return err
+		}
+		if deactivate {
+			return deleteUser(r.Context(), tx, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) {
+			return scim.Resource{}, scimErr
+		}
+		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+
+	// Deactivated users are deleted like on DELETE, so there is no resource left to return.
+	if deactivate {
+		return scim.Resource{}, nil
+	}
+	return h.Get(r, id)
+}
+
+// enterpriseAttributePath returns the path of the attribute with the given name in the enterprise user extension.
+func enterpriseAttributePath(name string) scimfilter.Path {
+	uri := enterpriseUserSchemaURN
+	return scimfilter.Path{AttributePath: scimfilter.AttributePath{URIPrefix: &uri, AttributeName: name}}
+}
+
+// applyPatchOperation applies a single PATCH operation on the given path to the attributes of a user. It returns an
+// invalidPath error if the path is not an attribute of users that can be patched.
+func (h *UserResourceHandler) applyPatchOperation(attributes scim.ResourceAttributes, op string, path scimfilter.Path, value interface{}) error {
+	op = strings.ToLower(op)
+	if op != scim.PatchOperationAdd && op != scim.PatchOperationReplace && op != scim.PatchOperationRemove {
+		return scimerrors.ScimErrorInvalidValue
+	}
+
+	attrPath := path.AttributePath
+	switch uri := attrPath.URI(); {
+	case uri == enterpriseUserSchemaURN:
+		if attrPath.SubAttribute != nil || path.ValueExpression != nil {
+			return unsupportedPatchPathError(path)
+		}
+		return applyEnterpriseAttributePatch(attributes, op, attrPath.AttributeName, value)
+	case uri != "" && uri != h.coreSchema.ID:
+		return unsupportedPatchPathError(path)
+	}
+
+	switch strings.ToLower(attrPath.AttributeName) {
+	case "username", "displayname", "externalid":
+		if attrPath.SubAttribute != nil || path.ValueExpression != nil {
+			return unsupportedPatchPathError(path)
+		}
+		name := stringAttributes[strings.ToLower(attrPath.AttributeName)]
+		if op == scim.PatchOperationRemove {
+			// Users always have a username.
+			if name == "userName" {
+				return scimerrors.ScimErrorMutability
+			}
+			attributes[name] = ""
+			return nil
+		}
+		str, ok := value.(string)
+		if !ok {
+			return scimerrors.ScimErrorInvalidValue
+		}
+		attributes[name] = str
+	case "active":
+		if attrPath.SubAttribute != nil || path.ValueExpression != nil {
+			return unsupportedPatchPathError(path)
+		}
+		if op == scim.PatchOperationRemove {
+			return scimerrors.ScimErrorMutability
+		}
+		active, ok := value.(bool)
+		if !ok {
+			return scimerrors.ScimErrorInvalidValue
+		}
+		attributes["active"] = active
+	case "name":
+		if path.ValueExpression != nil {
+			return unsupportedPatchPathError(path)
+		}
+		return applyNamePatch(attributes, op, attrPath.SubAttributeName(), value, path)
+	case "emails":
+		return h.applyEmailsPatch(attributes, op, path, value)
+	default:
+		return unsupportedPatchPathError(path)
+	}
+	return nil
+}
+
+// applyEnterpriseAttributePatch applies a PATCH operation to the attribute of the enterprise user extension with the
+// given name.
+func applyEnterpriseAttributePatch(attributes scim.ResourceAttributes, op, name string, value interface{}) error {
+	extension, ok := attributes[enterpriseUserSchemaURN].(map[string]interface{})
+	if !ok {
+		extension = map[string]interface{}{}
+		attributes[enterpriseUserSchemaURN] = extension
+	}
+
+	if op == scim.PatchOperationRemove {
+		delete(extension, name)
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return scimerrors.ScimErrorInvalidValue
+	}
+	if str == "" {
+		delete(extension, name)
+	} else {
+		extension[name] = str
+	}
+	return nil
+}
+
+// stringAttributes maps the lowercase names of the string attributes of users to their canonical names.
+var stringAttributes = map[string]string{
+	"username":    "userName",
+	"displayname": "displayName",
+	"externalid":  "externalId",
+}
+
+// nameSubAttributes maps the lowercase names of the sub-attributes of "name" to their canonical names.
+var nameSubAttributes = map[string]string{
+	"givenname":  "givenName",
+	"middlename": "middleName",
+	"familyname": "familyName",
+	"formatted":  "formatted",
+}
+
+// applyNamePatch applies a PATCH operation to the "name" attribute, or to the given sub-attribute of it.
+func applyNamePatch(attributes scim.ResourceAttributes, op, subAttribute string, value interface{}, path scimfilter.Path) error {
+	name, ok := attributes["name"].(map[string]interface{})
+	if !ok {
+		name = map[string]interface{}{}
+		attributes["name"] = name
+	}
+
+	values := map[string]interface{}{subAttribute: value}
+	if subAttribute == "" {
+		if op == scim.PatchOperationRemove {
+			for _, canonical := range nameSubAttributes {
+				name[canonical] = ""
+			}
+			return nil
+		}
+		// Sub-attributes that aren't given are left unchanged.
+		if values, ok = value.(map[string]interface{}); !ok {
+			return scimerrors.ScimErrorInvalidValue
+		}
+	}
+
+	for subAttribute, value := range values {
+		canonical, ok := nameSubAttributes[strings.ToLower(subAttribute)]
+		if !ok {
+			return unsupportedPatchPathError(path)
+		}
+		if op == scim.PatchOperationRemove {
+			name[canonical] = ""
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return scimerrors.ScimErrorInvalidValue
+		}
+		name[canonical] = str
+	}
+	return nil
+}
+
+// applyEmailsPatch applies a PATCH operation to the "emails" attribute. If the path has a value filter, e.g.
+// emails[type eq "work"].value, the operation applies to the matching email addresses only.
+func (h *UserResourceHandler) applyEmailsPatch(attributes scim.ResourceAttributes, op string, path scimfilter.Path, value interface{}) error {
+	emails, _ := attributes["emails"].([]interface{})
+
+	if path.ValueExpression == nil {
+		if path.AttributePath.SubAttribute != nil {
+			return unsupportedPatchPathError(path)
+		}
+		switch op {
+		case scim.PatchOperationRemove:
+			attributes["emails"] = []interface{}{}
+			return nil
+		case scim.PatchOperationReplace:
+			emails = nil
+		}
+		switch value := value.(type) {
+		case []interface{}:
+			emails = append(emails, value...)
+		case map[string]interface{}:
+			emails = append(emails, value)
+		default:
+			return scimerrors.ScimErrorInvalidValue
+		}
+		attributes["emails"] = emails
+		return nil
+	}
+
+	emailsAttribute, ok := h.coreAttribute("emails")
+	if !ok {
+		return unsupportedPatchPathError(path)
+	}
+	validator := filter.NewFilterValidator(path.ValueExpression, schema.Schema{
+		ID:         h.coreSchema.ID,
+		Attributes: emailsAttribute.SubAttributes(),
+	})
+	subAttribute := path.SubAttributeName()
+
+	var patched []interface{}
+	matched := false
+	for _, emailRaw := range emails {
+		email, ok := emailRaw.(map[string]interface{})
+		if !ok || validator.PassesFilter(email) != nil {
+			patched = append(patched, emailRaw)
+			continue
+		}
+		matched = true
+		switch {
+		case op == scim.PatchOperationRemove && subAttribute == "":
+			// Drop the email address.
+		case op == scim.PatchOperationRemove:
+			delete(email, subAttribute)
+			patched = append(patched, email)
+		case subAttribute == "":
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return scimerrors.ScimErrorInvalidValue
+			}
+			for k, v := range values {
+				email[k] = v
+			}
+			patched = append(patched, email)
+		default:
+			email[subAttribute] = value
+			patched = append(patched, email)
+		}
+	}
+
+	if !matched {
+		if op == scim.PatchOperationRemove {
+			return scimerrors.ScimErrorNoTarget
+		}
+		// Identity providers like Azure AD use e.g. emails[type eq "work"].value to set the work email address, even
+		// if the user doesn't have one yet, so add it.
+		email, ok := newEmailFromFilter(path.ValueExpression)
+		if !ok {
+			return scimerrors.ScimErrorNoTarget
+		}
+		// The type of email addresses isn't stored, so a filter on the type can't match stored email addresses. It
+		// refers to the primary email address instead, which is replaced rather than added to on every request.
+		if _, typed := email["type"]; typed && !hasEmailTypes(patched) {
+			if i := primaryEmailIndex(patched); i >= 0 {
+				primary := patched[i].(map[string]interface{})
+				for k, v := range email {
+					primary[k] = v
+				}
+				email = primary
+				email["primary"] = true
+				patched = append(patched[:i], patched[i+1:]...)
+			}
+		}
+		if subAttribute == "" {
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return scimerrors.ScimErrorInvalidValue
+			}
+			for k, v := range values {
+				email[k] = v
+			}
+		} else {
+			email[subAttribute] = value
+		}
+		patched = append(patched, email)
+	}
+
+	attributes["emails"] = patched
+	return nil
+}
+
+// newEmailFromFilter returns a new email address with the sub-attribute that the given value filter compares for
+// equality, e.g. {"type": "work"} for type eq "work". It returns false for other filters.
+func newEmailFromFilter(expr scimfilter.Expression) (map[string]interface{}, bool) {
+	e, ok := expr.(*scimfilter.AttributeExpression)
+	if !ok || e.Operator != scimfilter.EQ || e.AttributePath.SubAttribute != nil {
+		return nil, false
+	}
+	return map[string]interface{}{e.AttributePath.AttributeName: e.CompareValue}, true
+}
+
+// hasEmailTypes returns true if any of the given email addresses has a type.
+func hasEmailTypes(emails []interface{}) bool {
+	for _, emailRaw := range emails {
+		if email, ok := emailRaw.(map[string]interface{}); ok && email["type"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryEmailIndex returns the index of the email address marked as primary, otherwise of the first email address,
+// like extractPrimaryEmail. It returns -1 if there are no email addresses.
+func primaryEmailIndex(emails []interface{}) int {
+	first := -1
+	for i, emailRaw := range emails {
+		email, ok := emailRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if email["primary"] == true {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+// coreAttribute returns the attribute of the core user schema with the given name.
+func (h *UserResourceHandler) coreAttribute(name string) (schema.CoreAttribute, bool) {
+	for _, attribute := range h.coreSchema.Attributes {
+		if strings.EqualFold(attribute.Name(), name) {
+			return attribute, true
+		}
+	}
+	return schema.CoreAttribute{}, false
+}
+
+// syncDisplayName updates the display name of the patched attributes to match the patched name, unless the display
+// name itself was patched. Sourcegraph only stores the display name, so changes to e.g. name.givenName would otherwise
+// be lost.
+func syncDisplayName(original, patched scim.ResourceAttributes) {
+	if patched["displayName"] != original["displayName"] {
+		return
+	}
+	originalName, _ := original["name"].(map[string]interface{})
+	name, _ := patched["name"].(map[string]interface{})
+	if name["formatted"] != originalName["formatted"] {
+		patched["displayName"] = name["formatted"]
+		return
+	}
+
+	var pieces []string
+	changed := false
+	for _, subAttribute := range []string{"givenName", "middleName", "familyName"} {
+		if name[subAttribute] != originalName[subAttribute] {
+			changed = true
+		}
+		if piece, _ := name[subAttribute].(string); piece != "" {
+			pieces = append(pieces, piece)
+		}
+	}
+	if changed {
+		patched["displayName"] = strings.Join(pieces, " ")
+	}
+}
+
+// patchEmails adds the email addresses of the patched attributes that the user doesn't have yet, and removes the ones
+// that are no longer given.
+func (h *UserResourceHandler) patchEmails(ctx context.Context, user *types.UserForSCIM, attributes scim.ResourceAttributes) error {
+	emails := extractEmails(attributes)
+	given := make(map[string]struct{}, len(emails))
+	verified := emailsVerified()
+	for _, email := range emails {
+		given[email] = struct{}{}
+		if err := h.addEmail(ctx, user.ID, email, verified); err != nil {
+			return scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+	}
+
+	// A replaced primary email address can only be removed once the new one is primary. Only verified email addresses
+	// can be primary, so unverified ones stay secondary until the user verifies them.
+	if primaryEmail := markedPrimaryEmail(attributes); primaryEmail != "" && verified {
+		if err := h.db.UserEmails().SetPrimaryEmail(ctx, user.ID, primaryEmail); err != nil {
+			return scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+	}
+
+	for _, email := range user.Emails {
+		if _, ok := given[email]; ok {
+			continue
+		}
+		if err := h.db.UserEmails().Remove(ctx, user.ID, email); err != nil {
+			return scimerrors.ScimError{
+				ScimType: scimerrors.ScimTypeMutability,
+				Detail:   fmt.Sprintf("The email address %q can't be removed: %s", email, err),
+				Status:   http.StatusBadRequest,
+			}
+		}
+	}
+	return nil
+}
+
+// markedPrimaryEmail returns the email address of the attributes that is explicitly marked as primary, if any.
+func markedPrimaryEmail(attributes scim.ResourceAttributes) string {
+	emails, _ := attributes["emails"].([]interface{})
+	for _, emailRaw := range emails {
+		if email, ok := emailRaw.(map[string]interface{}); ok && email["primary"] == true {
+			value, _ := email["value"].(string)
+			return value
+		}
+	}
+	return ""
+}
+
+func unsupportedPatchPathError(path scimfilter.Path) scimerrors.ScimError {
+	return scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeInvalidPath,
+		Detail:   fmt.Sprintf("Patching the attribute %q is not supported.", path.String()),
+		Status:   http.StatusBadRequest,
+	}
+}
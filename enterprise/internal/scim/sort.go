@@ -0,0 +1,70 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	scimerrors "github.com/elimity-com/scim/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// userLess compares two users by one attribute.
+type userLess func(a, b *types.UserForSCIM) bool
+
+// sortableUserAttributes are the (lowercase) names of the user attributes that lists can be sorted by.
+// Users with an opaque resource ID are sorted by when they were created when sorting by "id".
+var sortableUserAttributes = map[string]userLess{
+	"id": func(a, b *types.UserForSCIM) bool {
+		return a.ID < b.ID
+	},
+	"username": func(a, b *types.UserForSCIM) bool {
+		return strings.ToLower(a.Username) < strings.ToLower(b.Username)
+	},
+	"displayname": func(a, b *types.UserForSCIM) bool {
+		return strings.ToLower(a.DisplayName) < strings.ToLower(b.DisplayName)
+	},
+}
+
+// userSortFromRequest returns how to sort the users listed in response to the given request, based on the "sortBy"
+// and "sortOrder" query parameters, see Section 3.4.2.3 of RFC 7644. It returns nil if no sorting was requested.
+// The SCIM library doesn't parse these parameters, so they are read from the request directly.
+func userSortFromRequest(r *http.Request) (userLess, *scimerrors.ScimError) {
+	if r.URL == nil {
+		return nil, nil
+	}
+	query := r.URL.Query()
+	sortBy := query.Get("sortBy")
+	if sortBy == "" {
+		return nil, nil
+	}
+
+	less, ok := sortableUserAttributes[strings.ToLower(sortBy)]
+	if !ok {
+		return nil, &scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeInvalidValue,
+			Detail:   fmt.Sprintf("Sorting by the attribute %q is not supported.", sortBy),
+			Status:   http.StatusBadRequest,
+		}
+	}
+
+	switch sortOrder := query.Get("sortOrder"); strings.ToLower(sortOrder) {
+	case "", "ascending":
+		return less, nil
+	case "descending":
+		return func(a, b *types.UserForSCIM) bool { return less(b, a) }, nil
+	default:
+		return nil, &scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeInvalidValue,
+			Detail:   fmt.Sprintf("The sort order %q is not supported, use \"ascending\" or \"descending\".", sortOrder),
+			Status:   http.StatusBadRequest,
+		}
+	}
+}
+
+// sortUsers sorts the given users in place. Users that compare equal keep their relative order, which is by ID.
+func sortUsers(users []*types.UserForSCIM, less userLess) {
+	sort.SliceStable(users, func(i, j int) bool { return less(users[i], users[j]) })
+}
@@ -2,6 +2,7 @@ package scim
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,15 +12,23 @@ import (
 	scimerrors "github.com/elimity-com/scim/errors"
 	"github.com/elimity-com/scim/optional"
 	"github.com/elimity-com/scim/schema"
+	"github.com/google/uuid"
+	scimfilter "github.com/scim2/filter-parser/v2"
 	"github.com/sourcegraph/log"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/scim/filter"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
+// enterpriseUserSchemaURN is the ID of the SCIM enterprise user extension, see Section 4.3 of RFC 7643.
+const enterpriseUserSchemaURN = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
 // UserResourceHandler implements the scim.ResourceHandler interface for users.
 type UserResourceHandler struct {
 	ctx              context.Context
@@ -40,6 +49,13 @@ func NewUserResourceHandler(ctx context.Context, observationCtx *observation.Con
 	}
 }
 
+// withDB returns a copy of the handler that uses the given database, e.g. a transaction.
+func (h *UserResourceHandler) withDB(db database.DB) *UserResourceHandler {
+	copied := *h
+	copied.db = db
+	return &copied
+}
+
 // Create stores given attributes. Returns a resource with the attributes that are stored and a (new) unique identifier.
 func (h *UserResourceHandler) Create(_ *http.Request, attributes scim.ResourceAttributes) (scim.Resource, error) {
 	// Get external ID, primary email, username, and display name
@@ -50,6 +66,7 @@ func (h *UserResourceHandler) Create(_ *http.Request, attributes scim.ResourceAt
 	}
 	username := extractUsername(attributes)
 	displayName := extractDisplayName(attributes)
+	enterpriseAttributes := extractEnterpriseAttributes(attributes)
 
 	// Create user (with or without external ID)
 	// TODO: Use NewSCIMUser instead of NewUser?
@@ -57,36 +74,107 @@ func (h *UserResourceHandler) Create(_ *http.Request, attributes scim.ResourceAt
 		Email:           primaryEmail,
 		Username:        username,
 		DisplayName:     displayName,
-		EmailIsVerified: true,
+		EmailIsVerified: emailsVerified(),
 	}
-	var user *types.User
-	var err error
-	if optionalExternalID.Present() {
-		accountSpec := extsvc.AccountSpec{
-			ServiceType: "scim",
-			// TODO: provide proper service ID
-			ServiceID: "TODO",
-			AccountID: optionalExternalID.Value(),
-		}
-		user, err = h.db.UserExternalAccounts().CreateUserAndSave(h.ctx, newUser, accountSpec, extsvc.AccountData{})
-	} else {
-		user, err = h.db.Users().Create(h.ctx, newUser)
+	if !newUser.EmailIsVerified {
+		code, err := backend.MakeEmailVerificationCode()
+		if err != nil {
+			return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+		newUser.EmailVerificationCode = code
 	}
+	dryRun := isDryRun()
+	var user, reclaimedUser *types.User
+	var existingUser *types.UserForSCIM
+	var resourceID string
+	err := h.db.WithTransact(h.ctx, func(tx database.DB) (err error) {
+		if optionalExternalID.Present() {
+			existingUser, err = resolveExternalIDConflict(h.ctx, tx, optionalExternalID.Value(), primaryEmail)
+			if err != nil || existingUser != nil {
+				return err
+			}
+		}
+		if err := checkUsernameAvailable(h.ctx, tx, 0, newUser.Username); err != nil {
+			return err
+		}
+		deletedUser, err := resolveDeletedUserConflict(h.ctx, tx, &newUser)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			reclaimedUser = deletedUser
+			return nil
+		}
+		if deletedUser != nil {
+			user, err = reclaimDeletedUser(h.ctx, tx, deletedUser, newUser, optionalExternalID)
+		} else if optionalExternalID.Present() {
+			user, err = tx.UserExternalAccounts().CreateUserAndSave(h.ctx, newUser, scimAccountSpec(optionalExternalID.Value()), extsvc.AccountData{})
+		} else {
+			user, err = tx.Users().Create(h.ctx, newUser)
+		}
+		if err != nil {
+			return err
+		}
+		if conf.Get().ScimOpaqueResourceIDs {
+			resourceID = uuid.New().String()
+			if err := tx.Users().SetSCIMResourceID(h.ctx, user.ID, resourceID); err != nil {
+				return err
+			}
+		}
+		if len(enterpriseAttributes) > 0 {
+			if err := tx.Users().SetSCIMEnterpriseAttributes(h.ctx, user.ID, enterpriseAttributes); err != nil {
+				return err
+			}
+		}
+		return assignDefaultRole(h.ctx, tx, user.ID)
+	})
 	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) {
+			return scim.Resource{}, scimErr
+		}
 		if dbErr, ok := containsDBError(err); ok {
 			if code := dbErr.Code(); code == database.ErrorCodeUsernameExists || code == database.ErrorCodeEmailExists {
-				return scim.Resource{}, scimerrors.ScimError{Status: http.StatusConflict, Detail: err.Error()}
+				return scim.Resource{}, scimerrors.ScimError{ScimType: scimerrors.ScimTypeUniqueness, Status: http.StatusConflict, Detail: err.Error()}
 			}
 		}
 		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
 	}
 
+	// The IdP retried creating a user that was already created, e.g. after a timeout.
+	if existingUser != nil {
+		return h.convertUserToSCIMResource(existingUser), nil
+	}
+
+	if dryRun {
+		logDryRun(h.observationCtx.Logger, "create user",
+			log.String("username", newUser.Username),
+			log.String("email", primaryEmail),
+			log.Bool("reclaimsDeletedUser", reclaimedUser != nil))
+		// No user is created, so a random ID stands in for theirs, unless a deleted user would be reclaimed.
+		resourceID = uuid.New().String()
+		if reclaimedUser != nil {
+			resourceID = strconv.Itoa(int(reclaimedUser.ID))
+		}
+	}
+
+	if resourceID == "" {
+		resourceID = strconv.Itoa(int(user.ID))
+	}
+
+	// The stored username may differ from the requested one, e.g. when it got a suffix, and the IdP must learn it.
+	resourceAttributes := make(scim.ResourceAttributes, len(attributes))
+	for k, v := range attributes {
+		resourceAttributes[k] = v
+	}
+	resourceAttributes["userName"] = newUser.Username
+
 	var now = time.Now()
 
 	return scim.Resource{
-		ID:         strconv.Itoa(int(user.ID)),
+		ID:         resourceID,
 		ExternalID: optionalExternalID,
-		Attributes: attributes,
+		Attributes: resourceAttributes,
 		Meta: scim.Meta{
 			Created:      &now,
 			LastModified: &now,
@@ -94,6 +182,198 @@ func (h *UserResourceHandler) Create(_ *http.Request, attributes scim.ResourceAt
 	}, nil
 }
 
+// scimAccountSpec returns the spec of the external account that links a user to their SCIM external ID.
+func scimAccountSpec(externalID string) extsvc.AccountSpec {
+	return extsvc.AccountSpec{
+		ServiceType: "scim",
+		// TODO: provide proper service ID
+		ServiceID: "TODO",
+		AccountID: externalID,
+	}
+}
+
+// resolveExternalIDConflict returns the user whose SCIM external account has the given external ID, if there is one
+// with the given email address. This happens when the IdP retries a create request that succeeded, in which case the
+// existing user is returned instead of creating a new one. If the external ID belongs to a user with a different
+// email address, it returns a uniqueness error, so that two users are never linked to the same IdP user.
+func resolveExternalIDConflict(ctx context.Context, db database.DB, externalID string, email string) (*types.UserForSCIM, error) {
+	spec := scimAccountSpec(externalID)
+	accounts, err := db.UserExternalAccounts().List(ctx, database.ExternalAccountsListOptions{
+		ServiceType: spec.ServiceType,
+		ServiceID:   spec.ServiceID,
+		AccountID:   spec.AccountID,
+		LimitOffset: &database.LimitOffset{Limit: 1},
+	})
+	if err != nil || len(accounts) == 0 {
+		return nil, err
+	}
+
+	users, err := db.Users().ListForSCIM(ctx, &database.UsersListOptions{UserIDs: []int32{accounts[0].UserID}})
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	for _, userEmail := range users[0].Emails {
+		if strings.EqualFold(userEmail, email) {
+			return users[0], nil
+		}
+	}
+	return nil, scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeUniqueness,
+		Status:   http.StatusConflict,
+		Detail:   fmt.Sprintf("The externalId %q belongs to another user.", externalID),
+	}
+}
+
+// maxUsernameSuffix is the largest numeric suffix tried to find an available username.
+const maxUsernameSuffix = 100
+
+// resolveDeletedUserConflict applies the strategy configured in scim.deletedUserConflictStrategy if the username of
+// the new user belongs to a soft-deleted user who was not provisioned through SCIM. It returns the deleted user if
+// they are to be reclaimed instead of creating a new user.
+func resolveDeletedUserConflict(ctx context.Context, db database.DB, newUser *database.NewUser) (*types.User, error) {
+	strategy := conf.Get().ScimDeletedUserConflictStrategy
+	if strategy == "" || newUser.Username == "" {
+		return nil, nil
+	}
+
+	deletedUser, err := db.Users().GetDeletedNonSCIMByUsername(ctx, newUser.Username)
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case "reject":
+		return nil, scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeUniqueness,
+			Status:   http.StatusConflict,
+			Detail:   fmt.Sprintf("The username %q belongs to a deleted user.", newUser.Username),
+		}
+	case "suffix":
+		username, err := suffixedUsername(ctx, db, newUser.Username)
+		if err != nil {
+			return nil, err
+		}
+		newUser.Username = username
+		return nil, nil
+	case "reclaim":
+		return deletedUser, nil
+	}
+	return nil, nil
+}
+
+// suffixedUsername returns the given username with the smallest numeric suffix, e.g. alice-1, that is neither used by
+// a user or organization nor by a soft-deleted user.
+func suffixedUsername(ctx context.Context, db database.DB, username string) (string, error) {
+	// Usernames can't contain consecutive dashes or dots.
+	base := strings.TrimRight(username, "-.")
+	for i := 1; i <= maxUsernameSuffix; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if _, err := db.Namespaces().GetByName(ctx, candidate); err != database.ErrNamespaceNotFound {
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		if _, err := db.Users().GetDeletedNonSCIMByUsername(ctx, candidate); !errcode.IsNotFound(err) {
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		return candidate, nil
+	}
+	return "", scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeUniqueness,
+		Status:   http.StatusConflict,
+		Detail:   fmt.Sprintf("No username with a suffix is available for %q.", username),
+	}
+}
+
+// reclaimDeletedUser recovers the given soft-deleted user and updates them with the details of the new user, instead
+// of creating the new user. Only the account itself is reused: the previous owner's credentials, external accounts,
+// site admin status and non-default roles are not brought back.
+func reclaimDeletedUser(ctx context.Context, db database.DB, deletedUser *types.User, newUser database.NewUser, externalID optional.String) (*types.User, error) {
+	if err := db.Users().ReclaimDeleted(ctx, deletedUser.ID); err != nil {
+		return nil, err
+	}
+	if err := db.Users().Update(ctx, deletedUser.ID, database.UserUpdate{DisplayName: &newUser.DisplayName}); err != nil {
+		return nil, err
+	}
+
+	// Soft-deleting the user removed their email addresses.
+	var code *string
+	if !newUser.EmailIsVerified {
+		code = &newUser.EmailVerificationCode
+	}
+	if err := db.UserEmails().Add(ctx, deletedUser.ID, newUser.Email, code); err != nil {
+		return nil, err
+	}
+	if newUser.EmailIsVerified {
+		if err := db.UserEmails().SetVerified(ctx, deletedUser.ID, newUser.Email, true); err != nil {
+			return nil, err
+		}
+		if err := db.UserEmails().SetPrimaryEmail(ctx, deletedUser.ID, newUser.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if externalID.Present() {
+		if err := db.UserExternalAccounts().Insert(ctx, deletedUser.ID, scimAccountSpec(externalID.Value()), extsvc.AccountData{}); err != nil {
+			return nil, err
+		}
+	}
+
+	user := *deletedUser
+	user.DisplayName = newUser.DisplayName
+	return &user, nil
+}
+
+// assignDefaultRole assigns the role configured in scim.defaultRole, if any, to the user with the given ID.
+func assignDefaultRole(ctx context.Context, db database.DB, userID int32) error {
+	name := conf.Get().ScimDefaultRole
+	if name == "" {
+		return nil
+	}
+
+	role, err := db.Roles().Get(ctx, database.GetRoleOpts{Name: name})
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return errors.Newf("the role %q configured in scim.defaultRole does not exist", name)
+		}
+		return err
+	}
+	// 🚨 SECURITY: System roles are tied to the site admin status of users and to temporary admin access.
+	if role.System {
+		return errors.Newf("the role %q configured in scim.defaultRole is a system role", name)
+	}
+
+	_, err = db.UserRoles().Assign(ctx, database.AssignUserRoleOpts{UserID: userID, RoleID: role.ID})
+	return err
+}
+
+// emailsVerified returns whether email addresses provisioned through SCIM are marked as verified.
+// It defaults to true, i.e. trusting the identity provider to have verified them.
+func emailsVerified() bool {
+	verified := conf.Get().ScimMarkEmailsVerified
+	return verified == nil || *verified
+}
+
+// extractEmails extracts all email addresses from the given attributes.
+func extractEmails(attributes scim.ResourceAttributes) (emails []string) {
+	if attributes["emails"] == nil {
+		return
+	}
+	for _, emailRaw := range attributes["emails"].([]interface{}) {
+		if email, ok := emailRaw.(map[string]interface{})["value"].(string); ok && email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return
+}
+
 // extractPrimaryEmail extracts the primary email address from the given attributes.
 // Tries to get the (first) email address marked as primary, otherwise uses the first email address it finds.
 func extractPrimaryEmail(attributes scim.ResourceAttributes) (primaryEmail string) {
@@ -139,6 +419,22 @@ func extractDisplayName(attributes scim.ResourceAttributes) (displayName string)
 	return
 }
 
+// extractEnterpriseAttributes extracts the string attributes of the enterprise user extension from the given
+// attributes.
+func extractEnterpriseAttributes(attributes scim.ResourceAttributes) map[string]string {
+	enterpriseAttributes := map[string]string{}
+	extension, ok := attributes[enterpriseUserSchemaURN].(map[string]interface{})
+	if !ok {
+		return enterpriseAttributes
+	}
+	for name, value := range extension {
+		if value, ok := value.(string); ok && value != "" {
+			enterpriseAttributes[name] = value
+		}
+	}
+	return enterpriseAttributes
+}
+
 // containsDBError returns true if the given error contains at least one database.ErrCannotCreateUser.
 // It also returns the first such error.
 func containsDBError(err error) (database.ErrCannotCreateUser, bool) {
@@ -173,58 +469,116 @@ func getOptionalExternalID(attributes scim.ResourceAttributes) optional.String {
 
 // Get returns the resource corresponding with the given identifier.
 func (h *UserResourceHandler) Get(r *http.Request, idStr string) (scim.Resource, error) {
-	id, err := strconv.ParseInt(idStr, 10, 32)
+	user, err := h.getUser(r.Context(), idStr)
 	if err != nil {
-		return scim.Resource{}, errors.New("invalid id")
+		return scim.Resource{}, err
 	}
 
-	// Get users
-	users, err := h.db.Users().ListForSCIM(r.Context(), &database.UsersListOptions{
-		UserIDs: []int32{int32(id)},
-	})
+	resource := h.convertUserToSCIMResource(user)
+
+	return resource, nil
+}
+
+// getUser returns the user with the given SCIM resource ID, which is either the ID of the user or the opaque ID
+// assigned to them when scim.opaqueResourceIDs was enabled.
+func (h *UserResourceHandler) getUser(ctx context.Context, idStr string) (*types.UserForSCIM, error) {
+	users, err := h.db.Users().ListForSCIM(ctx, resourceIDListOptions(idStr))
 	if err != nil {
-		return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		return nil, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
 	}
 	if len(users) == 0 {
-		return scim.Resource{}, scimerrors.ScimErrorResourceNotFound(idStr)
+		return nil, scimerrors.ScimErrorResourceNotFound(idStr)
 	}
+	return users[0], nil
+}
 
-	resource := h.convertUserToSCIMResource(users[0])
-
-	return resource, nil
+// resourceIDListOptions returns the options to list the user with the given SCIM resource ID, which is either the ID
+// of the user or their opaque ID.
+func resourceIDListOptions(idStr string) *database.UsersListOptions {
+	if id, err := strconv.ParseInt(idStr, 10, 32); err == nil {
+		return &database.UsersListOptions{UserIDs: []int32{int32(id)}}
+	}
+	return &database.UsersListOptions{SCIMResourceID: idStr}
 }
 
 // GetAll returns a paginated list of resources.
 // An empty list of resources will be represented as `null` in the JSON response if `nil` is assigned to the
 // Page.Resources. Otherwise, if an empty slice is assigned, an empty list will be represented as `[]`.
+// Users are ordered by ID. Sequential pages can continue after the last user of the previous page instead of at the
+// start index, see afterIDFromRequest. Sorted lists are sorted before they are paginated, so that pages don't overlap.
 func (h *UserResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
 	var totalCount int
-	var resources []scim.Resource
+	resources := []scim.Resource{}
 	var err error
 
-	if params.Filter == nil {
-		totalCount, resources, err = h.getAllFromDB(r, params.StartIndex, &params.Count)
+	less, scimErr := userSortFromRequest(r)
+	if scimErr != nil {
+		return scim.Page{}, *scimErr
+	}
+	afterID, scimErr := h.afterIDFromRequest(r)
+	if scimErr != nil {
+		return scim.Page{}, *scimErr
+	}
+	if afterID != 0 && less != nil {
+		return scim.Page{}, scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeInvalidValue,
+			Detail:   "Continuing after a user is only supported for lists ordered by ID, not with sortBy.",
+			Status:   http.StatusBadRequest,
+		}
+	}
+
+	if params.Filter == nil && less == nil {
+		var users []*types.UserForSCIM
+		totalCount, users, err = h.getAllFromDB(r, params.StartIndex, &params.Count, afterID)
+		resources = make([]scim.Resource, 0, len(users))
+		for _, user := range users {
+			resources = append(resources, h.convertUserToSCIMResource(user))
+		}
 	} else {
-		extensionSchemas := make([]schema.Schema, 0, len(h.schemaExtensions))
-		for _, ext := range h.schemaExtensions {
-			extensionSchemas = append(extensionSchemas, ext.Schema)
+		var validator *filter.Validator
+		if params.Filter != nil {
+			if scimErr := checkFilterAttributes(params.Filter, h.coreSchema.ID, filterableAttributes); scimErr != nil {
+				return scim.Page{}, *scimErr
+			}
+
+			extensionSchemas := make([]schema.Schema, 0, len(h.schemaExtensions))
+			for _, ext := range h.schemaExtensions {
+				extensionSchemas = append(extensionSchemas, ext.Schema)
+			}
+			v := filter.NewFilterValidator(params.Filter, h.coreSchema, extensionSchemas...)
+			validator = &v
 		}
-		validator := filter.NewFilterValidator(params.Filter, h.coreSchema, extensionSchemas...)
 
-		// Fetch all resources from the DB and then filter them here.
+		// Fetch all users from the DB and then filter and sort them here.
 		// This doesn't feel efficient, but it wasn't reasonable to implement this in SQL in the time available.
-		var allResources []scim.Resource
-		_, allResources, err = h.getAllFromDB(r, 0, nil)
+		var allUsers []*types.UserForSCIM
+		_, allUsers, err = h.getAllFromDB(r, 0, nil, 0)
 
-		for _, resource := range allResources {
-			if err := validator.PassesFilter(resource.Attributes); err != nil {
+		var matches []*types.UserForSCIM
+		for _, user := range allUsers {
+			if validator != nil && validator.PassesFilter(h.convertUserToSCIMResource(user).Attributes) != nil {
 				continue
 			}
+			matches = append(matches, user)
+		}
+		if less != nil {
+			sortUsers(matches, less)
+		}
 
-			totalCount++
-			if totalCount >= params.StartIndex && len(resources) < params.Count {
-				resources = append(resources, resource)
+		// Every match counts towards the total, not just the ones on the requested page.
+		totalCount = len(matches)
+		for i, user := range matches {
+			if afterID != 0 {
+				if user.ID <= afterID {
+					continue
+				}
+			} else if i+1 < params.StartIndex {
+				continue
 			}
+			if len(resources) == params.Count {
+				break
+			}
+			resources = append(resources, h.convertUserToSCIMResource(user))
 		}
 	}
 	if err != nil {
@@ -237,28 +591,82 @@ func (h *UserResourceHandler) GetAll(r *http.Request, params scim.ListRequestPar
 	}, nil
 }
 
-func (h *UserResourceHandler) getAllFromDB(r *http.Request, startIndex int, count *int) (totalCount int, resources []scim.Resource, err error) {
+// filterableAttributes are the (lowercase) names of the user attributes that filters can be applied to, i.e. the
+// attributes that convertUserToSCIMResource populates.
+var filterableAttributes = map[string]struct{}{
+	"username":    {},
+	"externalid":  {},
+	"name":        {},
+	"displayname": {},
+	"emails":      {},
+	"active":      {},
+}
+
+// checkFilterAttributes returns an invalidFilter error naming the first attribute in the filter that isn't one of the
+// given filterable attributes of the core schema with the given ID, or nil if all attributes are supported.
+func checkFilterAttributes(expr scimfilter.Expression, coreSchemaID string, filterable map[string]struct{}) *scimerrors.ScimError {
+	var unsupported string
+	var walk func(scimfilter.Expression)
+	check := func(path scimfilter.AttributePath) {
+		if unsupported != "" {
+			return
+		}
+		if uri := path.URI(); uri != "" && uri != coreSchemaID {
+			unsupported = path.String()
+			return
+		}
+		if _, ok := filterable[strings.ToLower(path.AttributeName)]; !ok {
+			unsupported = path.String()
+		}
+	}
+	walk = func(expr scimfilter.Expression) {
+		switch e := expr.(type) {
+		case *scimfilter.AttributeExpression:
+			check(e.AttributePath)
+		case *scimfilter.ValuePath:
+			check(e.AttributePath)
+		case *scimfilter.LogicalExpression:
+			walk(e.Left)
+			walk(e.Right)
+		case *scimfilter.NotExpression:
+			walk(e.Expression)
+		}
+	}
+	walk(expr)
+
+	if unsupported == "" {
+		return nil
+	}
+	return &scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeInvalidFilter,
+		Detail:   fmt.Sprintf("Filtering by the attribute %q is not supported.", unsupported),
+		Status:   http.StatusBadRequest,
+	}
+}
+
+func (h *UserResourceHandler) getAllFromDB(r *http.Request, startIndex int, count *int, afterID int32) (totalCount int, users []*types.UserForSCIM, err error) {
 	// Calculate offset
 	var offset int
 	if startIndex > 0 {
 		offset = startIndex - 1
 	}
 
-	// Get users and convert them to SCIM resources
+	// Get users
 	var opt = &database.UsersListOptions{}
 	if count != nil {
 		opt = &database.UsersListOptions{
 			LimitOffset: &database.LimitOffset{Limit: *count, Offset: offset},
 		}
+		// With keyset pagination, the position is given by the ID to continue after rather than the offset.
+		if afterID != 0 {
+			opt.AfterID = afterID
+			opt.LimitOffset.Offset = 0
+		}
 	}
-	users, err := h.db.Users().ListForSCIM(r.Context(), opt)
+	users, err = h.db.Users().ListForSCIM(r.Context(), opt)
 	if err != nil {
 		return
 	}
-	resources = make([]scim.Resource, 0, len(users))
-	for _, user := range users {
-		resources = append(resources, h.convertUserToSCIMResource(user))
-	}
 
 	// Get total count
 	if count == nil {
@@ -287,23 +695,43 @@ func (h *UserResourceHandler) convertUserToSCIMResource(user *types.UserForSCIM)
 		emailMap = append(emailMap, map[string]interface{}{"value": email})
 	}
 
+	attributes := scim.ResourceAttributes{
+		"userName":   user.Username,
+		"externalId": user.SCIMExternalID,
+		"name": map[string]interface{}{
+			"givenName":  firstName,
+			"middleName": middleName,
+			"familyName": lastName,
+			"formatted":  user.DisplayName,
+		},
+		"displayName": user.DisplayName,
+		"emails":      emailMap,
+		"active":      true,
+	}
+
+	// Convert enterprise extension attributes
+	if len(user.SCIMEnterpriseAttributes) > 0 {
+		extension := make(map[string]interface{}, len(user.SCIMEnterpriseAttributes))
+		for name, value := range user.SCIMEnterpriseAttributes {
+			extension[name] = value
+		}
+		attributes[enterpriseUserSchemaURN] = extension
+	}
+
 	return scim.Resource{
-		ID:         strconv.FormatInt(int64(user.ID), 10),
+		ID:         userResourceID(user),
 		ExternalID: externalIDOptional,
-		Attributes: scim.ResourceAttributes{
-			"userName":   user.Username,
-			"externalId": user.SCIMExternalID,
-			"name": map[string]interface{}{
-				"givenName":  firstName,
-				"middleName": middleName,
-				"familyName": lastName,
-				"formatted":  user.DisplayName,
-			},
-			"displayName": user.DisplayName,
-			"emails":      emailMap,
-			"active":      true,
-		},
+		Attributes: attributes,
+	}
+}
+
+// userResourceID returns the SCIM resource ID of the given user.
+func userResourceID(user *types.UserForSCIM) string {
+	// Users that were assigned an opaque ID keep it, even if scim.opaqueResourceIDs was disabled since.
+	if user.SCIMResourceID != "" {
+		return user.SCIMResourceID
 	}
+	return strconv.FormatInt(int64(user.ID), 10)
 }
 
 // displayNameToPieces splits a display name into first, middle, and last name.
@@ -323,42 +751,273 @@ func displayNameToPieces(displayName string) (first, middle, last string) {
 
 // Replace replaces ALL existing attributes of the resource with given identifier. Given attributes that are empty
 // are to be deleted. Returns a resource with the attributes that are stored.
-func (h *UserResourceHandler) Replace(r *http.Request, id string, attributes scim.ResourceAttributes) (scim.Resource, error) {
-	// TODO: Add real logic
-	attributesString := resourceAttributesToLoggableString(attributes)
-	h.observationCtx.Logger.Error("XXXXX Replace", log.String("method", r.Method), log.String("id", id), log.String("attributes", attributesString))
+func (h *UserResourceHandler) Replace(r *http.Request, idStr string, attributes scim.ResourceAttributes) (scim.Resource, error) {
+	user, err := h.getUser(r.Context(), idStr)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	userID := user.ID
+
+	if isDryRun() {
+		if err := h.logPlannedUpdate(r.Context(), "replace user", user, attributes); err != nil {
+			return scim.Resource{}, err
+		}
+		return plannedUserResource(user, attributes), nil
+	}
+
+	if err := h.updateUser(r.Context(), user, attributes); err != nil {
+		return scim.Resource{}, err
+	}
 
+	verified := emailsVerified()
+	for _, email := range extractEmails(attributes) {
+		if err := h.addEmail(r.Context(), userID, email, verified); err != nil {
+			return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+	}
+	// Only verified email addresses can be primary, so unverified ones stay secondary until the user verifies them.
+	if primaryEmail := extractPrimaryEmail(attributes); primaryEmail != "" && verified {
+		if err := h.db.UserEmails().SetPrimaryEmail(r.Context(), userID, primaryEmail); err != nil {
+			return scim.Resource{}, scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+		}
+	}
+
+	return h.Get(r, idStr)
+}
+
+// logPlannedUpdate logs the changes that updating the user with the given attributes would make while scim.dryRun is
+// enabled. Like the update, it fails if the new username is taken.
+func (h *UserResourceHandler) logPlannedUpdate(ctx context.Context, change string, user *types.UserForSCIM, attributes scim.ResourceAttributes) error {
+	username := extractUsername(attributes)
+	if username != "" && username != user.Username {
+		if err := checkUsernameAvailable(ctx, h.db, user.ID, username); err != nil {
+			return err
+		}
+	} else {
+		username = user.Username
+	}
+	logDryRun(h.observationCtx.Logger, change,
+		log.Int32("userID", user.ID),
+		log.String("username", username),
+		log.String("displayName", extractDisplayName(attributes)),
+		log.Strings("emails", extractEmails(attributes)))
+	return nil
+}
+
+// plannedUserResource returns the resource that updating the user with the given attributes would result in.
+func plannedUserResource(user *types.UserForSCIM, attributes scim.ResourceAttributes) scim.Resource {
 	return scim.Resource{
-		ID: "123",
-	}, nil
+		ID:         userResourceID(user),
+		ExternalID: getOptionalExternalID(attributes),
+		Attributes: attributes,
+	}
+}
+
+// updateUser stores the display name, username, external ID and enterprise attributes given in the attributes for
+// the user. Email addresses are left unchanged.
+func (h *UserResourceHandler) updateUser(ctx context.Context, user *types.UserForSCIM, attributes scim.ResourceAttributes) error {
+	userID := user.ID
+
+	displayName := extractDisplayName(attributes)
+	update := database.UserUpdate{DisplayName: &displayName}
+	username := extractUsername(attributes)
+	renamed := username != "" && username != user.Username
+	if renamed {
+		if err := checkUsernameAvailable(ctx, h.db, userID, username); err != nil {
+			return err
+		}
+		update.Username = username
+	}
+
+	// Rename the user and invalidate their sessions atomically, so that a failed rename doesn't sign them out.
+	err := h.db.WithTransact(ctx, func(tx database.DB) error {
+		if err := tx.Users().Update(ctx, userID, update); err != nil {
+			return err
+		}
+		// Replacing the resource also removes the enterprise attributes that are no longer given.
+		if err := tx.Users().SetSCIMEnterpriseAttributes(ctx, userID, extractEnterpriseAttributes(attributes)); err != nil {
+			return err
+		}
+		if externalID := getOptionalExternalID(attributes); externalID.Present() && externalID.Value() != user.SCIMExternalID {
+			if err := updateSCIMExternalID(ctx, tx, userID, externalID.Value()); err != nil {
+				return err
+			}
+		}
+		if renamed && conf.Get().ScimInvalidateSessionsOnRename {
+			return tx.Users().InvalidateSessionsByID(ctx, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) {
+			return scimErr
+		}
+		if database.IsUsernameExists(err) {
+			return usernameTakenError(username)
+		}
+		return scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	return nil
+}
+
+// updateSCIMExternalID links the user to the given SCIM external ID instead of their current one. An empty external
+// ID removes the link. It returns a uniqueness error if the external ID belongs to another user.
+func updateSCIMExternalID(ctx context.Context, db database.DB, userID int32, externalID string) error {
+	if externalID == "" {
+		return db.UserExternalAccounts().Delete(ctx, database.ExternalAccountsDeleteOptions{UserID: userID, ServiceType: "scim"})
+	}
+
+	spec := scimAccountSpec(externalID)
+	existing, err := db.UserExternalAccounts().List(ctx, database.ExternalAccountsListOptions{
+		ServiceType: spec.ServiceType,
+		ServiceID:   spec.ServiceID,
+		AccountID:   spec.AccountID,
+		LimitOffset: &database.LimitOffset{Limit: 1},
+	})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && existing[0].UserID != userID {
+		return scimerrors.ScimError{
+			ScimType: scimerrors.ScimTypeUniqueness,
+			Status:   http.StatusConflict,
+			Detail:   fmt.Sprintf("The externalId %q belongs to another user.", externalID),
+		}
+	}
+
+	current, err := db.UserExternalAccounts().List(ctx, database.ExternalAccountsListOptions{
+		UserID:      userID,
+		ServiceType: spec.ServiceType,
+		LimitOffset: &database.LimitOffset{Limit: 1},
+	})
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 {
+		return db.UserExternalAccounts().Insert(ctx, userID, spec, extsvc.AccountData{})
+	}
+	return db.UserExternalAccounts().UpdateAccountID(ctx, current[0].ID, externalID)
+}
+
+// checkUsernameAvailable returns a 409 error if the given username is already taken by a user other than the one
+// with the given ID, or by an organization, which share the same namespace. New users pass 0 as their ID.
+func checkUsernameAvailable(ctx context.Context, db database.DB, userID int32, username string) error {
+	if username == "" {
+		return nil
+	}
+	namespace, err := db.Namespaces().GetByName(ctx, username)
+	if errors.Is(err, database.ErrNamespaceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
+	// Changing the case of the user's own username is not a collision.
+	if userID != 0 && namespace.User == userID {
+		return nil
+	}
+	return usernameTakenError(username)
+}
+
+func usernameTakenError(username string) scimerrors.ScimError {
+	return scimerrors.ScimError{
+		ScimType: scimerrors.ScimTypeUniqueness,
+		Status:   http.StatusConflict,
+		Detail:   fmt.Sprintf("The username %q is already in use.", username),
+	}
+}
+
+// addEmail adds the given email address to the user, unless they already have it. Addresses that are added as
+// verified are also marked as verified if the user already had them unverified.
+func (h *UserResourceHandler) addEmail(ctx context.Context, userID int32, email string, verified bool) error {
+	_, alreadyVerified, err := h.db.UserEmails().Get(ctx, userID, email)
+	if err != nil && !errcode.IsNotFound(err) {
+		return err
+	}
+	if err != nil {
+		var code *string
+		if !verified {
+			c, err := backend.MakeEmailVerificationCode()
+			if err != nil {
+				return err
+			}
+			code = &c
+		}
+		if err := h.db.UserEmails().Add(ctx, userID, email, code); err != nil {
+			return err
+		}
+	}
+	if verified && !alreadyVerified {
+		return h.db.UserEmails().SetVerified(ctx, userID, email, true)
+	}
+	return nil
 }
 
 // Delete removes the resource with corresponding ID.
+// The user is soft-deleted, or permanently deleted if scim.hardDeleteUsers is enabled. Deleting a user that was
+// already soft-deleted succeeds without changing anything.
 func (h *UserResourceHandler) Delete(r *http.Request, id string) error {
-	// TODO: Add real logic
-	h.observationCtx.Logger.Error("XXXXX Delete", log.String("method", r.Method), log.String("id", id))
+	user, err := h.getUser(r.Context(), id)
+	if err != nil {
+		var scimErr scimerrors.ScimError
+		if errors.As(err, &scimErr) && scimErr.Status == http.StatusNotFound {
+			if deleted, lookupErr := h.isDeletedUser(r.Context(), id); lookupErr == nil && deleted {
+				return nil
+			}
+		}
+		return err
+	}
+
+	if isDryRun() {
+		h.logPlannedDelete(user)
+		return nil
+	}
 
+	err = h.db.WithTransact(r.Context(), func(tx database.DB) error {
+		return deleteUser(r.Context(), tx, user.ID)
+	})
+	if err != nil {
+		return scimerrors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()}
+	}
 	return nil
 }
 
-// Patch update one or more attributes of a SCIM resource using a sequence of
-// operations to "add", "remove", or "replace" values.
-// If you return no Resource.Attributes, a 204 No Content status code will be returned.
-// This case is only valid in the following scenarios:
-// 1. the Add/Replace operation should return No Content only when the value already exists AND is the same.
-// 2. the Remove operation should return No Content when the value to be removed is already absent.
-// More information in Section 3.5.2 of RFC 7644: https://tools.ietf.org/html/rfc7644#section-3.5.2
-func (h *UserResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
-	var operationsString string
-	for _, operation := range operations {
-		operationsString += operation.Op + ": " + operation.Path.AttributePath.AttributeName + ", "
+// logPlannedDelete logs the deletion of the user while scim.dryRun is enabled.
+func (h *UserResourceHandler) logPlannedDelete(user *types.UserForSCIM) {
+	logDryRun(h.observationCtx.Logger, "delete user",
+		log.Int32("userID", user.ID),
+		log.String("username", user.Username),
+		log.Bool("hardDelete", conf.Get().ScimHardDeleteUsers))
+}
+
+// deleteUser signs the user out, removes their SCIM external account and deletes them. It is used both for DELETE
+// requests and for PATCH requests that deactivate the user, and is meant to be called within a transaction.
+func deleteUser(ctx context.Context, tx database.DB, userID int32) error {
+	// Sign the user out everywhere.
+	if err := tx.Users().InvalidateSessionsByID(ctx, userID); err != nil {
+		return err
+	}
+	// Remove the mapping to the SCIM external ID, so that the user can be provisioned again.
+	if err := tx.UserExternalAccounts().Delete(ctx, database.ExternalAccountsDeleteOptions{UserID: userID, ServiceType: "scim"}); err != nil {
+		return err
+	}
+	// Deleting the user also revokes their access tokens.
+	if conf.Get().ScimHardDeleteUsers {
+		return tx.Users().HardDelete(ctx, userID)
 	}
-	// TODO: Add real logic
-	h.observationCtx.Logger.Error("XXXXX Patch", log.String("method", r.Method), log.String("id", id), log.String("operations", operationsString))
+	return tx.Users().Delete(ctx, userID)
+}
 
-	return scim.Resource{
-		ID: "123",
-	}, nil
+// isDeletedUser returns true if the user with the given SCIM resource ID exists, but was soft-deleted.
+func (h *UserResourceHandler) isDeletedUser(ctx context.Context, idStr string) (bool, error) {
+	opt := resourceIDListOptions(idStr)
+	opt.IncludeDeleted = true
+	users, err := h.db.Users().ListForSCIM(ctx, opt)
+	if err != nil {
+		return false, err
+	}
+	return len(users) > 0, nil
 }
 
 // createUserResourceType creates a SCIM resource type for users.
@@ -440,7 +1099,7 @@ func createCoreSchema() schema.Schema {
 // createSchemaExtensions creates a SCIM schema extension for users.
 func createSchemaExtensions() []scim.SchemaExtension {
 	extensionUserSchema := schema.Schema{
-		ID:          "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User",
+		ID:          enterpriseUserSchemaURN,
 		Name:        optional.NewString("EnterpriseUser"),
 		Description: optional.NewString("Enterprise User"),
 		Attributes: []schema.CoreAttribute{
@@ -450,6 +1109,15 @@ func createSchemaExtensions() []scim.SchemaExtension {
 			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
 				Name: "organization",
 			})),
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name: "division",
+			})),
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name: "department",
+			})),
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name: "costCenter",
+			})),
 		},
 	}
 
@@ -3,14 +3,23 @@ package scim
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"strconv"
 	"testing"
 
+	mockassert "github.com/derision-test/go-mockgen/testutil/assert"
 	"github.com/elimity-com/scim"
+	scimerrors "github.com/elimity-com/scim/errors"
+	"github.com/google/uuid"
 	"github.com/scim2/filter-parser/v2"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +48,937 @@ func TestUserResourceHandler_Create(t *testing.T) {
 	assert.Equal(t, "5", user.ID)
 }
 
+func TestUserResourceHandler_Create_EmailVerification(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		markVerified *bool
+		wantVerified bool
+	}{
+		{name: "default", markVerified: nil, wantVerified: true},
+		{name: "trusted", markVerified: boolPtr(true), wantVerified: true},
+		{name: "untrusted", markVerified: boolPtr(false), wantVerified: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimMarkEmailsVerified: tc.markVerified}})
+			t.Cleanup(func() { conf.Mock(nil) })
+
+			db := getMockDB()
+			var gotNewUser database.NewUser
+			db.Users().(*database.MockUserStore).CreateFunc.SetDefaultHook(func(ctx context.Context, user database.NewUser) (*types.User, error) {
+				gotNewUser = user
+				return &types.User{ID: 5, Username: user.Username, DisplayName: user.DisplayName}, nil
+			})
+			userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+			_, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+				"userName": "user1",
+				"emails": []interface{}{
+					map[string]interface{}{"value": "a@b.c", "primary": true},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.wantVerified, gotNewUser.EmailIsVerified)
+			// Unverified emails need a verification code so that the user can verify them.
+			assert.Equal(t, !tc.wantVerified, gotNewUser.EmailVerificationCode != "")
+		})
+	}
+}
+
+func TestUserResourceHandler_Create_DefaultRole(t *testing.T) {
+	attributes := scim.ResourceAttributes{
+		"userName": "user1",
+		"emails": []interface{}{
+			map[string]interface{}{"value": "a@b.c", "primary": true},
+		},
+	}
+
+	t.Run("no default role", func(t *testing.T) {
+		db := getMockDB()
+		roles := database.NewMockRoleStore()
+		db.RolesFunc.SetDefaultReturn(roles)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mockassert.NotCalled(t, roles.GetFunc)
+	})
+
+	t.Run("existing role", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimDefaultRole: "PROVISIONED"}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		db := getMockDB()
+		roles := database.NewMockRoleStore()
+		roles.GetFunc.SetDefaultReturn(&types.Role{ID: 7, Name: "PROVISIONED"}, nil)
+		db.RolesFunc.SetDefaultReturn(roles)
+		userRoles := database.NewMockUserRoleStore()
+		db.UserRolesFunc.SetDefaultReturn(userRoles)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mockassert.CalledOnceWith(t, roles.GetFunc, mockassert.Values(mockassert.Skip, database.GetRoleOpts{Name: "PROVISIONED"}))
+		mockassert.CalledOnceWith(t, userRoles.AssignFunc, mockassert.Values(mockassert.Skip, database.AssignUserRoleOpts{UserID: 5, RoleID: 7}))
+	})
+
+	t.Run("missing role", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimDefaultRole: "MISSING"}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		db := getMockDB()
+		roles := database.NewMockRoleStore()
+		roles.GetFunc.SetDefaultReturn(nil, &database.RoleNotFoundErr{})
+		db.RolesFunc.SetDefaultReturn(roles)
+		userRoles := database.NewMockUserRoleStore()
+		db.UserRolesFunc.SetDefaultReturn(userRoles)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, attributes)
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusInternalServerError, scimErr.Status)
+		assert.Contains(t, scimErr.Detail, `the role "MISSING" configured in scim.defaultRole does not exist`)
+		mockassert.NotCalled(t, userRoles.AssignFunc)
+	})
+
+	t.Run("system role", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimDefaultRole: string(types.SiteAdministratorSystemRole)}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		db := getMockDB()
+		roles := database.NewMockRoleStore()
+		roles.GetFunc.SetDefaultReturn(&types.Role{ID: 2, Name: string(types.SiteAdministratorSystemRole), System: true}, nil)
+		db.RolesFunc.SetDefaultReturn(roles)
+		userRoles := database.NewMockUserRoleStore()
+		db.UserRolesFunc.SetDefaultReturn(userRoles)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, attributes)
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Contains(t, scimErr.Detail, `the role "SITE_ADMINISTRATOR" configured in scim.defaultRole is a system role`)
+		mockassert.NotCalled(t, userRoles.AssignFunc)
+	})
+}
+
+func TestUserResourceHandler_Create_DeletedUserConflict(t *testing.T) {
+	attributes := scim.ResourceAttributes{
+		"userName":   "alice",
+		"externalId": "ALICE",
+		"emails": []interface{}{
+			map[string]interface{}{"value": "alice@example.com", "primary": true},
+		},
+	}
+	// alice and alice-1 are soft-deleted local users.
+	getMockDBWithDeletedUsers := func() *database.MockDB {
+		db := getMockDB()
+		db.Users().(*database.MockUserStore).GetDeletedNonSCIMByUsernameFunc.SetDefaultHook(func(ctx context.Context, username string) (*types.User, error) {
+			if username == "alice" || username == "alice-1" {
+				return &types.User{ID: 10, Username: username}, nil
+			}
+			return nil, &errcode.Mock{IsNotFound: true}
+		})
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+		db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc.SetDefaultHook(func(ctx context.Context, newUser database.NewUser, _ extsvc.AccountSpec, _ extsvc.AccountData) (*types.User, error) {
+			return &types.User{ID: 5, Username: newUser.Username}, nil
+		})
+		return db
+	}
+	mockStrategy := func(t *testing.T, strategy string) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimDeletedUserConflictStrategy: strategy}})
+		t.Cleanup(func() { conf.Mock(nil) })
+	}
+
+	t.Run("no strategy", func(t *testing.T) {
+		db := getMockDBWithDeletedUsers()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		if _, err := userResourceHandler.Create(&http.Request{}, attributes); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		mockassert.NotCalled(t, users.GetDeletedNonSCIMByUsernameFunc)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		mockStrategy(t, "reject")
+		db := getMockDBWithDeletedUsers()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, attributes)
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusConflict, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeUniqueness, scimErr.ScimType)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		mockStrategy(t, "suffix")
+		db := getMockDBWithDeletedUsers()
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		user, err := userResourceHandler.Create(&http.Request{}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// alice-1 also belongs to a deleted user.
+		if calls := externalAccounts.CreateUserAndSaveFunc.History(); assert.Len(t, calls, 1) {
+			assert.Equal(t, "alice-2", calls[0].Arg1.Username)
+		}
+		// The IdP learns the username that was actually stored.
+		assert.Equal(t, "alice-2", user.Attributes["userName"])
+		assert.Equal(t, "alice", attributes["userName"])
+		mockassert.NotCalled(t, db.Users().(*database.MockUserStore).ReclaimDeletedFunc)
+	})
+
+	t.Run("reclaim", func(t *testing.T) {
+		mockStrategy(t, "reclaim")
+		db := getMockDBWithDeletedUsers()
+		// The deleted user isn't part of the mock's user list.
+		db.Users().(*database.MockUserStore).UpdateFunc.SetDefaultReturn(nil)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		user, err := userResourceHandler.Create(&http.Request{}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The deleted user is recovered and linked instead of creating a new user.
+		assert.Equal(t, "10", user.ID)
+		users := db.Users().(*database.MockUserStore)
+		mockassert.CalledOnceWith(t, users.ReclaimDeletedFunc, mockassert.Values(mockassert.Skip, int32(10)))
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		mockassert.NotCalled(t, externalAccounts.CreateUserAndSaveFunc)
+		mockassert.CalledOnceWith(t, externalAccounts.InsertFunc, mockassert.Values(mockassert.Skip, int32(10), scimAccountSpec("ALICE")))
+		userEmails := db.UserEmails().(*database.MockUserEmailsStore)
+		mockassert.CalledOnceWith(t, userEmails.AddFunc, mockassert.Values(mockassert.Skip, int32(10), "alice@example.com"))
+		mockassert.CalledOnceWith(t, userEmails.SetPrimaryEmailFunc, mockassert.Values(mockassert.Skip, int32(10), "alice@example.com"))
+	})
+}
+
+func TestUserResourceHandler_Create_Conflicts(t *testing.T) {
+	// user1 is linked to the IdP user "external1".
+	getMockDBWithExternalAccounts := func() *database.MockDB {
+		db := getMockDB()
+		db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).ListFunc.SetDefaultHook(func(ctx context.Context, opt database.ExternalAccountsListOptions) ([]*extsvc.Account, error) {
+			if opt.ServiceType == "scim" && opt.AccountID == "external1" {
+				return []*extsvc.Account{{UserID: 1, AccountSpec: scimAccountSpec("external1")}}, nil
+			}
+			return nil, nil
+		})
+		db.Namespaces().(*database.MockNamespaceStore).GetByNameFunc.SetDefaultHook(func(ctx context.Context, name string) (*database.Namespace, error) {
+			if name == "user1" {
+				return &database.Namespace{Name: name, User: 1}, nil
+			}
+			return nil, database.ErrNamespaceNotFound
+		})
+		return db
+	}
+	assertConflict := func(t *testing.T, err error) {
+		t.Helper()
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusConflict, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeUniqueness, scimErr.ScimType)
+	}
+
+	t.Run("duplicate externalId", func(t *testing.T) {
+		db := getMockDBWithExternalAccounts()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+			"userName":   "user5",
+			"externalId": "external1",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "e@example.com", "primary": true},
+			},
+		})
+
+		assertConflict(t, err)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	})
+
+	t.Run("duplicate userName", func(t *testing.T) {
+		db := getMockDBWithExternalAccounts()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+			"userName":   "user1",
+			"externalId": "external5",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "e@example.com", "primary": true},
+			},
+		})
+
+		assertConflict(t, err)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	})
+
+	t.Run("userName of an organization", func(t *testing.T) {
+		db := getMockDBWithExternalAccounts()
+		db.Namespaces().(*database.MockNamespaceStore).GetByNameFunc.SetDefaultReturn(&database.Namespace{Name: "engineering", Organization: 1}, nil)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+			"userName":   "engineering",
+			"externalId": "external5",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "e@example.com", "primary": true},
+			},
+		})
+
+		assertConflict(t, err)
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	})
+
+	t.Run("retried create", func(t *testing.T) {
+		db := getMockDBWithExternalAccounts()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		user, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+			"userName":   "user1",
+			"externalId": "external1",
+			"emails": []interface{}{
+				map[string]interface{}{"value": "A@example.com", "primary": true},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The existing user is returned instead of creating a new one.
+		assert.Equal(t, "1", user.ID)
+		assert.Equal(t, "user1", user.Attributes["userName"])
+		mockassert.NotCalled(t, db.UserExternalAccounts().(*database.MockUserExternalAccountsStore).CreateUserAndSaveFunc)
+	})
+}
+
+func TestUserResourceHandler_Create_OpaqueResourceID(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimOpaqueResourceIDs: true}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	db := getMockDB()
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+	created, err := userResourceHandler.Create(&http.Request{}, scim.ResourceAttributes{
+		"userName": "user5",
+		"emails": []interface{}{
+			map[string]interface{}{"value": "e@example.com", "primary": true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ID is opaque rather than the user ID
+	assert.NotEqual(t, "5", created.ID)
+	_, err = uuid.Parse(created.ID)
+	assert.NoError(t, err)
+
+	t.Run("get by opaque ID", func(t *testing.T) {
+		user, err := userResourceHandler.Get(&http.Request{}, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, created.ID, user.ID)
+		assert.Equal(t, "user5", user.Attributes["userName"])
+	})
+
+	t.Run("get by user ID", func(t *testing.T) {
+		user, err := userResourceHandler.Get(&http.Request{}, "5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, created.ID, user.ID)
+	})
+
+	t.Run("existing users keep their user ID", func(t *testing.T) {
+		user, err := userResourceHandler.Get(&http.Request{}, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "1", user.ID)
+	})
+
+	t.Run("unknown opaque ID", func(t *testing.T) {
+		_, err := userResourceHandler.Get(&http.Request{}, uuid.New().String())
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusNotFound, scimErr.Status)
+	})
+}
+
+func TestUserResourceHandler_Replace_EmailVerification(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		markVerified bool
+	}{
+		{name: "trusted", markVerified: true},
+		{name: "untrusted", markVerified: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimMarkEmailsVerified: &tc.markVerified}})
+			t.Cleanup(func() { conf.Mock(nil) })
+
+			db := getMockDB()
+			db.Users().(*database.MockUserStore).UpdateFunc.SetDefaultReturn(nil)
+			userEmails := database.NewMockUserEmailsStore()
+			userEmails.GetFunc.SetDefaultReturn("", false, &errcode.Mock{IsNotFound: true})
+			db.UserEmailsFunc.SetDefaultReturn(userEmails)
+
+			userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+			_, err := userResourceHandler.Replace(&http.Request{}, "1", scim.ResourceAttributes{
+				"userName": "user1",
+				"emails": []interface{}{
+					map[string]interface{}{"value": "new@example.com", "primary": true},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			addCalls := userEmails.AddFunc.History()
+			if assert.Len(t, addCalls, 1) {
+				assert.Equal(t, "new@example.com", addCalls[0].Arg2)
+				// Unverified emails need a verification code so that the user can verify them.
+				assert.Equal(t, !tc.markVerified, addCalls[0].Arg3 != nil)
+			}
+			if tc.markVerified {
+				mockassert.CalledOnceWith(t, userEmails.SetVerifiedFunc, mockassert.Values(mockassert.Skip, int32(1), "new@example.com", true))
+				mockassert.CalledOnceWith(t, userEmails.SetPrimaryEmailFunc, mockassert.Values(mockassert.Skip, int32(1), "new@example.com"))
+			} else {
+				mockassert.NotCalled(t, userEmails.SetVerifiedFunc)
+				mockassert.NotCalled(t, userEmails.SetPrimaryEmailFunc)
+			}
+		})
+	}
+}
+
+func TestUserResourceHandler_Replace_Username(t *testing.T) {
+	replace := func(t *testing.T, db *database.MockDB, username string) error {
+		t.Helper()
+		userEmails := database.NewMockUserEmailsStore()
+		db.UserEmailsFunc.SetDefaultReturn(userEmails)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+		_, err := userResourceHandler.Replace(&http.Request{}, "1", scim.ResourceAttributes{"userName": username})
+		return err
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		db := getMockDB()
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+
+		if err := replace(t, db, "renamed"); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		if assert.Len(t, users.UpdateFunc.History(), 1) {
+			assert.Equal(t, "renamed", users.UpdateFunc.History()[0].Arg2.Username)
+		}
+		// Sessions are preserved by default.
+		mockassert.NotCalled(t, users.InvalidateSessionsByIDFunc)
+	})
+
+	t.Run("unchanged username", func(t *testing.T) {
+		db := getMockDB()
+
+		if err := replace(t, db, "user1"); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		if assert.Len(t, users.UpdateFunc.History(), 1) {
+			assert.Equal(t, "", users.UpdateFunc.History()[0].Arg2.Username)
+		}
+		mockassert.NotCalled(t, db.NamespacesFunc)
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		db := getMockDB()
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(&database.Namespace{Name: "user2", User: 2}, nil)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+
+		err := replace(t, db, "user2")
+
+		var scimErr scimerrors.ScimError
+		if assert.True(t, errors.As(err, &scimErr)) {
+			assert.Equal(t, http.StatusConflict, scimErr.Status)
+			assert.Equal(t, scimerrors.ScimTypeUniqueness, scimErr.ScimType)
+		}
+		mockassert.NotCalled(t, db.Users().(*database.MockUserStore).UpdateFunc)
+	})
+
+	t.Run("invalidate sessions", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimInvalidateSessionsOnRename: true}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		db := getMockDB()
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+
+		if err := replace(t, db, "renamed"); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		mockassert.CalledOnceWith(t, users.InvalidateSessionsByIDFunc, mockassert.Values(mockassert.Skip, int32(1)))
+
+		// Sessions are not invalidated if the username doesn't change.
+		if err := replace(t, db, "renamed"); err != nil {
+			t.Fatal(err)
+		}
+		mockassert.CalledOnce(t, users.InvalidateSessionsByIDFunc)
+	})
+}
+
+func TestUserResourceHandler_Patch_EnterpriseAttributes(t *testing.T) {
+	db := getMockDB()
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+	parsePath := func(t *testing.T, raw string) *filter.Path {
+		t.Helper()
+		path, err := filter.ParsePath([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &path
+	}
+	departmentPath := enterpriseUserSchemaURN + ":department"
+
+	t.Run("add department", func(t *testing.T) {
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationAdd, Path: parsePath(t, departmentPath), Value: "Engineering"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[string]interface{}{"department": "Engineering"}, user.Attributes[enterpriseUserSchemaURN])
+	})
+
+	t.Run("replace department without path", func(t *testing.T) {
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Value: map[string]interface{}{
+				departmentPath:                        "Sales",
+				enterpriseUserSchemaURN + ":division": "EMEA",
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[string]interface{}{"department": "Sales", "division": "EMEA"}, user.Attributes[enterpriseUserSchemaURN])
+	})
+
+	t.Run("remove department", func(t *testing.T) {
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationRemove, Path: parsePath(t, departmentPath)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, map[string]interface{}{"division": "EMEA"}, user.Attributes[enterpriseUserSchemaURN])
+	})
+
+	t.Run("unsupported path", func(t *testing.T) {
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "nickName"), Value: "Nick"},
+		})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeInvalidPath, scimErr.ScimType)
+
+		// Failed patches don't change the user.
+		user, err := userResourceHandler.Get(&http.Request{}, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "First Last", user.Attributes["displayName"])
+		assert.Equal(t, map[string]interface{}{"division": "EMEA"}, user.Attributes[enterpriseUserSchemaURN])
+	})
+}
+
+func TestUserResourceHandler_Patch(t *testing.T) {
+	parsePath := func(t *testing.T, raw string) *filter.Path {
+		t.Helper()
+		path, err := filter.ParsePath([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &path
+	}
+
+	t.Run("given name", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "name.givenName"), Value: "Second"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Only the display name is stored, so it is updated to match.
+		assert.Equal(t, "Second Last", user.Attributes["displayName"])
+		assert.Equal(t, "user1", user.Attributes["userName"])
+	})
+
+	t.Run("replace without path", func(t *testing.T) {
+		db := getMockDB()
+		namespaces := database.NewMockNamespaceStore()
+		namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+		db.NamespacesFunc.SetDefaultReturn(namespaces)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Value: map[string]interface{}{
+				"userName":    "renamed",
+				"displayName": "New Name",
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "renamed", user.Attributes["userName"])
+		assert.Equal(t, "New Name", user.Attributes["displayName"])
+	})
+
+	t.Run("work email", func(t *testing.T) {
+		db := getMockDB()
+		userEmails := db.UserEmails().(*database.MockUserEmailsStore)
+		userEmails.GetFunc.SetDefaultHook(func(ctx context.Context, id int32, email string) (string, bool, error) {
+			if email == "work@example.com" {
+				return "", false, &errcode.Mock{IsNotFound: true}
+			}
+			return email, true, nil
+		})
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, `emails[type eq "work"].value`), Value: "work@example.com"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The type of email addresses isn't stored, so the work email address replaces the primary one.
+		addCalls := userEmails.AddFunc.History()
+		if assert.Len(t, addCalls, 1) {
+			assert.Equal(t, "work@example.com", addCalls[0].Arg2)
+		}
+		mockassert.CalledOnceWith(t, userEmails.SetPrimaryEmailFunc, mockassert.Values(mockassert.Skip, int32(1), "work@example.com"))
+		mockassert.CalledOnceWith(t, userEmails.RemoveFunc, mockassert.Values(mockassert.Skip, int32(1), "a@example.com"))
+	})
+
+	t.Run("work email replaced twice", func(t *testing.T) {
+		db := getMockDB()
+		users, err := db.Users().ListForSCIM(context.Background(), &database.UsersListOptions{UserIDs: []int32{1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		user := users[0]
+		userEmails := db.UserEmails().(*database.MockUserEmailsStore)
+		userEmails.GetFunc.SetDefaultHook(func(ctx context.Context, id int32, email string) (string, bool, error) {
+			for _, e := range user.Emails {
+				if e == email {
+					return email, true, nil
+				}
+			}
+			return "", false, &errcode.Mock{IsNotFound: true}
+		})
+		userEmails.AddFunc.SetDefaultHook(func(ctx context.Context, id int32, email string, code *string) error {
+			user.Emails = append(user.Emails, email)
+			return nil
+		})
+		userEmails.RemoveFunc.SetDefaultHook(func(ctx context.Context, id int32, email string) error {
+			for i, e := range user.Emails {
+				if e == email {
+					user.Emails = append(user.Emails[:i], user.Emails[i+1:]...)
+					return nil
+				}
+			}
+			return errors.New("email not found")
+		})
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		for _, email := range []string{"work1@example.com", "work2@example.com"} {
+			_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+				{Op: scim.PatchOperationReplace, Path: parsePath(t, `emails[type eq "work"].value`), Value: email},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Each replacement replaces the previous email address instead of adding another one.
+		assert.Equal(t, []string{"work2@example.com"}, user.Emails)
+	})
+
+	t.Run("remove email", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationRemove, Path: parsePath(t, `emails[value eq "a@example.com"]`)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		userEmails := db.UserEmails().(*database.MockUserEmailsStore)
+		mockassert.CalledOnceWith(t, userEmails.RemoveFunc, mockassert.Values(mockassert.Skip, int32(1), "a@example.com"))
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		user, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "displayName"), Value: "New Name"},
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "active"), Value: false},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The user is deleted, so no resource is returned.
+		assert.Empty(t, user.Attributes)
+
+		// The user is patched and deleted like on DELETE.
+		users := db.Users().(*database.MockUserStore)
+		mockassert.CalledOnceWith(t, users.UpdateFunc, mockassert.Values(mockassert.Skip, int32(1), mockassert.Skip))
+		mockassert.CalledOnceWith(t, users.DeleteFunc, mockassert.Values(mockassert.Skip, int32(1)))
+		mockassert.CalledOnceWith(t, users.InvalidateSessionsByIDFunc, mockassert.Values(mockassert.Skip, int32(1)))
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		mockassert.CalledOnceWith(t, externalAccounts.DeleteFunc, mockassert.Values(mockassert.Skip, database.ExternalAccountsDeleteOptions{UserID: 1, ServiceType: "scim"}))
+
+		_, err = userResourceHandler.Get(&http.Request{}, "1")
+		var scimErr scimerrors.ScimError
+		if assert.True(t, errors.As(err, &scimErr)) {
+			assert.Equal(t, http.StatusNotFound, scimErr.Status)
+		}
+	})
+
+	t.Run("unknown sub-attribute", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "name.nickName"), Value: "Nick"},
+		})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeInvalidPath, scimErr.ScimType)
+		mockassert.NotCalled(t, db.Users().(*database.MockUserStore).UpdateFunc)
+	})
+}
+
+func TestUserResourceHandler_UpdateExternalID(t *testing.T) {
+	parsePath := func(t *testing.T, raw string) *filter.Path {
+		t.Helper()
+		path, err := filter.ParsePath([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &path
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		db, _ := getMockDBWithSCIMExternalAccounts(t)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Replace(&http.Request{}, "1", scim.ResourceAttributes{
+			"userName":   "user1",
+			"externalId": "new-external-id",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := userResourceHandler.Get(&http.Request{}, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "new-external-id", user.ExternalID.Value())
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		mockassert.CalledOnceWith(t, externalAccounts.UpdateAccountIDFunc, mockassert.Values(mockassert.Skip, int32(1), "new-external-id"))
+	})
+
+	t.Run("patch user without external ID", func(t *testing.T) {
+		db, _ := getMockDBWithSCIMExternalAccounts(t)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "2", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "externalId"), Value: "external2"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := userResourceHandler.Get(&http.Request{}, "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "external2", user.ExternalID.Value())
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		mockassert.CalledOnceWith(t, externalAccounts.InsertFunc, mockassert.Values(mockassert.Skip, int32(2), scimAccountSpec("external2")))
+	})
+
+	t.Run("patch remove", func(t *testing.T) {
+		db, _ := getMockDBWithSCIMExternalAccounts(t)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{
+			{Op: scim.PatchOperationRemove, Path: parsePath(t, "externalId")},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := userResourceHandler.Get(&http.Request{}, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.False(t, user.ExternalID.Present())
+	})
+
+	t.Run("external ID of another user", func(t *testing.T) {
+		db, users := getMockDBWithSCIMExternalAccounts(t)
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		_, err := userResourceHandler.Patch(&http.Request{}, "2", []scim.PatchOperation{
+			{Op: scim.PatchOperationReplace, Path: parsePath(t, "externalId"), Value: "external1"},
+		})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusConflict, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeUniqueness, scimErr.ScimType)
+		assert.Equal(t, "", users[2].SCIMExternalID)
+	})
+}
+
+func TestUserResourceHandler_Patch_OperationLimit(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimMaxPatchOperations: 2}})
+	t.Cleanup(func() { conf.Mock(nil) })
+
+	db := getMockDB()
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+	path, err := filter.ParsePath([]byte(enterpriseUserSchemaURN + ":department"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operation := scim.PatchOperation{Op: scim.PatchOperationReplace, Path: &path, Value: "Engineering"}
+
+	t.Run("within limit", func(t *testing.T) {
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{operation, operation})
+		assert.NoError(t, err)
+	})
+
+	t.Run("exceeding limit", func(t *testing.T) {
+		setAttributes := db.Users().(*database.MockUserStore).SetSCIMEnterpriseAttributesFunc
+		calls := len(setAttributes.History())
+		_, err := userResourceHandler.Patch(&http.Request{}, "1", []scim.PatchOperation{operation, operation, operation})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeTooMany, scimErr.ScimType)
+		// The user is not changed.
+		assert.Len(t, setAttributes.History(), calls)
+	})
+}
+
+func TestUserResourceHandler_Delete(t *testing.T) {
+	t.Run("soft delete", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		if err := userResourceHandler.Delete(&http.Request{}, "1"); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		mockassert.CalledOnceWith(t, users.DeleteFunc, mockassert.Values(mockassert.Skip, int32(1)))
+		mockassert.NotCalled(t, users.HardDeleteFunc)
+		mockassert.CalledOnceWith(t, users.InvalidateSessionsByIDFunc, mockassert.Values(mockassert.Skip, int32(1)))
+		externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+		mockassert.CalledOnceWith(t, externalAccounts.DeleteFunc, mockassert.Values(mockassert.Skip, database.ExternalAccountsDeleteOptions{UserID: 1, ServiceType: "scim"}))
+
+		// The user can't be fetched anymore.
+		_, err := userResourceHandler.Get(&http.Request{}, "1")
+		var scimErr scimerrors.ScimError
+		if assert.True(t, errors.As(err, &scimErr)) {
+			assert.Equal(t, http.StatusNotFound, scimErr.Status)
+		}
+
+		// Deleting the user again succeeds without changes.
+		if err := userResourceHandler.Delete(&http.Request{}, "1"); err != nil {
+			t.Fatal(err)
+		}
+		mockassert.CalledOnce(t, users.DeleteFunc)
+	})
+
+	t.Run("hard delete", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ScimHardDeleteUsers: true}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		if err := userResourceHandler.Delete(&http.Request{}, "1"); err != nil {
+			t.Fatal(err)
+		}
+
+		users := db.Users().(*database.MockUserStore)
+		mockassert.CalledOnceWith(t, users.HardDeleteFunc, mockassert.Values(mockassert.Skip, int32(1)))
+		mockassert.NotCalled(t, users.DeleteFunc)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db := getMockDB()
+		userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+		err := userResourceHandler.Delete(&http.Request{}, "100")
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusNotFound, scimErr.Status)
+		mockassert.NotCalled(t, db.Users().(*database.MockUserStore).DeleteFunc)
+	})
+}
+
 func TestUserResourceHandler_Get(t *testing.T) {
 	db := getMockDB()
 	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
@@ -126,6 +1066,19 @@ func TestUserResourceHandler_GetAll(t *testing.T) {
 		{name: "filter: userName", count: 999, startIndex: 1, filter: "userName eq \"user3\"", wantTotalResults: 1, wantResults: 1, wantFirstID: 3},
 		{name: "filter: OR", count: 999, startIndex: 1, filter: "(userName eq \"user3\") OR (displayName eq \"First Middle Last\")", wantTotalResults: 2, wantResults: 2, wantFirstID: 2},
 		{name: "filter: AND", count: 999, startIndex: 1, filter: "(userName eq \"user3\") AND (displayName eq \"First Last\")", wantTotalResults: 1, wantResults: 1, wantFirstID: 3},
+		{name: "filter: OR, count=1, offset=1", count: 1, startIndex: 2, filter: "(displayName eq \"First Last\") OR (userName eq \"user2\")", wantTotalResults: 3, wantResults: 1, wantFirstID: 2},
+		{name: "filter: OR, count=2, offset=2", count: 2, startIndex: 3, filter: "(displayName eq \"First Last\") OR (userName eq \"user2\")", wantTotalResults: 3, wantResults: 1, wantFirstID: 3},
+		{name: "filter: AND within OR, count=1", count: 1, startIndex: 1, filter: "((userName eq \"user3\") AND (displayName eq \"First Last\")) OR (userName eq \"user4\")", wantTotalResults: 2, wantResults: 1, wantFirstID: 3},
+		{name: "filter: sw", count: 999, startIndex: 1, filter: "userName sw \"user\"", wantTotalResults: 4, wantResults: 4, wantFirstID: 1},
+		{name: "filter: co", count: 999, startIndex: 1, filter: "userName co \"ER3\"", wantTotalResults: 1, wantResults: 1, wantFirstID: 3},
+		{name: "filter: ew", count: 999, startIndex: 1, filter: "externalId ew \"1\"", wantTotalResults: 1, wantResults: 1, wantFirstID: 1},
+		{name: "filter: ne", count: 999, startIndex: 1, filter: "userName ne \"user1\"", wantTotalResults: 3, wantResults: 3, wantFirstID: 2},
+		{name: "filter: gt", count: 999, startIndex: 1, filter: "userName gt \"user2\"", wantTotalResults: 2, wantResults: 2, wantFirstID: 3},
+		{name: "filter: le", count: 999, startIndex: 1, filter: "userName le \"user2\"", wantTotalResults: 2, wantResults: 2, wantFirstID: 1},
+		{name: "filter: pr", count: 999, startIndex: 1, filter: "externalId pr", wantTotalResults: 1, wantResults: 1, wantFirstID: 1},
+		{name: "filter: pr, multi-valued", count: 999, startIndex: 1, filter: "emails pr", wantTotalResults: 2, wantResults: 2, wantFirstID: 1},
+		{name: "filter: NOT pr", count: 999, startIndex: 1, filter: "not (displayName pr)", wantTotalResults: 1, wantResults: 1, wantFirstID: 4},
+		{name: "filter: pr within AND within OR", count: 999, startIndex: 1, filter: "(userName sw \"user\" AND externalId pr) OR userName eq \"user4\"", wantTotalResults: 2, wantResults: 2, wantFirstID: 1},
 	}
 
 	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
@@ -154,6 +1107,261 @@ func TestUserResourceHandler_GetAll(t *testing.T) {
 	}
 }
 
+func TestUserResourceHandler_GetAll_NoMatches(t *testing.T) {
+	handler := newTestHandler(t, getMockDB())
+
+	for _, query := range []string{
+		"filter=" + url.QueryEscape(`userName eq "nope"`),
+		"filter=" + url.QueryEscape(`userName eq "nope"`) + "&sortBy=userName",
+	} {
+		t.Run(query, func(t *testing.T) {
+			var page struct {
+				TotalResults int
+				Resources    []interface{}
+			}
+			serveJSON(t, handler, "/.api/scim/v2/Users?"+query, &page)
+
+			assert.Equal(t, 0, page.TotalResults)
+			// The resources are an empty list rather than null.
+			assert.NotNil(t, page.Resources)
+			assert.Empty(t, page.Resources)
+		})
+	}
+}
+
+func TestUserResourceHandler_GetAll_Sorted(t *testing.T) {
+	cases := []struct {
+		name             string
+		query            string
+		count            int
+		startIndex       int
+		filter           string
+		wantTotalResults int
+		wantIDs          []string
+	}{
+		{name: "userName ascending", query: "sortBy=userName", count: 2, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"1", "2"}},
+		{name: "userName ascending, explicit order", query: "sortBy=userName&sortOrder=ascending", count: 2, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"1", "2"}},
+		{name: "userName descending", query: "sortBy=userName&sortOrder=descending", count: 2, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"4", "3"}},
+		{name: "userName descending, second page", query: "sortBy=userName&sortOrder=descending", count: 2, startIndex: 3, wantTotalResults: 4, wantIDs: []string{"2", "1"}},
+		{name: "displayName ascending", query: "sortBy=displayName", count: 999, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"4", "1", "3", "2"}},
+		{name: "displayName descending", query: "sortBy=displayName&sortOrder=descending", count: 999, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"2", "1", "3", "4"}},
+		{name: "id descending", query: "sortBy=id&sortOrder=descending", count: 999, startIndex: 1, wantTotalResults: 4, wantIDs: []string{"4", "3", "2", "1"}},
+		{name: "filtered", query: "sortBy=userName&sortOrder=descending", count: 999, startIndex: 1, filter: "displayName eq \"First Last\"", wantTotalResults: 2, wantIDs: []string{"3", "1"}},
+	}
+
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, getMockDB())
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := scim.ListRequestParams{Count: c.count, StartIndex: c.startIndex}
+			if c.filter != "" {
+				filterExpr, err := filter.ParseFilter([]byte(c.filter))
+				if err != nil {
+					t.Fatal(err)
+				}
+				params.Filter = filterExpr
+			}
+			page, err := userResourceHandler.GetAll(&http.Request{URL: &url.URL{RawQuery: c.query}}, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, c.wantTotalResults, page.TotalResults)
+			var ids []string
+			for _, resource := range page.Resources {
+				ids = append(ids, resource.ID)
+			}
+			assert.Equal(t, c.wantIDs, ids)
+		})
+	}
+
+	for _, query := range []string{"sortBy=title", "sortBy=userName&sortOrder=random"} {
+		t.Run(query, func(t *testing.T) {
+			_, err := userResourceHandler.GetAll(&http.Request{URL: &url.URL{RawQuery: query}}, scim.ListRequestParams{Count: 10, StartIndex: 1})
+
+			var scimErr scimerrors.ScimError
+			if !errors.As(err, &scimErr) {
+				t.Fatalf("expected a SCIM error, got %v", err)
+			}
+			assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+			assert.Equal(t, scimerrors.ScimTypeInvalidValue, scimErr.ScimType)
+		})
+	}
+}
+
+func TestUserResourceHandler_GetAll_UnsupportedFilterAttribute(t *testing.T) {
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, getMockDB())
+
+	for _, tc := range []struct {
+		name          string
+		filter        string
+		wantAttribute string
+	}{
+		{
+			name:          "extension attribute",
+			filter:        `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "123"`,
+			wantAttribute: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber",
+		},
+		{
+			name:          "nested in a logical expression",
+			filter:        `userName eq "user1" or title eq "Engineer"`,
+			wantAttribute: "title",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filterExpr, err := filter.ParseFilter([]byte(tc.filter))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = userResourceHandler.GetAll(&http.Request{}, scim.ListRequestParams{Count: 10, StartIndex: 1, Filter: filterExpr})
+
+			var scimErr scimerrors.ScimError
+			if !errors.As(err, &scimErr) {
+				t.Fatalf("expected a SCIM error, got %v", err)
+			}
+			assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+			assert.Equal(t, scimerrors.ScimTypeInvalidFilter, scimErr.ScimType)
+			assert.Contains(t, scimErr.Detail, tc.wantAttribute)
+		})
+	}
+}
+
+func TestUserResourceHandler_GetAll_StableAcrossChanges(t *testing.T) {
+	users := []*types.UserForSCIM{
+		{User: types.User{ID: 1, Username: "user1"}},
+		{User: types.User{ID: 2, Username: "user2"}, SCIMResourceID: "opaque2"},
+		{User: types.User{ID: 3, Username: "user3"}},
+		{User: types.User{ID: 4, Username: "user4"}},
+		{User: types.User{ID: 5, Username: "user5"}},
+		{User: types.User{ID: 6, Username: "user6"}},
+	}
+	deleted := map[int32]bool{}
+	userStore := database.NewMockUserStore()
+	userStore.ListForSCIMFunc.SetDefaultHook(func(ctx context.Context, opt *database.UsersListOptions) ([]*types.UserForSCIM, error) {
+		var listed []*types.UserForSCIM
+		for _, user := range users {
+			if (deleted[user.ID] && !opt.IncludeDeleted) || (opt.SCIMResourceID != "" && user.SCIMResourceID != opt.SCIMResourceID) {
+				continue
+			}
+			listed = append(listed, user)
+		}
+		return applyLimitOffset(applyAfterID(listed, opt.AfterID), opt.LimitOffset)
+	})
+	userStore.CountFunc.SetDefaultHook(func(ctx context.Context, opt *database.UsersListOptions) (int, error) {
+		return len(users) - len(deleted), nil
+	})
+	db := database.NewMockDB()
+	db.UsersFunc.SetDefaultReturn(userStore)
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, db)
+
+	getPageIDs := func(startIndex int, afterID string) []string {
+		r := &http.Request{URL: &url.URL{}}
+		if afterID != "" {
+			r.URL.RawQuery = "afterId=" + url.QueryEscape(afterID)
+		}
+		page, err := userResourceHandler.GetAll(r, scim.ListRequestParams{Count: 2, StartIndex: startIndex})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, resource := range page.Resources {
+			ids = append(ids, resource.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"1", "opaque2"}, getPageIDs(1, ""))
+
+	// An already returned user is deleted between two page fetches. Continuing at startIndex=3 would skip user 3, but
+	// continuing after the last returned user doesn't, even though that is the user that was deleted.
+	deleted[2] = true
+	assert.Equal(t, []string{"4", "5"}, getPageIDs(3, ""))
+	assert.Equal(t, []string{"3", "4"}, getPageIDs(3, "opaque2"))
+
+	// A new user is inserted and another already returned user is deleted before the next page fetch.
+	users = append(users, &types.UserForSCIM{User: types.User{ID: 7, Username: "user7"}})
+	deleted[1] = true
+	assert.Equal(t, []string{"5", "6"}, getPageIDs(5, "4"))
+	assert.Equal(t, []string{"7"}, getPageIDs(7, "6"))
+
+	t.Run("unknown user to continue after", func(t *testing.T) {
+		_, err := userResourceHandler.GetAll(&http.Request{URL: &url.URL{RawQuery: "afterId=nope"}}, scim.ListRequestParams{Count: 2, StartIndex: 1})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, scimerrors.ScimTypeInvalidValue, scimErr.ScimType)
+	})
+
+	t.Run("with sortBy", func(t *testing.T) {
+		_, err := userResourceHandler.GetAll(&http.Request{URL: &url.URL{RawQuery: "afterId=4&sortBy=userName"}}, scim.ListRequestParams{Count: 2, StartIndex: 1})
+
+		var scimErr scimerrors.ScimError
+		if !errors.As(err, &scimErr) {
+			t.Fatalf("expected a SCIM error, got %v", err)
+		}
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+	})
+}
+
+func TestUserResourceHandler_GetAll_AfterIDWithFilter(t *testing.T) {
+	userResourceHandler := NewUserResourceHandler(context.Background(), &observation.TestContext, getMockDB())
+
+	filterExpr, err := filter.ParseFilter([]byte(`displayName eq "First Last"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := userResourceHandler.GetAll(&http.Request{URL: &url.URL{RawQuery: "afterId=1"}}, scim.ListRequestParams{Count: 10, StartIndex: 2, Filter: filterExpr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, page.TotalResults)
+	if assert.Len(t, page.Resources, 1) {
+		assert.Equal(t, "3", page.Resources[0].ID)
+	}
+}
+
+// getMockDBWithSCIMExternalAccounts returns a mock DB whose SCIM external accounts are the external IDs of the users,
+// along with the users by ID.
+func getMockDBWithSCIMExternalAccounts(t *testing.T) (*database.MockDB, map[int32]*types.UserForSCIM) {
+	t.Helper()
+	db := getMockDB()
+	list, err := db.Users().ListForSCIM(context.Background(), &database.UsersListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := make(map[int32]*types.UserForSCIM, len(list))
+	for _, user := range list {
+		users[user.ID] = user
+	}
+
+	// The ID of the external account of a user is the ID of the user.
+	externalAccounts := db.UserExternalAccounts().(*database.MockUserExternalAccountsStore)
+	externalAccounts.ListFunc.SetDefaultHook(func(ctx context.Context, opt database.ExternalAccountsListOptions) ([]*extsvc.Account, error) {
+		var accounts []*extsvc.Account
+		for _, user := range list {
+			if user.SCIMExternalID == "" || (opt.UserID != 0 && opt.UserID != user.ID) || (opt.AccountID != "" && opt.AccountID != user.SCIMExternalID) {
+				continue
+			}
+			accounts = append(accounts, &extsvc.Account{ID: user.ID, UserID: user.ID, AccountSpec: scimAccountSpec(user.SCIMExternalID)})
+		}
+		return accounts, nil
+	})
+	externalAccounts.InsertFunc.SetDefaultHook(func(ctx context.Context, userID int32, spec extsvc.AccountSpec, data extsvc.AccountData) error {
+		users[userID].SCIMExternalID = spec.AccountID
+		return nil
+	})
+	externalAccounts.UpdateAccountIDFunc.SetDefaultHook(func(ctx context.Context, id int32, accountID string) error {
+		users[id].SCIMExternalID = accountID
+		return nil
+	})
+	externalAccounts.DeleteFunc.SetDefaultHook(func(ctx context.Context, opt database.ExternalAccountsDeleteOptions) error {
+		users[opt.UserID].SCIMExternalID = ""
+		return nil
+	})
+	return db, users
+}
+
 func getMockDB() *database.MockDB {
 	users := []*types.UserForSCIM{
 		{User: types.User{ID: 1, Username: "user1", DisplayName: "First Last"}, Emails: []string{"a@example.com"}, SCIMExternalID: "external1"},
@@ -161,6 +1369,19 @@ func getMockDB() *database.MockDB {
 		{User: types.User{ID: 3, Username: "user3", DisplayName: "First Last"}},
 		{User: types.User{ID: 4, Username: "user4"}},
 	}
+	deleted := map[int32]bool{}
+	withoutDeleted := func(users []*types.UserForSCIM, opt *database.UsersListOptions) []*types.UserForSCIM {
+		if opt.IncludeDeleted {
+			return users
+		}
+		var remaining []*types.UserForSCIM
+		for _, user := range users {
+			if !deleted[user.ID] {
+				remaining = append(remaining, user)
+			}
+		}
+		return remaining
+	}
 
 	userStore := database.NewMockUserStore()
 	userStore.GetByCurrentAuthUserFunc.SetDefaultReturn(&types.User{SiteAdmin: true}, nil)
@@ -175,19 +1396,96 @@ func getMockDB() *database.MockDB {
 					}
 				}
 			}
-			return applyLimitOffset(filteredUsers, opt.LimitOffset)
+			return applyLimitOffset(withoutDeleted(filteredUsers, opt), opt.LimitOffset)
+		}
+		// Return the user with the given opaque ID
+		if opt.SCIMResourceID != "" {
+			for _, user := range withoutDeleted(users, opt) {
+				if user.SCIMResourceID == opt.SCIMResourceID {
+					return []*types.UserForSCIM{user}, nil
+				}
+			}
+			return nil, nil
 		}
 
-		return applyLimitOffset(users, opt.LimitOffset)
+		return applyLimitOffset(applyAfterID(withoutDeleted(users, opt), opt.AfterID), opt.LimitOffset)
 	})
 	userStore.CountFunc.SetDefaultReturn(4, nil)
-	userStore.CreateFunc.SetDefaultHook(func(ctx context.Context, user database.NewUser) (*types.User, error) {
-		return &types.User{ID: 5, Username: user.Username, DisplayName: user.DisplayName}, nil
+	userStore.CreateFunc.SetDefaultHook(func(ctx context.Context, newUser database.NewUser) (*types.User, error) {
+		user := &types.UserForSCIM{User: types.User{ID: int32(len(users) + 1), Username: newUser.Username, DisplayName: newUser.DisplayName}}
+		users = append(users, user)
+		return &user.User, nil
+	})
+	userStore.SetSCIMResourceIDFunc.SetDefaultHook(func(ctx context.Context, id int32, resourceID string) error {
+		for _, user := range users {
+			if user.ID == id {
+				user.SCIMResourceID = resourceID
+				return nil
+			}
+		}
+		return errors.New("user not found")
+	})
+	userStore.DeleteFunc.SetDefaultHook(func(ctx context.Context, id int32) error {
+		for _, user := range users {
+			if user.ID == id && !deleted[id] {
+				deleted[id] = true
+				return nil
+			}
+		}
+		return errors.New("user not found")
+	})
+	userStore.HardDeleteFunc.SetDefaultHook(func(ctx context.Context, id int32) error {
+		for i, user := range users {
+			if user.ID == id {
+				users = append(users[:i], users[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("user not found")
+	})
+	userStore.UpdateFunc.SetDefaultHook(func(ctx context.Context, id int32, update database.UserUpdate) error {
+		for _, user := range users {
+			if user.ID == id {
+				if update.Username != "" {
+					user.Username = update.Username
+				}
+				if update.DisplayName != nil {
+					user.DisplayName = *update.DisplayName
+				}
+				return nil
+			}
+		}
+		return errors.New("user not found")
+	})
+	userStore.SetSCIMEnterpriseAttributesFunc.SetDefaultHook(func(ctx context.Context, id int32, attributes map[string]string) error {
+		for _, user := range users {
+			if user.ID == id {
+				user.SCIMEnterpriseAttributes = attributes
+				return nil
+			}
+		}
+		return errors.New("user not found")
 	})
 
+	// Email addresses are reported as already added and verified by default.
+	userEmails := database.NewMockUserEmailsStore()
+	userEmails.GetFunc.SetDefaultHook(func(ctx context.Context, id int32, email string) (string, bool, error) {
+		return email, true, nil
+	})
+
+	// All usernames are available unless a test says otherwise.
+	namespaces := database.NewMockNamespaceStore()
+	namespaces.GetByNameFunc.SetDefaultReturn(nil, database.ErrNamespaceNotFound)
+
 	// Create DB
 	db := database.NewMockDB()
 	db.UsersFunc.SetDefaultReturn(userStore)
+	db.UserEmailsFunc.SetDefaultReturn(userEmails)
+	db.NamespacesFunc.SetDefaultReturn(namespaces)
+	db.UserExternalAccountsFunc.SetDefaultReturn(database.NewMockUserExternalAccountsStore())
+	db.WithTransactFunc.SetDefaultHook(func(ctx context.Context, f func(database.DB) error) error {
+		return f(db)
+	})
 	return db
 }
 
@@ -205,3 +1503,17 @@ func applyLimitOffset(users []*types.UserForSCIM, limitOffset *database.LimitOff
 	}
 	return users[start:end], nil
 }
+
+func applyAfterID(users []*types.UserForSCIM, afterID int32) []*types.UserForSCIM {
+	var filteredUsers []*types.UserForSCIM
+	for _, user := range users {
+		if user.ID > afterID {
+			filteredUsers = append(filteredUsers, user)
+		}
+	}
+	return filteredUsers
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
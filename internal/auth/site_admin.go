@@ -25,10 +25,7 @@ func CheckCurrentUserIsSiteAdmin(ctx context.Context, db database.DB) error {
 	if user == nil {
 		return ErrNotAuthenticated
 	}
-	if !user.SiteAdmin {
-		return ErrMustBeSiteAdmin
-	}
-	return nil
+	return checkIsSiteAdmin(ctx, db, user)
 }
 
 // CheckUserIsSiteAdmin returns an error if the user is NOT a site admin.
@@ -43,12 +40,51 @@ func CheckUserIsSiteAdmin(ctx context.Context, db database.DB, userID int32) err
 	if user == nil {
 		return ErrNotAuthenticated
 	}
+	return checkIsSiteAdmin(ctx, db, user)
+}
+
+// CheckCurrentUserIsPermanentSiteAdmin returns an error if the current user is
+// NOT a site admin. Unlike CheckCurrentUserIsSiteAdmin, a temporary assignment of
+// the ADMIN system role is not enough, so that temporary admin access can't be
+// used to grant lasting admin access.
+func CheckCurrentUserIsPermanentSiteAdmin(ctx context.Context, db database.DB) error {
+	if actor.FromContext(ctx).IsInternal() {
+		return nil
+	}
+	user, err := CurrentUser(ctx, db)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrNotAuthenticated
+	}
 	if !user.SiteAdmin {
 		return ErrMustBeSiteAdmin
 	}
 	return nil
 }
 
+// checkIsSiteAdmin returns an error if the user is neither a site admin nor has
+// an active assignment of the ADMIN system role. Users who aren't site admins
+// cost an extra query to look up the assignment.
+//
+// CheckCurrentUserIsSiteAdmin, CheckUserIsSiteAdmin and CheckSiteAdminOrSameUser
+// go through it, so temporary admins pass them. Checks of types.User.SiteAdmin
+// elsewhere don't.
+func checkIsSiteAdmin(ctx context.Context, db database.DB, user *types.User) error {
+	if user.SiteAdmin {
+		return nil
+	}
+	expiresAt, err := db.Users().TemporaryAdminExpiresAt(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if expiresAt.IsZero() {
+		return ErrMustBeSiteAdmin
+	}
+	return nil
+}
+
 // InsufficientAuthorizationError is an error that occurs when the authentication is technically valid
 // (e.g., the token is not expired) but does not yield a user with privileges to perform a certain
 // action.
@@ -425,15 +425,16 @@ func PasswordPolicyEnabled() bool {
 	return pc.Enabled
 }
 
-// By default, password reset links are valid for 4 hours.
-const defaultPasswordLinkExpiry = 14400
+// DefaultPasswordResetLinkExpiry is how long password reset links are valid by
+// default, in seconds: 4 hours.
+const DefaultPasswordResetLinkExpiry = 14400
 
 // AuthPasswordResetLinkExpiry returns the time (in seconds) indicating how long password
 // reset links are considered valid. If not set, it returns the default value.
 func AuthPasswordResetLinkExpiry() int {
 	val := Get().AuthPasswordResetLinkExpiry
 	if val <= 0 {
-		return defaultPasswordLinkExpiry
+		return DefaultPasswordResetLinkExpiry
 	}
 	return val
 }
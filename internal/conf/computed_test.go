@@ -20,11 +20,11 @@ func TestAuthPasswordResetLinkDuration(t *testing.T) {
 	}{{
 		name: "password link expiry has a default value if null",
 		sc:   &Unified{},
-		want: defaultPasswordLinkExpiry,
+		want: DefaultPasswordResetLinkExpiry,
 	}, {
 		name: "password link expiry has a default value if blank",
 		sc:   &Unified{SiteConfiguration: schema.SiteConfiguration{AuthPasswordResetLinkExpiry: 0}},
-		want: defaultPasswordLinkExpiry,
+		want: DefaultPasswordResetLinkExpiry,
 	}, {
 		name: "password link expiry can be customized",
 		sc:   &Unified{SiteConfiguration: schema.SiteConfiguration{AuthPasswordResetLinkExpiry: 60}},
@@ -84,6 +84,9 @@ type UserExternalAccountsStore interface {
 	// TouchLastValid sets last valid time of the given user external account to be now.
 	TouchLastValid(ctx context.Context, id int32) error
 
+	// UpdateAccountID changes the account ID of the given user external account.
+	UpdateAccountID(ctx context.Context, id int32, accountID string) error
+
 	WithEncryptionKey(key encryption.Key) UserExternalAccountsStore
 
 	QueryRow(ctx context.Context, query *sqlf.Query) *sql.Row
@@ -334,6 +337,25 @@ WHERE id = $1
 	return err
 }
 
+func (s *userExternalAccountsStore) UpdateAccountID(ctx context.Context, id int32, accountID string) error {
+	res, err := s.ExecResult(ctx, sqlf.Sprintf(`
+UPDATE user_external_accounts
+SET
+	account_id = %s,
+	updated_at = now()
+WHERE id = %s AND deleted_at IS NULL
+`, accountID, id))
+	if err != nil {
+		return err
+	}
+	if nrows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if nrows == 0 {
+		return userExternalAccountNotFoundError{[]any{id}}
+	}
+	return nil
+}
+
 // ExternalAccountsDeleteOptions defines criteria that will be used to select
 // which accounts to soft delete.
 type ExternalAccountsDeleteOptions struct {
@@ -15,6 +15,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 	"github.com/sourcegraph/sourcegraph/internal/encryption"
 	et "github.com/sourcegraph/sourcegraph/internal/encryption/testing"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
@@ -665,3 +666,41 @@ func TestExternalAccounts_TouchExpiredList(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestExternalAccounts_UpdateAccountID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	spec := extsvc.AccountSpec{
+		ServiceType: "xa",
+		ServiceID:   "xb",
+		ClientID:    "xc",
+		AccountID:   "xd",
+	}
+
+	user, err := db.UserExternalAccounts().CreateUserAndSave(ctx, NewUser{Username: "u"}, spec, extsvc.AccountData{})
+	require.NoError(t, err)
+
+	accts, err := db.UserExternalAccounts().List(ctx, ExternalAccountsListOptions{UserID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, accts, 1)
+
+	err = db.UserExternalAccounts().UpdateAccountID(ctx, accts[0].ID, "xd2")
+	require.NoError(t, err)
+
+	accts, err = db.UserExternalAccounts().List(ctx, ExternalAccountsListOptions{UserID: user.ID})
+	require.NoError(t, err)
+	require.Len(t, accts, 1)
+	require.Equal(t, "xd2", accts[0].AccountID)
+
+	// Deleted accounts can't be updated.
+	err = db.UserExternalAccounts().Delete(ctx, ExternalAccountsDeleteOptions{IDs: []int32{accts[0].ID}})
+	require.NoError(t, err)
+	err = db.UserExternalAccounts().UpdateAccountID(ctx, accts[0].ID, "xd3")
+	require.True(t, errcode.IsNotFound(err))
+}
@@ -35472,6 +35472,9 @@ type MockPermissionStore struct {
 	// GetByIDFunc is an instance of a mock function object controlling the
 	// behavior of the method GetByID.
 	GetByIDFunc *PermissionStoreGetByIDFunc
+	// GrantedToUsersFunc is an instance of a mock function object
+	// controlling the behavior of the method GrantedToUsers.
+	GrantedToUsersFunc *PermissionStoreGrantedToUsersFunc
 	// HandleFunc is an instance of a mock function object controlling the
 	// behavior of the method Handle.
 	HandleFunc *PermissionStoreHandleFunc
@@ -35523,6 +35526,11 @@ func NewMockPermissionStore() *MockPermissionStore {
 				return
 			},
 		},
+		GrantedToUsersFunc: &PermissionStoreGrantedToUsersFunc{
+			defaultHook: func(context.Context, []int32, []int32) (r0 map[int32][]int32, r1 error) {
+				return
+			},
+		},
 		HandleFunc: &PermissionStoreHandleFunc{
 			defaultHook: func() (r0 basestore.TransactableHandle) {
 				return
@@ -35580,6 +35588,11 @@ func NewStrictMockPermissionStore() *MockPermissionStore {
 				panic("unexpected invocation of MockPermissionStore.GetByID")
 			},
 		},
+		GrantedToUsersFunc: &PermissionStoreGrantedToUsersFunc{
+			defaultHook: func(context.Context, []int32, []int32) (map[int32][]int32, error) {
+				panic("unexpected invocation of MockPermissionStore.GrantedToUsers")
+			},
+		},
 		HandleFunc: &PermissionStoreHandleFunc{
 			defaultHook: func() basestore.TransactableHandle {
 				panic("unexpected invocation of MockPermissionStore.Handle")
@@ -35624,6 +35637,9 @@ func NewMockPermissionStoreFrom(i PermissionStore) *MockPermissionStore {
 		GetByIDFunc: &PermissionStoreGetByIDFunc{
 			defaultHook: i.GetByID,
 		},
+		GrantedToUsersFunc: &PermissionStoreGrantedToUsersFunc{
+			defaultHook: i.GrantedToUsers,
+		},
 		HandleFunc: &PermissionStoreHandleFunc{
 			defaultHook: i.Handle,
 		},
@@ -36383,6 +36399,119 @@ func (c PermissionStoreGetByIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// PermissionStoreGrantedToUsersFunc describes the behavior when the
+// GrantedToUsers method of the parent MockPermissionStore instance is
+// invoked.
+type PermissionStoreGrantedToUsersFunc struct {
+	defaultHook func(context.Context, []int32, []int32) (map[int32][]int32, error)
+	hooks       []func(context.Context, []int32, []int32) (map[int32][]int32, error)
+	history     []PermissionStoreGrantedToUsersFuncCall
+	mutex       sync.Mutex
+}
+
+// GrantedToUsers delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockPermissionStore) GrantedToUsers(v0 context.Context, v1 []int32, v2 []int32) (map[int32][]int32, error) {
+	r0, r1 := m.GrantedToUsersFunc.nextHook()(v0, v1, v2)
+	m.GrantedToUsersFunc.appendCall(PermissionStoreGrantedToUsersFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the GrantedToUsers
+// method of the parent MockPermissionStore instance is invoked and the hook
+// queue is empty.
+func (f *PermissionStoreGrantedToUsersFunc) SetDefaultHook(hook func(context.Context, []int32, []int32) (map[int32][]int32, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GrantedToUsers method of the parent MockPermissionStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *PermissionStoreGrantedToUsersFunc) PushHook(hook func(context.Context, []int32, []int32) (map[int32][]int32, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *PermissionStoreGrantedToUsersFunc) SetDefaultReturn(r0 map[int32][]int32, r1 error) {
+	f.SetDefaultHook(func(context.Context, []int32, []int32) (map[int32][]int32, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *PermissionStoreGrantedToUsersFunc) PushReturn(r0 map[int32][]int32, r1 error) {
+	f.PushHook(func(context.Context, []int32, []int32) (map[int32][]int32, error) {
+		return r0, r1
+	})
+}
+
+func (f *PermissionStoreGrantedToUsersFunc) nextHook() func(context.Context, []int32, []int32) (map[int32][]int32, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *PermissionStoreGrantedToUsersFunc) appendCall(r0 PermissionStoreGrantedToUsersFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of PermissionStoreGrantedToUsersFuncCall
+// objects describing the invocations of this function.
+func (f *PermissionStoreGrantedToUsersFunc) History() []PermissionStoreGrantedToUsersFuncCall {
+	f.mutex.Lock()
+	history := make([]PermissionStoreGrantedToUsersFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// PermissionStoreGrantedToUsersFuncCall is an object that describes an
+// invocation of method GrantedToUsers on an instance of
+// MockPermissionStore.
+type PermissionStoreGrantedToUsersFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 []int32
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 []int32
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[int32][]int32
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c PermissionStoreGrantedToUsersFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c PermissionStoreGrantedToUsersFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // PermissionStoreHandleFunc describes the behavior when the Handle method
 // of the parent MockPermissionStore instance is invoked.
 type PermissionStoreHandleFunc struct {
@@ -47639,6 +47768,9 @@ type MockSecurityEventLogsStore struct {
 	// InsertListFunc is an instance of a mock function object controlling
 	// the behavior of the method InsertList.
 	InsertListFunc *SecurityEventLogsStoreInsertListFunc
+	// ListFunc is an instance of a mock function object controlling the
+	// behavior of the method List.
+	ListFunc *SecurityEventLogsStoreListFunc
 	// LogEventFunc is an instance of a mock function object controlling the
 	// behavior of the method LogEvent.
 	LogEventFunc *SecurityEventLogsStoreLogEventFunc
@@ -47667,6 +47799,11 @@ func NewMockSecurityEventLogsStore() *MockSecurityEventLogsStore {
 				return
 			},
 		},
+		ListFunc: &SecurityEventLogsStoreListFunc{
+			defaultHook: func(context.Context, SecurityEventLogsListOptions) (r0 []*SecurityEvent, r1 error) {
+				return
+			},
+		},
 		LogEventFunc: &SecurityEventLogsStoreLogEventFunc{
 			defaultHook: func(context.Context, *SecurityEvent) {
 				return
@@ -47700,6 +47837,11 @@ func NewStrictMockSecurityEventLogsStore() *MockSecurityEventLogsStore {
 				panic("unexpected invocation of MockSecurityEventLogsStore.InsertList")
 			},
 		},
+		ListFunc: &SecurityEventLogsStoreListFunc{
+			defaultHook: func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+				panic("unexpected invocation of MockSecurityEventLogsStore.List")
+			},
+		},
 		LogEventFunc: &SecurityEventLogsStoreLogEventFunc{
 			defaultHook: func(context.Context, *SecurityEvent) {
 				panic("unexpected invocation of MockSecurityEventLogsStore.LogEvent")
@@ -47727,6 +47869,9 @@ func NewMockSecurityEventLogsStoreFrom(i SecurityEventLogsStore) *MockSecurityEv
 		InsertListFunc: &SecurityEventLogsStoreInsertListFunc{
 			defaultHook: i.InsertList,
 		},
+		ListFunc: &SecurityEventLogsStoreListFunc{
+			defaultHook: i.List,
+		},
 		LogEventFunc: &SecurityEventLogsStoreLogEventFunc{
 			defaultHook: i.LogEvent,
 		},
@@ -48048,6 +48193,114 @@ func (c SecurityEventLogsStoreInsertListFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// SecurityEventLogsStoreListFunc describes the behavior when the List
+// method of the parent MockSecurityEventLogsStore instance is invoked.
+type SecurityEventLogsStoreListFunc struct {
+	defaultHook func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error)
+	hooks       []func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error)
+	history     []SecurityEventLogsStoreListFuncCall
+	mutex       sync.Mutex
+}
+
+// List delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockSecurityEventLogsStore) List(v0 context.Context, v1 SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+	r0, r1 := m.ListFunc.nextHook()(v0, v1)
+	m.ListFunc.appendCall(SecurityEventLogsStoreListFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the List method of the
+// parent MockSecurityEventLogsStore instance is invoked and the hook queue
+// is empty.
+func (f *SecurityEventLogsStoreListFunc) SetDefaultHook(hook func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// List method of the parent MockSecurityEventLogsStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *SecurityEventLogsStoreListFunc) PushHook(hook func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *SecurityEventLogsStoreListFunc) SetDefaultReturn(r0 []*SecurityEvent, r1 error) {
+	f.SetDefaultHook(func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *SecurityEventLogsStoreListFunc) PushReturn(r0 []*SecurityEvent, r1 error) {
+	f.PushHook(func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+		return r0, r1
+	})
+}
+
+func (f *SecurityEventLogsStoreListFunc) nextHook() func(context.Context, SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *SecurityEventLogsStoreListFunc) appendCall(r0 SecurityEventLogsStoreListFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of SecurityEventLogsStoreListFuncCall objects
+// describing the invocations of this function.
+func (f *SecurityEventLogsStoreListFunc) History() []SecurityEventLogsStoreListFuncCall {
+	f.mutex.Lock()
+	history := make([]SecurityEventLogsStoreListFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// SecurityEventLogsStoreListFuncCall is an object that describes an
+// invocation of method List on an instance of MockSecurityEventLogsStore.
+type SecurityEventLogsStoreListFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 SecurityEventLogsListOptions
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []*SecurityEvent
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c SecurityEventLogsStoreListFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c SecurityEventLogsStoreListFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // SecurityEventLogsStoreLogEventFunc describes the behavior when the
 // LogEvent method of the parent MockSecurityEventLogsStore instance is
 // invoked.
@@ -54646,6 +54899,9 @@ type MockUserExternalAccountsStore struct {
 	// TransactFunc is an instance of a mock function object controlling the
 	// behavior of the method Transact.
 	TransactFunc *UserExternalAccountsStoreTransactFunc
+	// UpdateAccountIDFunc is an instance of a mock function object
+	// controlling the behavior of the method UpdateAccountID.
+	UpdateAccountIDFunc *UserExternalAccountsStoreUpdateAccountIDFunc
 	// WithFunc is an instance of a mock function object controlling the
 	// behavior of the method With.
 	WithFunc *UserExternalAccountsStoreWithFunc
@@ -54734,6 +54990,11 @@ func NewMockUserExternalAccountsStore() *MockUserExternalAccountsStore {
 				return
 			},
 		},
+		UpdateAccountIDFunc: &UserExternalAccountsStoreUpdateAccountIDFunc{
+			defaultHook: func(context.Context, int32, string) (r0 error) {
+				return
+			},
+		},
 		WithFunc: &UserExternalAccountsStoreWithFunc{
 			defaultHook: func(basestore.ShareableStore) (r0 UserExternalAccountsStore) {
 				return
@@ -54827,6 +55088,11 @@ func NewStrictMockUserExternalAccountsStore() *MockUserExternalAccountsStore {
 				panic("unexpected invocation of MockUserExternalAccountsStore.Transact")
 			},
 		},
+		UpdateAccountIDFunc: &UserExternalAccountsStoreUpdateAccountIDFunc{
+			defaultHook: func(context.Context, int32, string) error {
+				panic("unexpected invocation of MockUserExternalAccountsStore.UpdateAccountID")
+			},
+		},
 		WithFunc: &UserExternalAccountsStoreWithFunc{
 			defaultHook: func(basestore.ShareableStore) UserExternalAccountsStore {
 				panic("unexpected invocation of MockUserExternalAccountsStore.With")
@@ -54890,6 +55156,9 @@ func NewMockUserExternalAccountsStoreFrom(i UserExternalAccountsStore) *MockUser
 		TransactFunc: &UserExternalAccountsStoreTransactFunc{
 			defaultHook: i.Transact,
 		},
+		UpdateAccountIDFunc: &UserExternalAccountsStoreUpdateAccountIDFunc{
+			defaultHook: i.UpdateAccountID,
+		},
 		WithFunc: &UserExternalAccountsStoreWithFunc{
 			defaultHook: i.With,
 		},
@@ -56552,35 +56821,37 @@ func (c UserExternalAccountsStoreTransactFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
-// UserExternalAccountsStoreWithFunc describes the behavior when the With
-// method of the parent MockUserExternalAccountsStore instance is invoked.
-type UserExternalAccountsStoreWithFunc struct {
-	defaultHook func(basestore.ShareableStore) UserExternalAccountsStore
-	hooks       []func(basestore.ShareableStore) UserExternalAccountsStore
-	history     []UserExternalAccountsStoreWithFuncCall
+// UserExternalAccountsStoreUpdateAccountIDFunc describes the behavior when
+// the UpdateAccountID method of the parent MockUserExternalAccountsStore
+// instance is invoked.
+type UserExternalAccountsStoreUpdateAccountIDFunc struct {
+	defaultHook func(context.Context, int32, string) error
+	hooks       []func(context.Context, int32, string) error
+	history     []UserExternalAccountsStoreUpdateAccountIDFuncCall
 	mutex       sync.Mutex
 }
 
-// With delegates to the next hook function in the queue and stores the
-// parameter and result values of this invocation.
-func (m *MockUserExternalAccountsStore) With(v0 basestore.ShareableStore) UserExternalAccountsStore {
-	r0 := m.WithFunc.nextHook()(v0)
-	m.WithFunc.appendCall(UserExternalAccountsStoreWithFuncCall{v0, r0})
+// UpdateAccountID delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockUserExternalAccountsStore) UpdateAccountID(v0 context.Context, v1 int32, v2 string) error {
+	r0 := m.UpdateAccountIDFunc.nextHook()(v0, v1, v2)
+	m.UpdateAccountIDFunc.appendCall(UserExternalAccountsStoreUpdateAccountIDFuncCall{v0, v1, v2, r0})
 	return r0
 }
 
-// SetDefaultHook sets function that is called when the With method of the
-// parent MockUserExternalAccountsStore instance is invoked and the hook
-// queue is empty.
-func (f *UserExternalAccountsStoreWithFunc) SetDefaultHook(hook func(basestore.ShareableStore) UserExternalAccountsStore) {
+// SetDefaultHook sets function that is called when the UpdateAccountID
+// method of the parent MockUserExternalAccountsStore instance is invoked
+// and the hook queue is empty.
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) SetDefaultHook(hook func(context.Context, int32, string) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// With method of the parent MockUserExternalAccountsStore instance invokes
-// the hook at the front of the queue and discards it. After the queue is
-// empty, the default hook function is invoked for any future action.
-func (f *UserExternalAccountsStoreWithFunc) PushHook(hook func(basestore.ShareableStore) UserExternalAccountsStore) {
+// UpdateAccountID method of the parent MockUserExternalAccountsStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) PushHook(hook func(context.Context, int32, string) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -56588,20 +56859,20 @@ func (f *UserExternalAccountsStoreWithFunc) PushHook(hook func(basestore.Shareab
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *UserExternalAccountsStoreWithFunc) SetDefaultReturn(r0 UserExternalAccountsStore) {
-	f.SetDefaultHook(func(basestore.ShareableStore) UserExternalAccountsStore {
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int32, string) error {
 		return r0
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *UserExternalAccountsStoreWithFunc) PushReturn(r0 UserExternalAccountsStore) {
-	f.PushHook(func(basestore.ShareableStore) UserExternalAccountsStore {
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int32, string) error {
 		return r0
 	})
 }
 
-func (f *UserExternalAccountsStoreWithFunc) nextHook() func(basestore.ShareableStore) UserExternalAccountsStore {
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) nextHook() func(context.Context, int32, string) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -56614,58 +56885,168 @@ func (f *UserExternalAccountsStoreWithFunc) nextHook() func(basestore.ShareableS
 	return hook
 }
 
-func (f *UserExternalAccountsStoreWithFunc) appendCall(r0 UserExternalAccountsStoreWithFuncCall) {
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) appendCall(r0 UserExternalAccountsStoreUpdateAccountIDFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of UserExternalAccountsStoreWithFuncCall
-// objects describing the invocations of this function.
-func (f *UserExternalAccountsStoreWithFunc) History() []UserExternalAccountsStoreWithFuncCall {
+// History returns a sequence of
+// UserExternalAccountsStoreUpdateAccountIDFuncCall objects describing the
+// invocations of this function.
+func (f *UserExternalAccountsStoreUpdateAccountIDFunc) History() []UserExternalAccountsStoreUpdateAccountIDFuncCall {
 	f.mutex.Lock()
-	history := make([]UserExternalAccountsStoreWithFuncCall, len(f.history))
+	history := make([]UserExternalAccountsStoreUpdateAccountIDFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// UserExternalAccountsStoreWithFuncCall is an object that describes an
-// invocation of method With on an instance of
+// UserExternalAccountsStoreUpdateAccountIDFuncCall is an object that
+// describes an invocation of method UpdateAccountID on an instance of
 // MockUserExternalAccountsStore.
-type UserExternalAccountsStoreWithFuncCall struct {
+type UserExternalAccountsStoreUpdateAccountIDFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
-	Arg0 basestore.ShareableStore
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int32
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 UserExternalAccountsStore
+	Result0 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c UserExternalAccountsStoreWithFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0}
+func (c UserExternalAccountsStoreUpdateAccountIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c UserExternalAccountsStoreWithFuncCall) Results() []interface{} {
+func (c UserExternalAccountsStoreUpdateAccountIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
-// UserExternalAccountsStoreWithEncryptionKeyFunc describes the behavior
-// when the WithEncryptionKey method of the parent
-// MockUserExternalAccountsStore instance is invoked.
-type UserExternalAccountsStoreWithEncryptionKeyFunc struct {
-	defaultHook func(encryption.Key) UserExternalAccountsStore
-	hooks       []func(encryption.Key) UserExternalAccountsStore
-	history     []UserExternalAccountsStoreWithEncryptionKeyFuncCall
-	mutex       sync.Mutex
-}
-
-// WithEncryptionKey delegates to the next hook function in the queue and
+// UserExternalAccountsStoreWithFunc describes the behavior when the With
+// method of the parent MockUserExternalAccountsStore instance is invoked.
+type UserExternalAccountsStoreWithFunc struct {
+	defaultHook func(basestore.ShareableStore) UserExternalAccountsStore
+	hooks       []func(basestore.ShareableStore) UserExternalAccountsStore
+	history     []UserExternalAccountsStoreWithFuncCall
+	mutex       sync.Mutex
+}
+
+// With delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockUserExternalAccountsStore) With(v0 basestore.ShareableStore) UserExternalAccountsStore {
+	r0 := m.WithFunc.nextHook()(v0)
+	m.WithFunc.appendCall(UserExternalAccountsStoreWithFuncCall{v0, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the With method of the
+// parent MockUserExternalAccountsStore instance is invoked and the hook
+// queue is empty.
+func (f *UserExternalAccountsStoreWithFunc) SetDefaultHook(hook func(basestore.ShareableStore) UserExternalAccountsStore) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// With method of the parent MockUserExternalAccountsStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *UserExternalAccountsStoreWithFunc) PushHook(hook func(basestore.ShareableStore) UserExternalAccountsStore) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserExternalAccountsStoreWithFunc) SetDefaultReturn(r0 UserExternalAccountsStore) {
+	f.SetDefaultHook(func(basestore.ShareableStore) UserExternalAccountsStore {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserExternalAccountsStoreWithFunc) PushReturn(r0 UserExternalAccountsStore) {
+	f.PushHook(func(basestore.ShareableStore) UserExternalAccountsStore {
+		return r0
+	})
+}
+
+func (f *UserExternalAccountsStoreWithFunc) nextHook() func(basestore.ShareableStore) UserExternalAccountsStore {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserExternalAccountsStoreWithFunc) appendCall(r0 UserExternalAccountsStoreWithFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserExternalAccountsStoreWithFuncCall
+// objects describing the invocations of this function.
+func (f *UserExternalAccountsStoreWithFunc) History() []UserExternalAccountsStoreWithFuncCall {
+	f.mutex.Lock()
+	history := make([]UserExternalAccountsStoreWithFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserExternalAccountsStoreWithFuncCall is an object that describes an
+// invocation of method With on an instance of
+// MockUserExternalAccountsStore.
+type UserExternalAccountsStoreWithFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 basestore.ShareableStore
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 UserExternalAccountsStore
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserExternalAccountsStoreWithFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserExternalAccountsStoreWithFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// UserExternalAccountsStoreWithEncryptionKeyFunc describes the behavior
+// when the WithEncryptionKey method of the parent
+// MockUserExternalAccountsStore instance is invoked.
+type UserExternalAccountsStoreWithEncryptionKeyFunc struct {
+	defaultHook func(encryption.Key) UserExternalAccountsStore
+	hooks       []func(encryption.Key) UserExternalAccountsStore
+	history     []UserExternalAccountsStoreWithEncryptionKeyFuncCall
+	mutex       sync.Mutex
+}
+
+// WithEncryptionKey delegates to the next hook function in the queue and
 // stores the parameter and result values of this invocation.
 func (m *MockUserExternalAccountsStore) WithEncryptionKey(v0 encryption.Key) UserExternalAccountsStore {
 	r0 := m.WithEncryptionKeyFunc.nextHook()(v0)
@@ -56771,6 +57152,9 @@ type MockUserRoleStore struct {
 	// AssignSystemRoleFunc is an instance of a mock function object
 	// controlling the behavior of the method AssignSystemRole.
 	AssignSystemRoleFunc *UserRoleStoreAssignSystemRoleFunc
+	// AssignWithExpiryFunc is an instance of a mock function object
+	// controlling the behavior of the method AssignWithExpiry.
+	AssignWithExpiryFunc *UserRoleStoreAssignWithExpiryFunc
 	// BulkAssignSystemRolesToUserFunc is an instance of a mock function
 	// object controlling the behavior of the method
 	// BulkAssignSystemRolesToUser.
@@ -56778,6 +57162,9 @@ type MockUserRoleStore struct {
 	// BulkAssignToUserFunc is an instance of a mock function object
 	// controlling the behavior of the method BulkAssignToUser.
 	BulkAssignToUserFunc *UserRoleStoreBulkAssignToUserFunc
+	// DeleteExpiredFunc is an instance of a mock function object
+	// controlling the behavior of the method DeleteExpired.
+	DeleteExpiredFunc *UserRoleStoreDeleteExpiredFunc
 	// GetByRoleIDFunc is an instance of a mock function object controlling
 	// the behavior of the method GetByRoleID.
 	GetByRoleIDFunc *UserRoleStoreGetByRoleIDFunc
@@ -56787,9 +57174,15 @@ type MockUserRoleStore struct {
 	// GetByUserIDFunc is an instance of a mock function object controlling
 	// the behavior of the method GetByUserID.
 	GetByUserIDFunc *UserRoleStoreGetByUserIDFunc
+	// GetByUserIDsFunc is an instance of a mock function object controlling
+	// the behavior of the method GetByUserIDs.
+	GetByUserIDsFunc *UserRoleStoreGetByUserIDsFunc
 	// HandleFunc is an instance of a mock function object controlling the
 	// behavior of the method Handle.
 	HandleFunc *UserRoleStoreHandleFunc
+	// ListRecentFunc is an instance of a mock function object controlling
+	// the behavior of the method ListRecent.
+	ListRecentFunc *UserRoleStoreListRecentFunc
 	// RevokeFunc is an instance of a mock function object controlling the
 	// behavior of the method Revoke.
 	RevokeFunc *UserRoleStoreRevokeFunc
@@ -56818,6 +57211,11 @@ func NewMockUserRoleStore() *MockUserRoleStore {
 				return
 			},
 		},
+		AssignWithExpiryFunc: &UserRoleStoreAssignWithExpiryFunc{
+			defaultHook: func(context.Context, AssignUserRoleOpts) (r0 *types.UserRole, r1 error) {
+				return
+			},
+		},
 		BulkAssignSystemRolesToUserFunc: &UserRoleStoreBulkAssignSystemRolesToUserFunc{
 			defaultHook: func(context.Context, BulkAssignSystemRolesToUserOpts) (r0 []*types.UserRole, r1 error) {
 				return
@@ -56828,6 +57226,11 @@ func NewMockUserRoleStore() *MockUserRoleStore {
 				return
 			},
 		},
+		DeleteExpiredFunc: &UserRoleStoreDeleteExpiredFunc{
+			defaultHook: func(context.Context) (r0 []*types.UserRole, r1 error) {
+				return
+			},
+		},
 		GetByRoleIDFunc: &UserRoleStoreGetByRoleIDFunc{
 			defaultHook: func(context.Context, GetUserRoleOpts) (r0 []*types.UserRole, r1 error) {
 				return
@@ -56843,11 +57246,21 @@ func NewMockUserRoleStore() *MockUserRoleStore {
 				return
 			},
 		},
+		GetByUserIDsFunc: &UserRoleStoreGetByUserIDsFunc{
+			defaultHook: func(context.Context, []int32) (r0 []*types.UserRole, r1 error) {
+				return
+			},
+		},
 		HandleFunc: &UserRoleStoreHandleFunc{
 			defaultHook: func() (r0 basestore.TransactableHandle) {
 				return
 			},
 		},
+		ListRecentFunc: &UserRoleStoreListRecentFunc{
+			defaultHook: func(context.Context, ListRecentUserRolesOpts) (r0 []*types.UserRole, r1 error) {
+				return
+			},
+		},
 		RevokeFunc: &UserRoleStoreRevokeFunc{
 			defaultHook: func(context.Context, RevokeUserRoleOpts) (r0 error) {
 				return
@@ -56885,6 +57298,11 @@ func NewStrictMockUserRoleStore() *MockUserRoleStore {
 				panic("unexpected invocation of MockUserRoleStore.AssignSystemRole")
 			},
 		},
+		AssignWithExpiryFunc: &UserRoleStoreAssignWithExpiryFunc{
+			defaultHook: func(context.Context, AssignUserRoleOpts) (*types.UserRole, error) {
+				panic("unexpected invocation of MockUserRoleStore.AssignWithExpiry")
+			},
+		},
 		BulkAssignSystemRolesToUserFunc: &UserRoleStoreBulkAssignSystemRolesToUserFunc{
 			defaultHook: func(context.Context, BulkAssignSystemRolesToUserOpts) ([]*types.UserRole, error) {
 				panic("unexpected invocation of MockUserRoleStore.BulkAssignSystemRolesToUser")
@@ -56895,6 +57313,11 @@ func NewStrictMockUserRoleStore() *MockUserRoleStore {
 				panic("unexpected invocation of MockUserRoleStore.BulkAssignToUser")
 			},
 		},
+		DeleteExpiredFunc: &UserRoleStoreDeleteExpiredFunc{
+			defaultHook: func(context.Context) ([]*types.UserRole, error) {
+				panic("unexpected invocation of MockUserRoleStore.DeleteExpired")
+			},
+		},
 		GetByRoleIDFunc: &UserRoleStoreGetByRoleIDFunc{
 			defaultHook: func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
 				panic("unexpected invocation of MockUserRoleStore.GetByRoleID")
@@ -56910,11 +57333,21 @@ func NewStrictMockUserRoleStore() *MockUserRoleStore {
 				panic("unexpected invocation of MockUserRoleStore.GetByUserID")
 			},
 		},
+		GetByUserIDsFunc: &UserRoleStoreGetByUserIDsFunc{
+			defaultHook: func(context.Context, []int32) ([]*types.UserRole, error) {
+				panic("unexpected invocation of MockUserRoleStore.GetByUserIDs")
+			},
+		},
 		HandleFunc: &UserRoleStoreHandleFunc{
 			defaultHook: func() basestore.TransactableHandle {
 				panic("unexpected invocation of MockUserRoleStore.Handle")
 			},
 		},
+		ListRecentFunc: &UserRoleStoreListRecentFunc{
+			defaultHook: func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+				panic("unexpected invocation of MockUserRoleStore.ListRecent")
+			},
+		},
 		RevokeFunc: &UserRoleStoreRevokeFunc{
 			defaultHook: func(context.Context, RevokeUserRoleOpts) error {
 				panic("unexpected invocation of MockUserRoleStore.Revoke")
@@ -56949,12 +57382,18 @@ func NewMockUserRoleStoreFrom(i UserRoleStore) *MockUserRoleStore {
 		AssignSystemRoleFunc: &UserRoleStoreAssignSystemRoleFunc{
 			defaultHook: i.AssignSystemRole,
 		},
+		AssignWithExpiryFunc: &UserRoleStoreAssignWithExpiryFunc{
+			defaultHook: i.AssignWithExpiry,
+		},
 		BulkAssignSystemRolesToUserFunc: &UserRoleStoreBulkAssignSystemRolesToUserFunc{
 			defaultHook: i.BulkAssignSystemRolesToUser,
 		},
 		BulkAssignToUserFunc: &UserRoleStoreBulkAssignToUserFunc{
 			defaultHook: i.BulkAssignToUser,
 		},
+		DeleteExpiredFunc: &UserRoleStoreDeleteExpiredFunc{
+			defaultHook: i.DeleteExpired,
+		},
 		GetByRoleIDFunc: &UserRoleStoreGetByRoleIDFunc{
 			defaultHook: i.GetByRoleID,
 		},
@@ -56964,9 +57403,15 @@ func NewMockUserRoleStoreFrom(i UserRoleStore) *MockUserRoleStore {
 		GetByUserIDFunc: &UserRoleStoreGetByUserIDFunc{
 			defaultHook: i.GetByUserID,
 		},
+		GetByUserIDsFunc: &UserRoleStoreGetByUserIDsFunc{
+			defaultHook: i.GetByUserIDs,
+		},
 		HandleFunc: &UserRoleStoreHandleFunc{
 			defaultHook: i.Handle,
 		},
+		ListRecentFunc: &UserRoleStoreListRecentFunc{
+			defaultHook: i.ListRecent,
+		},
 		RevokeFunc: &UserRoleStoreRevokeFunc{
 			defaultHook: i.Revoke,
 		},
@@ -57199,6 +57644,116 @@ func (c UserRoleStoreAssignSystemRoleFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UserRoleStoreAssignWithExpiryFunc describes the behavior when the
+// AssignWithExpiry method of the parent MockUserRoleStore instance is
+// invoked.
+type UserRoleStoreAssignWithExpiryFunc struct {
+	defaultHook func(context.Context, AssignUserRoleOpts) (*types.UserRole, error)
+	hooks       []func(context.Context, AssignUserRoleOpts) (*types.UserRole, error)
+	history     []UserRoleStoreAssignWithExpiryFuncCall
+	mutex       sync.Mutex
+}
+
+// AssignWithExpiry delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockUserRoleStore) AssignWithExpiry(v0 context.Context, v1 AssignUserRoleOpts) (*types.UserRole, error) {
+	r0, r1 := m.AssignWithExpiryFunc.nextHook()(v0, v1)
+	m.AssignWithExpiryFunc.appendCall(UserRoleStoreAssignWithExpiryFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the AssignWithExpiry
+// method of the parent MockUserRoleStore instance is invoked and the hook
+// queue is empty.
+func (f *UserRoleStoreAssignWithExpiryFunc) SetDefaultHook(hook func(context.Context, AssignUserRoleOpts) (*types.UserRole, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// AssignWithExpiry method of the parent MockUserRoleStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *UserRoleStoreAssignWithExpiryFunc) PushHook(hook func(context.Context, AssignUserRoleOpts) (*types.UserRole, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserRoleStoreAssignWithExpiryFunc) SetDefaultReturn(r0 *types.UserRole, r1 error) {
+	f.SetDefaultHook(func(context.Context, AssignUserRoleOpts) (*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserRoleStoreAssignWithExpiryFunc) PushReturn(r0 *types.UserRole, r1 error) {
+	f.PushHook(func(context.Context, AssignUserRoleOpts) (*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserRoleStoreAssignWithExpiryFunc) nextHook() func(context.Context, AssignUserRoleOpts) (*types.UserRole, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserRoleStoreAssignWithExpiryFunc) appendCall(r0 UserRoleStoreAssignWithExpiryFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserRoleStoreAssignWithExpiryFuncCall
+// objects describing the invocations of this function.
+func (f *UserRoleStoreAssignWithExpiryFunc) History() []UserRoleStoreAssignWithExpiryFuncCall {
+	f.mutex.Lock()
+	history := make([]UserRoleStoreAssignWithExpiryFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserRoleStoreAssignWithExpiryFuncCall is an object that describes an
+// invocation of method AssignWithExpiry on an instance of
+// MockUserRoleStore.
+type UserRoleStoreAssignWithExpiryFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 AssignUserRoleOpts
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *types.UserRole
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserRoleStoreAssignWithExpiryFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserRoleStoreAssignWithExpiryFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UserRoleStoreBulkAssignSystemRolesToUserFunc describes the behavior when
 // the BulkAssignSystemRolesToUser method of the parent MockUserRoleStore
 // instance is invoked.
@@ -57421,35 +57976,35 @@ func (c UserRoleStoreBulkAssignToUserFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
-// UserRoleStoreGetByRoleIDFunc describes the behavior when the GetByRoleID
-// method of the parent MockUserRoleStore instance is invoked.
-type UserRoleStoreGetByRoleIDFunc struct {
-	defaultHook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)
-	hooks       []func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)
-	history     []UserRoleStoreGetByRoleIDFuncCall
+// UserRoleStoreDeleteExpiredFunc describes the behavior when the
+// DeleteExpired method of the parent MockUserRoleStore instance is invoked.
+type UserRoleStoreDeleteExpiredFunc struct {
+	defaultHook func(context.Context) ([]*types.UserRole, error)
+	hooks       []func(context.Context) ([]*types.UserRole, error)
+	history     []UserRoleStoreDeleteExpiredFuncCall
 	mutex       sync.Mutex
 }
 
-// GetByRoleID delegates to the next hook function in the queue and stores
+// DeleteExpired delegates to the next hook function in the queue and stores
 // the parameter and result values of this invocation.
-func (m *MockUserRoleStore) GetByRoleID(v0 context.Context, v1 GetUserRoleOpts) ([]*types.UserRole, error) {
-	r0, r1 := m.GetByRoleIDFunc.nextHook()(v0, v1)
-	m.GetByRoleIDFunc.appendCall(UserRoleStoreGetByRoleIDFuncCall{v0, v1, r0, r1})
+func (m *MockUserRoleStore) DeleteExpired(v0 context.Context) ([]*types.UserRole, error) {
+	r0, r1 := m.DeleteExpiredFunc.nextHook()(v0)
+	m.DeleteExpiredFunc.appendCall(UserRoleStoreDeleteExpiredFuncCall{v0, r0, r1})
 	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the GetByRoleID method
+// SetDefaultHook sets function that is called when the DeleteExpired method
 // of the parent MockUserRoleStore instance is invoked and the hook queue is
 // empty.
-func (f *UserRoleStoreGetByRoleIDFunc) SetDefaultHook(hook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)) {
+func (f *UserRoleStoreDeleteExpiredFunc) SetDefaultHook(hook func(context.Context) ([]*types.UserRole, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// GetByRoleID method of the parent MockUserRoleStore instance invokes the
+// DeleteExpired method of the parent MockUserRoleStore instance invokes the
 // hook at the front of the queue and discards it. After the queue is empty,
 // the default hook function is invoked for any future action.
-func (f *UserRoleStoreGetByRoleIDFunc) PushHook(hook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)) {
+func (f *UserRoleStoreDeleteExpiredFunc) PushHook(hook func(context.Context) ([]*types.UserRole, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -57457,20 +58012,20 @@ func (f *UserRoleStoreGetByRoleIDFunc) PushHook(hook func(context.Context, GetUs
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *UserRoleStoreGetByRoleIDFunc) SetDefaultReturn(r0 []*types.UserRole, r1 error) {
-	f.SetDefaultHook(func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+func (f *UserRoleStoreDeleteExpiredFunc) SetDefaultReturn(r0 []*types.UserRole, r1 error) {
+	f.SetDefaultHook(func(context.Context) ([]*types.UserRole, error) {
 		return r0, r1
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *UserRoleStoreGetByRoleIDFunc) PushReturn(r0 []*types.UserRole, r1 error) {
-	f.PushHook(func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+func (f *UserRoleStoreDeleteExpiredFunc) PushReturn(r0 []*types.UserRole, r1 error) {
+	f.PushHook(func(context.Context) ([]*types.UserRole, error) {
 		return r0, r1
 	})
 }
 
-func (f *UserRoleStoreGetByRoleIDFunc) nextHook() func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+func (f *UserRoleStoreDeleteExpiredFunc) nextHook() func(context.Context) ([]*types.UserRole, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -57483,32 +58038,29 @@ func (f *UserRoleStoreGetByRoleIDFunc) nextHook() func(context.Context, GetUserR
 	return hook
 }
 
-func (f *UserRoleStoreGetByRoleIDFunc) appendCall(r0 UserRoleStoreGetByRoleIDFuncCall) {
+func (f *UserRoleStoreDeleteExpiredFunc) appendCall(r0 UserRoleStoreDeleteExpiredFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of UserRoleStoreGetByRoleIDFuncCall objects
+// History returns a sequence of UserRoleStoreDeleteExpiredFuncCall objects
 // describing the invocations of this function.
-func (f *UserRoleStoreGetByRoleIDFunc) History() []UserRoleStoreGetByRoleIDFuncCall {
+func (f *UserRoleStoreDeleteExpiredFunc) History() []UserRoleStoreDeleteExpiredFuncCall {
 	f.mutex.Lock()
-	history := make([]UserRoleStoreGetByRoleIDFuncCall, len(f.history))
+	history := make([]UserRoleStoreDeleteExpiredFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// UserRoleStoreGetByRoleIDFuncCall is an object that describes an
-// invocation of method GetByRoleID on an instance of MockUserRoleStore.
-type UserRoleStoreGetByRoleIDFuncCall struct {
+// UserRoleStoreDeleteExpiredFuncCall is an object that describes an
+// invocation of method DeleteExpired on an instance of MockUserRoleStore.
+type UserRoleStoreDeleteExpiredFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
-	// Arg1 is the value of the 2nd argument passed to this method
-	// invocation.
-	Arg1 GetUserRoleOpts
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 []*types.UserRole
@@ -57519,7 +58071,115 @@ type UserRoleStoreGetByRoleIDFuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c UserRoleStoreGetByRoleIDFuncCall) Args() []interface{} {
+func (c UserRoleStoreDeleteExpiredFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserRoleStoreDeleteExpiredFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// UserRoleStoreGetByRoleIDFunc describes the behavior when the GetByRoleID
+// method of the parent MockUserRoleStore instance is invoked.
+type UserRoleStoreGetByRoleIDFunc struct {
+	defaultHook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)
+	hooks       []func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)
+	history     []UserRoleStoreGetByRoleIDFuncCall
+	mutex       sync.Mutex
+}
+
+// GetByRoleID delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockUserRoleStore) GetByRoleID(v0 context.Context, v1 GetUserRoleOpts) ([]*types.UserRole, error) {
+	r0, r1 := m.GetByRoleIDFunc.nextHook()(v0, v1)
+	m.GetByRoleIDFunc.appendCall(UserRoleStoreGetByRoleIDFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the GetByRoleID method
+// of the parent MockUserRoleStore instance is invoked and the hook queue is
+// empty.
+func (f *UserRoleStoreGetByRoleIDFunc) SetDefaultHook(hook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetByRoleID method of the parent MockUserRoleStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *UserRoleStoreGetByRoleIDFunc) PushHook(hook func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserRoleStoreGetByRoleIDFunc) SetDefaultReturn(r0 []*types.UserRole, r1 error) {
+	f.SetDefaultHook(func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserRoleStoreGetByRoleIDFunc) PushReturn(r0 []*types.UserRole, r1 error) {
+	f.PushHook(func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserRoleStoreGetByRoleIDFunc) nextHook() func(context.Context, GetUserRoleOpts) ([]*types.UserRole, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserRoleStoreGetByRoleIDFunc) appendCall(r0 UserRoleStoreGetByRoleIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserRoleStoreGetByRoleIDFuncCall objects
+// describing the invocations of this function.
+func (f *UserRoleStoreGetByRoleIDFunc) History() []UserRoleStoreGetByRoleIDFuncCall {
+	f.mutex.Lock()
+	history := make([]UserRoleStoreGetByRoleIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserRoleStoreGetByRoleIDFuncCall is an object that describes an
+// invocation of method GetByRoleID on an instance of MockUserRoleStore.
+type UserRoleStoreGetByRoleIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 GetUserRoleOpts
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []*types.UserRole
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserRoleStoreGetByRoleIDFuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1}
 }
 
@@ -57748,6 +58408,114 @@ func (c UserRoleStoreGetByUserIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UserRoleStoreGetByUserIDsFunc describes the behavior when the GetByUserIDs
+// method of the parent MockUserRoleStore instance is invoked.
+type UserRoleStoreGetByUserIDsFunc struct {
+	defaultHook func(context.Context, []int32) ([]*types.UserRole, error)
+	hooks       []func(context.Context, []int32) ([]*types.UserRole, error)
+	history     []UserRoleStoreGetByUserIDsFuncCall
+	mutex       sync.Mutex
+}
+
+// GetByUserIDs delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockUserRoleStore) GetByUserIDs(v0 context.Context, v1 []int32) ([]*types.UserRole, error) {
+	r0, r1 := m.GetByUserIDsFunc.nextHook()(v0, v1)
+	m.GetByUserIDsFunc.appendCall(UserRoleStoreGetByUserIDsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the GetByUserIDs method
+// of the parent MockUserRoleStore instance is invoked and the hook queue is
+// empty.
+func (f *UserRoleStoreGetByUserIDsFunc) SetDefaultHook(hook func(context.Context, []int32) ([]*types.UserRole, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetByUserIDs method of the parent MockUserRoleStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *UserRoleStoreGetByUserIDsFunc) PushHook(hook func(context.Context, []int32) ([]*types.UserRole, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserRoleStoreGetByUserIDsFunc) SetDefaultReturn(r0 []*types.UserRole, r1 error) {
+	f.SetDefaultHook(func(context.Context, []int32) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserRoleStoreGetByUserIDsFunc) PushReturn(r0 []*types.UserRole, r1 error) {
+	f.PushHook(func(context.Context, []int32) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserRoleStoreGetByUserIDsFunc) nextHook() func(context.Context, []int32) ([]*types.UserRole, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserRoleStoreGetByUserIDsFunc) appendCall(r0 UserRoleStoreGetByUserIDsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserRoleStoreGetByUserIDsFuncCall objects
+// describing the invocations of this function.
+func (f *UserRoleStoreGetByUserIDsFunc) History() []UserRoleStoreGetByUserIDsFuncCall {
+	f.mutex.Lock()
+	history := make([]UserRoleStoreGetByUserIDsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserRoleStoreGetByUserIDsFuncCall is an object that describes an
+// invocation of method GetByUserIDs on an instance of MockUserRoleStore.
+type UserRoleStoreGetByUserIDsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 []int32
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []*types.UserRole
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserRoleStoreGetByUserIDsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserRoleStoreGetByUserIDsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UserRoleStoreHandleFunc describes the behavior when the Handle method of
 // the parent MockUserRoleStore instance is invoked.
 type UserRoleStoreHandleFunc struct {
@@ -57846,6 +58614,114 @@ func (c UserRoleStoreHandleFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// UserRoleStoreListRecentFunc describes the behavior when the ListRecent
+// method of the parent MockUserRoleStore instance is invoked.
+type UserRoleStoreListRecentFunc struct {
+	defaultHook func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error)
+	hooks       []func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error)
+	history     []UserRoleStoreListRecentFuncCall
+	mutex       sync.Mutex
+}
+
+// ListRecent delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockUserRoleStore) ListRecent(v0 context.Context, v1 ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+	r0, r1 := m.ListRecentFunc.nextHook()(v0, v1)
+	m.ListRecentFunc.appendCall(UserRoleStoreListRecentFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the ListRecent method of
+// the parent MockUserRoleStore instance is invoked and the hook queue is
+// empty.
+func (f *UserRoleStoreListRecentFunc) SetDefaultHook(hook func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ListRecent method of the parent MockUserRoleStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *UserRoleStoreListRecentFunc) PushHook(hook func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserRoleStoreListRecentFunc) SetDefaultReturn(r0 []*types.UserRole, r1 error) {
+	f.SetDefaultHook(func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserRoleStoreListRecentFunc) PushReturn(r0 []*types.UserRole, r1 error) {
+	f.PushHook(func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserRoleStoreListRecentFunc) nextHook() func(context.Context, ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserRoleStoreListRecentFunc) appendCall(r0 UserRoleStoreListRecentFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserRoleStoreListRecentFuncCall objects
+// describing the invocations of this function.
+func (f *UserRoleStoreListRecentFunc) History() []UserRoleStoreListRecentFuncCall {
+	f.mutex.Lock()
+	history := make([]UserRoleStoreListRecentFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserRoleStoreListRecentFuncCall is an object that describes an invocation
+// of method ListRecent on an instance of MockUserRoleStore.
+type UserRoleStoreListRecentFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 ListRecentUserRolesOpts
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []*types.UserRole
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserRoleStoreListRecentFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserRoleStoreListRecentFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UserRoleStoreRevokeFunc describes the behavior when the Revoke method of
 // the parent MockUserRoleStore instance is invoked.
 type UserRoleStoreRevokeFunc struct {
@@ -58316,6 +59192,10 @@ type MockUserStore struct {
 	// GetByVerifiedEmailFunc is an instance of a mock function object
 	// controlling the behavior of the method GetByVerifiedEmail.
 	GetByVerifiedEmailFunc *UserStoreGetByVerifiedEmailFunc
+	// GetDeletedNonSCIMByUsernameFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// GetDeletedNonSCIMByUsername.
+	GetDeletedNonSCIMByUsernameFunc *UserStoreGetDeletedNonSCIMByUsernameFunc
 	// HandleFunc is an instance of a mock function object controlling the
 	// behavior of the method Handle.
 	HandleFunc *UserStoreHandleFunc
@@ -58353,6 +59233,9 @@ type MockUserStore struct {
 	// a mock function object controlling the behavior of the method
 	// RandomizePasswordAndClearPasswordResetRateLimit.
 	RandomizePasswordAndClearPasswordResetRateLimitFunc *UserStoreRandomizePasswordAndClearPasswordResetRateLimitFunc
+	// ReclaimDeletedFunc is an instance of a mock function object
+	// controlling the behavior of the method ReclaimDeleted.
+	ReclaimDeletedFunc *UserStoreReclaimDeletedFunc
 	// RecoverUsersListFunc is an instance of a mock function object
 	// controlling the behavior of the method RecoverUsersList.
 	RecoverUsersListFunc *UserStoreRecoverUsersListFunc
@@ -58365,12 +59248,22 @@ type MockUserStore struct {
 	// SetPasswordFunc is an instance of a mock function object controlling
 	// the behavior of the method SetPassword.
 	SetPasswordFunc *UserStoreSetPasswordFunc
+	// SetSCIMEnterpriseAttributesFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// SetSCIMEnterpriseAttributes.
+	SetSCIMEnterpriseAttributesFunc *UserStoreSetSCIMEnterpriseAttributesFunc
+	// SetSCIMResourceIDFunc is an instance of a mock function object
+	// controlling the behavior of the method SetSCIMResourceID.
+	SetSCIMResourceIDFunc *UserStoreSetSCIMResourceIDFunc
 	// SetTagFunc is an instance of a mock function object controlling the
 	// behavior of the method SetTag.
 	SetTagFunc *UserStoreSetTagFunc
 	// TagsFunc is an instance of a mock function object controlling the
 	// behavior of the method Tags.
 	TagsFunc *UserStoreTagsFunc
+	// TemporaryAdminExpiresAtFunc is an instance of a mock function object
+	// controlling the behavior of the method TemporaryAdminExpiresAt.
+	TemporaryAdminExpiresAtFunc *UserStoreTemporaryAdminExpiresAtFunc
 	// TransactFunc is an instance of a mock function object controlling the
 	// behavior of the method Transact.
 	TransactFunc *UserStoreTransactFunc
@@ -58469,6 +59362,11 @@ func NewMockUserStore() *MockUserStore {
 				return
 			},
 		},
+		GetDeletedNonSCIMByUsernameFunc: &UserStoreGetDeletedNonSCIMByUsernameFunc{
+			defaultHook: func(context.Context, string) (r0 *types.User, r1 error) {
+				return
+			},
+		},
 		HandleFunc: &UserStoreHandleFunc{
 			defaultHook: func() (r0 basestore.TransactableHandle) {
 				return
@@ -58529,6 +59427,11 @@ func NewMockUserStore() *MockUserStore {
 				return
 			},
 		},
+		ReclaimDeletedFunc: &UserStoreReclaimDeletedFunc{
+			defaultHook: func(context.Context, int32) (r0 error) {
+				return
+			},
+		},
 		RecoverUsersListFunc: &UserStoreRecoverUsersListFunc{
 			defaultHook: func(context.Context, []int32) (r0 []int32, r1 error) {
 				return
@@ -58549,6 +59452,16 @@ func NewMockUserStore() *MockUserStore {
 				return
 			},
 		},
+		SetSCIMEnterpriseAttributesFunc: &UserStoreSetSCIMEnterpriseAttributesFunc{
+			defaultHook: func(context.Context, int32, map[string]string) (r0 error) {
+				return
+			},
+		},
+		SetSCIMResourceIDFunc: &UserStoreSetSCIMResourceIDFunc{
+			defaultHook: func(context.Context, int32, string) (r0 error) {
+				return
+			},
+		},
 		SetTagFunc: &UserStoreSetTagFunc{
 			defaultHook: func(context.Context, int32, string, bool) (r0 error) {
 				return
@@ -58559,6 +59472,11 @@ func NewMockUserStore() *MockUserStore {
 				return
 			},
 		},
+		TemporaryAdminExpiresAtFunc: &UserStoreTemporaryAdminExpiresAtFunc{
+			defaultHook: func(context.Context, int32) (r0 time.Time, r1 error) {
+				return
+			},
+		},
 		TransactFunc: &UserStoreTransactFunc{
 			defaultHook: func(context.Context) (r0 UserStore, r1 error) {
 				return
@@ -58666,6 +59584,11 @@ func NewStrictMockUserStore() *MockUserStore {
 				panic("unexpected invocation of MockUserStore.GetByVerifiedEmail")
 			},
 		},
+		GetDeletedNonSCIMByUsernameFunc: &UserStoreGetDeletedNonSCIMByUsernameFunc{
+			defaultHook: func(context.Context, string) (*types.User, error) {
+				panic("unexpected invocation of MockUserStore.GetDeletedNonSCIMByUsername")
+			},
+		},
 		HandleFunc: &UserStoreHandleFunc{
 			defaultHook: func() basestore.TransactableHandle {
 				panic("unexpected invocation of MockUserStore.Handle")
@@ -58726,6 +59649,11 @@ func NewStrictMockUserStore() *MockUserStore {
 				panic("unexpected invocation of MockUserStore.RandomizePasswordAndClearPasswordResetRateLimit")
 			},
 		},
+		ReclaimDeletedFunc: &UserStoreReclaimDeletedFunc{
+			defaultHook: func(context.Context, int32) error {
+				panic("unexpected invocation of MockUserStore.ReclaimDeleted")
+			},
+		},
 		RecoverUsersListFunc: &UserStoreRecoverUsersListFunc{
 			defaultHook: func(context.Context, []int32) ([]int32, error) {
 				panic("unexpected invocation of MockUserStore.RecoverUsersList")
@@ -58746,6 +59674,16 @@ func NewStrictMockUserStore() *MockUserStore {
 				panic("unexpected invocation of MockUserStore.SetPassword")
 			},
 		},
+		SetSCIMEnterpriseAttributesFunc: &UserStoreSetSCIMEnterpriseAttributesFunc{
+			defaultHook: func(context.Context, int32, map[string]string) error {
+				panic("unexpected invocation of MockUserStore.SetSCIMEnterpriseAttributes")
+			},
+		},
+		SetSCIMResourceIDFunc: &UserStoreSetSCIMResourceIDFunc{
+			defaultHook: func(context.Context, int32, string) error {
+				panic("unexpected invocation of MockUserStore.SetSCIMResourceID")
+			},
+		},
 		SetTagFunc: &UserStoreSetTagFunc{
 			defaultHook: func(context.Context, int32, string, bool) error {
 				panic("unexpected invocation of MockUserStore.SetTag")
@@ -58756,6 +59694,11 @@ func NewStrictMockUserStore() *MockUserStore {
 				panic("unexpected invocation of MockUserStore.Tags")
 			},
 		},
+		TemporaryAdminExpiresAtFunc: &UserStoreTemporaryAdminExpiresAtFunc{
+			defaultHook: func(context.Context, int32) (time.Time, error) {
+				panic("unexpected invocation of MockUserStore.TemporaryAdminExpiresAt")
+			},
+		},
 		TransactFunc: &UserStoreTransactFunc{
 			defaultHook: func(context.Context) (UserStore, error) {
 				panic("unexpected invocation of MockUserStore.Transact")
@@ -58831,6 +59774,9 @@ func NewMockUserStoreFrom(i UserStore) *MockUserStore {
 		GetByVerifiedEmailFunc: &UserStoreGetByVerifiedEmailFunc{
 			defaultHook: i.GetByVerifiedEmail,
 		},
+		GetDeletedNonSCIMByUsernameFunc: &UserStoreGetDeletedNonSCIMByUsernameFunc{
+			defaultHook: i.GetDeletedNonSCIMByUsername,
+		},
 		HandleFunc: &UserStoreHandleFunc{
 			defaultHook: i.Handle,
 		},
@@ -58867,6 +59813,9 @@ func NewMockUserStoreFrom(i UserStore) *MockUserStore {
 		RandomizePasswordAndClearPasswordResetRateLimitFunc: &UserStoreRandomizePasswordAndClearPasswordResetRateLimitFunc{
 			defaultHook: i.RandomizePasswordAndClearPasswordResetRateLimit,
 		},
+		ReclaimDeletedFunc: &UserStoreReclaimDeletedFunc{
+			defaultHook: i.ReclaimDeleted,
+		},
 		RecoverUsersListFunc: &UserStoreRecoverUsersListFunc{
 			defaultHook: i.RecoverUsersList,
 		},
@@ -58879,12 +59828,21 @@ func NewMockUserStoreFrom(i UserStore) *MockUserStore {
 		SetPasswordFunc: &UserStoreSetPasswordFunc{
 			defaultHook: i.SetPassword,
 		},
+		SetSCIMEnterpriseAttributesFunc: &UserStoreSetSCIMEnterpriseAttributesFunc{
+			defaultHook: i.SetSCIMEnterpriseAttributes,
+		},
+		SetSCIMResourceIDFunc: &UserStoreSetSCIMResourceIDFunc{
+			defaultHook: i.SetSCIMResourceID,
+		},
 		SetTagFunc: &UserStoreSetTagFunc{
 			defaultHook: i.SetTag,
 		},
 		TagsFunc: &UserStoreTagsFunc{
 			defaultHook: i.Tags,
 		},
+		TemporaryAdminExpiresAtFunc: &UserStoreTemporaryAdminExpiresAtFunc{
+			defaultHook: i.TemporaryAdminExpiresAt,
+		},
 		TransactFunc: &UserStoreTransactFunc{
 			defaultHook: i.Transact,
 		},
@@ -60620,6 +61578,118 @@ func (c UserStoreGetByVerifiedEmailFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UserStoreGetDeletedNonSCIMByUsernameFunc describes the behavior when the
+// GetDeletedNonSCIMByUsername method of the parent MockUserStore instance
+// is invoked.
+type UserStoreGetDeletedNonSCIMByUsernameFunc struct {
+	defaultHook func(context.Context, string) (*types.User, error)
+	hooks       []func(context.Context, string) (*types.User, error)
+	history     []UserStoreGetDeletedNonSCIMByUsernameFuncCall
+	mutex       sync.Mutex
+}
+
+// GetDeletedNonSCIMByUsername delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockUserStore) GetDeletedNonSCIMByUsername(v0 context.Context, v1 string) (*types.User, error) {
+	r0, r1 := m.GetDeletedNonSCIMByUsernameFunc.nextHook()(v0, v1)
+	m.GetDeletedNonSCIMByUsernameFunc.appendCall(UserStoreGetDeletedNonSCIMByUsernameFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// GetDeletedNonSCIMByUsername method of the parent MockUserStore instance
+// is invoked and the hook queue is empty.
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) SetDefaultHook(hook func(context.Context, string) (*types.User, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetDeletedNonSCIMByUsername method of the parent MockUserStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) PushHook(hook func(context.Context, string) (*types.User, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) SetDefaultReturn(r0 *types.User, r1 error) {
+	f.SetDefaultHook(func(context.Context, string) (*types.User, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) PushReturn(r0 *types.User, r1 error) {
+	f.PushHook(func(context.Context, string) (*types.User, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) nextHook() func(context.Context, string) (*types.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) appendCall(r0 UserStoreGetDeletedNonSCIMByUsernameFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// UserStoreGetDeletedNonSCIMByUsernameFuncCall objects describing the
+// invocations of this function.
+func (f *UserStoreGetDeletedNonSCIMByUsernameFunc) History() []UserStoreGetDeletedNonSCIMByUsernameFuncCall {
+	f.mutex.Lock()
+	history := make([]UserStoreGetDeletedNonSCIMByUsernameFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserStoreGetDeletedNonSCIMByUsernameFuncCall is an object that describes
+// an invocation of method GetDeletedNonSCIMByUsername on an instance of
+// MockUserStore.
+type UserStoreGetDeletedNonSCIMByUsernameFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *types.User
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserStoreGetDeletedNonSCIMByUsernameFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserStoreGetDeletedNonSCIMByUsernameFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UserStoreHandleFunc describes the behavior when the Handle method of the
 // parent MockUserStore instance is invoked.
 type UserStoreHandleFunc struct {
@@ -61871,18 +62941,126 @@ func (f *UserStoreRandomizePasswordAndClearPasswordResetRateLimitFunc) appendCal
 // describing the invocations of this function.
 func (f *UserStoreRandomizePasswordAndClearPasswordResetRateLimitFunc) History() []UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall {
 	f.mutex.Lock()
-	history := make([]UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall, len(f.history))
+	history := make([]UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall is an
+// object that describes an invocation of method
+// RandomizePasswordAndClearPasswordResetRateLimit on an instance of
+// MockUserStore.
+type UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int32
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// UserStoreReclaimDeletedFunc describes the behavior when the
+// ReclaimDeleted method of the parent MockUserStore instance is
+// invoked.
+type UserStoreReclaimDeletedFunc struct {
+	defaultHook func(context.Context, int32) error
+	hooks       []func(context.Context, int32) error
+	history     []UserStoreReclaimDeletedFuncCall
+	mutex       sync.Mutex
+}
+
+// ReclaimDeleted delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockUserStore) ReclaimDeleted(v0 context.Context, v1 int32) error {
+	r0 := m.ReclaimDeletedFunc.nextHook()(v0, v1)
+	m.ReclaimDeletedFunc.appendCall(UserStoreReclaimDeletedFuncCall{v0, v1, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// ReclaimDeleted method of the parent MockUserStore instance is
+// invoked and the hook queue is empty.
+func (f *UserStoreReclaimDeletedFunc) SetDefaultHook(hook func(context.Context, int32) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ReclaimDeleted method of the parent MockUserStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *UserStoreReclaimDeletedFunc) PushHook(hook func(context.Context, int32) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserStoreReclaimDeletedFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int32) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserStoreReclaimDeletedFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int32) error {
+		return r0
+	})
+}
+
+func (f *UserStoreReclaimDeletedFunc) nextHook() func(context.Context, int32) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserStoreReclaimDeletedFunc) appendCall(r0 UserStoreReclaimDeletedFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserStoreReclaimDeletedFuncCall
+// objects describing the invocations of this function.
+func (f *UserStoreReclaimDeletedFunc) History() []UserStoreReclaimDeletedFuncCall {
+	f.mutex.Lock()
+	history := make([]UserStoreReclaimDeletedFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall is an
-// object that describes an invocation of method
-// RandomizePasswordAndClearPasswordResetRateLimit on an instance of
+// UserStoreReclaimDeletedFuncCall is an object that describes an
+// invocation of method ReclaimDeleted on an instance of
 // MockUserStore.
-type UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall struct {
+type UserStoreReclaimDeletedFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
@@ -61896,13 +63074,13 @@ type UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall) Args() []interface{} {
+func (c UserStoreReclaimDeletedFuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c UserStoreRandomizePasswordAndClearPasswordResetRateLimitFuncCall) Results() []interface{} {
+func (c UserStoreReclaimDeletedFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
@@ -62347,6 +63525,226 @@ func (c UserStoreSetPasswordFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UserStoreSetSCIMEnterpriseAttributesFunc describes the behavior when the
+// SetSCIMEnterpriseAttributes method of the parent MockUserStore instance
+// is invoked.
+type UserStoreSetSCIMEnterpriseAttributesFunc struct {
+	defaultHook func(context.Context, int32, map[string]string) error
+	hooks       []func(context.Context, int32, map[string]string) error
+	history     []UserStoreSetSCIMEnterpriseAttributesFuncCall
+	mutex       sync.Mutex
+}
+
+// SetSCIMEnterpriseAttributes delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockUserStore) SetSCIMEnterpriseAttributes(v0 context.Context, v1 int32, v2 map[string]string) error {
+	r0 := m.SetSCIMEnterpriseAttributesFunc.nextHook()(v0, v1, v2)
+	m.SetSCIMEnterpriseAttributesFunc.appendCall(UserStoreSetSCIMEnterpriseAttributesFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// SetSCIMEnterpriseAttributes method of the parent MockUserStore instance
+// is invoked and the hook queue is empty.
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) SetDefaultHook(hook func(context.Context, int32, map[string]string) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SetSCIMEnterpriseAttributes method of the parent MockUserStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) PushHook(hook func(context.Context, int32, map[string]string) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int32, map[string]string) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int32, map[string]string) error {
+		return r0
+	})
+}
+
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) nextHook() func(context.Context, int32, map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) appendCall(r0 UserStoreSetSCIMEnterpriseAttributesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// UserStoreSetSCIMEnterpriseAttributesFuncCall objects describing the
+// invocations of this function.
+func (f *UserStoreSetSCIMEnterpriseAttributesFunc) History() []UserStoreSetSCIMEnterpriseAttributesFuncCall {
+	f.mutex.Lock()
+	history := make([]UserStoreSetSCIMEnterpriseAttributesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserStoreSetSCIMEnterpriseAttributesFuncCall is an object that describes
+// an invocation of method SetSCIMEnterpriseAttributes on an instance of
+// MockUserStore.
+type UserStoreSetSCIMEnterpriseAttributesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int32
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 map[string]string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserStoreSetSCIMEnterpriseAttributesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserStoreSetSCIMEnterpriseAttributesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// UserStoreSetSCIMResourceIDFunc describes the behavior when the
+// SetSCIMResourceID method of the parent MockUserStore instance is invoked.
+type UserStoreSetSCIMResourceIDFunc struct {
+	defaultHook func(context.Context, int32, string) error
+	hooks       []func(context.Context, int32, string) error
+	history     []UserStoreSetSCIMResourceIDFuncCall
+	mutex       sync.Mutex
+}
+
+// SetSCIMResourceID delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockUserStore) SetSCIMResourceID(v0 context.Context, v1 int32, v2 string) error {
+	r0 := m.SetSCIMResourceIDFunc.nextHook()(v0, v1, v2)
+	m.SetSCIMResourceIDFunc.appendCall(UserStoreSetSCIMResourceIDFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the SetSCIMResourceID
+// method of the parent MockUserStore instance is invoked and the hook queue
+// is empty.
+func (f *UserStoreSetSCIMResourceIDFunc) SetDefaultHook(hook func(context.Context, int32, string) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SetSCIMResourceID method of the parent MockUserStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *UserStoreSetSCIMResourceIDFunc) PushHook(hook func(context.Context, int32, string) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserStoreSetSCIMResourceIDFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int32, string) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserStoreSetSCIMResourceIDFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int32, string) error {
+		return r0
+	})
+}
+
+func (f *UserStoreSetSCIMResourceIDFunc) nextHook() func(context.Context, int32, string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserStoreSetSCIMResourceIDFunc) appendCall(r0 UserStoreSetSCIMResourceIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserStoreSetSCIMResourceIDFuncCall objects
+// describing the invocations of this function.
+func (f *UserStoreSetSCIMResourceIDFunc) History() []UserStoreSetSCIMResourceIDFuncCall {
+	f.mutex.Lock()
+	history := make([]UserStoreSetSCIMResourceIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserStoreSetSCIMResourceIDFuncCall is an object that describes an
+// invocation of method SetSCIMResourceID on an instance of MockUserStore.
+type UserStoreSetSCIMResourceIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int32
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserStoreSetSCIMResourceIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserStoreSetSCIMResourceIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // UserStoreSetTagFunc describes the behavior when the SetTag method of the
 // parent MockUserStore instance is invoked.
 type UserStoreSetTagFunc struct {
@@ -62564,6 +63962,117 @@ func (c UserStoreTagsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UserStoreTemporaryAdminExpiresAtFunc describes the behavior when the
+// TemporaryAdminExpiresAt method of the parent MockUserStore instance is
+// invoked.
+type UserStoreTemporaryAdminExpiresAtFunc struct {
+	defaultHook func(context.Context, int32) (time.Time, error)
+	hooks       []func(context.Context, int32) (time.Time, error)
+	history     []UserStoreTemporaryAdminExpiresAtFuncCall
+	mutex       sync.Mutex
+}
+
+// TemporaryAdminExpiresAt delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockUserStore) TemporaryAdminExpiresAt(v0 context.Context, v1 int32) (time.Time, error) {
+	r0, r1 := m.TemporaryAdminExpiresAtFunc.nextHook()(v0, v1)
+	m.TemporaryAdminExpiresAtFunc.appendCall(UserStoreTemporaryAdminExpiresAtFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// TemporaryAdminExpiresAt method of the parent MockUserStore instance is
+// invoked and the hook queue is empty.
+func (f *UserStoreTemporaryAdminExpiresAtFunc) SetDefaultHook(hook func(context.Context, int32) (time.Time, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// TemporaryAdminExpiresAt method of the parent MockUserStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *UserStoreTemporaryAdminExpiresAtFunc) PushHook(hook func(context.Context, int32) (time.Time, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UserStoreTemporaryAdminExpiresAtFunc) SetDefaultReturn(r0 time.Time, r1 error) {
+	f.SetDefaultHook(func(context.Context, int32) (time.Time, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UserStoreTemporaryAdminExpiresAtFunc) PushReturn(r0 time.Time, r1 error) {
+	f.PushHook(func(context.Context, int32) (time.Time, error) {
+		return r0, r1
+	})
+}
+
+func (f *UserStoreTemporaryAdminExpiresAtFunc) nextHook() func(context.Context, int32) (time.Time, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UserStoreTemporaryAdminExpiresAtFunc) appendCall(r0 UserStoreTemporaryAdminExpiresAtFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UserStoreTemporaryAdminExpiresAtFuncCall
+// objects describing the invocations of this function.
+func (f *UserStoreTemporaryAdminExpiresAtFunc) History() []UserStoreTemporaryAdminExpiresAtFuncCall {
+	f.mutex.Lock()
+	history := make([]UserStoreTemporaryAdminExpiresAtFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UserStoreTemporaryAdminExpiresAtFuncCall is an object that describes an
+// invocation of method TemporaryAdminExpiresAt on an instance of
+// MockUserStore.
+type UserStoreTemporaryAdminExpiresAtFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int32
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 time.Time
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UserStoreTemporaryAdminExpiresAtFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UserStoreTemporaryAdminExpiresAtFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UserStoreTransactFunc describes the behavior when the Transact method of
 // the parent MockUserStore instance is invoked.
 type UserStoreTransactFunc struct {
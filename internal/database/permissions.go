@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -45,6 +46,9 @@ type PermissionStore interface {
 	FetchAll(ctx context.Context) ([]*types.Permission, error)
 	// GetByID returns the permission matching the given ID, or PermissionNotFoundErr if no such record exists.
 	GetByID(ctx context.Context, opts GetPermissionOpts) (*types.Permission, error)
+	// GrantedToUsers returns, for each of the given users, which of the given permissions they are
+	// granted by their active role assignments. Users who are granted none of them are omitted.
+	GrantedToUsers(ctx context.Context, userIDs, permissionIDs []int32) (map[int32][]int32, error)
 	// List returns all the permissions in the database that matches the options.
 	List(ctx context.Context, opts PermissionListOpts) ([]*types.Permission, error)
 }
@@ -68,6 +72,11 @@ type PermissionListOpts struct {
 
 	RoleID int32
 	UserID int32
+	// Query, if set, only matches permissions whose namespace or action contains
+	// it (case-insensitively).
+	Query string
+	// Namespace, if set, only matches permissions in this namespace.
+	Namespace types.PermissionNamespace
 }
 
 type PermissionNotFoundErr struct {
@@ -285,6 +294,46 @@ func (p *permissionStore) FetchAll(ctx context.Context) ([]*types.Permission, er
 	return permissions, rows.Err()
 }
 
+const permissionGrantedToUsersQueryFmtStr = `
+SELECT DISTINCT user_roles.user_id, role_permissions.permission_id
+FROM user_roles
+INNER JOIN role_permissions ON role_permissions.role_id = user_roles.role_id
+WHERE
+	user_roles.user_id = ANY(%s)
+	AND role_permissions.permission_id = ANY(%s)
+	AND %s
+`
+
+func (p *permissionStore) GrantedToUsers(ctx context.Context, userIDs, permissionIDs []int32) (map[int32][]int32, error) {
+	granted := make(map[int32][]int32)
+	if len(userIDs) == 0 || len(permissionIDs) == 0 {
+		return granted, nil
+	}
+
+	query := sqlf.Sprintf(
+		permissionGrantedToUsersQueryFmtStr,
+		pq.Array(userIDs),
+		pq.Array(permissionIDs),
+		activeUserRoleCond,
+	)
+
+	rows, err := p.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "error running query")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, permissionID int32
+		if err := rows.Scan(&userID, &permissionID); err != nil {
+			return nil, errors.Wrap(err, "scanning granted permission")
+		}
+		granted[userID] = append(granted[userID], permissionID)
+	}
+
+	return granted, rows.Err()
+}
+
 const permissionListQueryFmtStr = `
 SELECT %s FROM permissions
 %s
@@ -360,13 +409,22 @@ func (p *permissionStore) computeConditionsAndJoins(opts PermissionListOpts) ([]
 	}
 
 	if opts.UserID != 0 {
-		conds = append(conds, sqlf.Sprintf("user_roles.user_id = %s", opts.UserID))
+		conds = append(conds, sqlf.Sprintf("user_roles.user_id = %s", opts.UserID), activeUserRoleCond)
 		joins = sqlf.Sprintf(`
 INNER JOIN role_permissions ON role_permissions.permission_id = permissions.id
 INNER JOIN user_roles ON user_roles.role_id = role_permissions.role_id
 `)
 	}
 
+	if opts.Query != "" {
+		q := "%" + opts.Query + "%"
+		conds = append(conds, sqlf.Sprintf("(permissions.namespace ILIKE %s OR permissions.action ILIKE %s)", q, q))
+	}
+
+	if opts.Namespace != "" {
+		conds = append(conds, sqlf.Sprintf("permissions.namespace = %s", opts.Namespace))
+	}
+
 	return conds, joins
 }
 
@@ -157,6 +157,52 @@ func TestPermissionList(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, ps, 2)
 	})
+
+	t.Run("with query", func(t *testing.T) {
+		// Matches the actions READ-1 and READ-10.
+		ps, err := store.List(ctx, PermissionListOpts{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			Query: "read-1",
+		})
+
+		require.NoError(t, err)
+		require.Len(t, ps, 2)
+
+		// Matches the namespace of all permissions.
+		ps, err = store.List(ctx, PermissionListOpts{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			Query: string(types.BatchChangesNamespace),
+		})
+
+		require.NoError(t, err)
+		require.Len(t, ps, totalPerms)
+	})
+
+	t.Run("with namespace", func(t *testing.T) {
+		ps, err := store.List(ctx, PermissionListOpts{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			Namespace: types.BatchChangesNamespace,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, ps, totalPerms)
+
+		ps, err = store.List(ctx, PermissionListOpts{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			Namespace: types.RBACNamespace,
+		})
+
+		require.NoError(t, err)
+		require.Empty(t, ps)
+	})
 }
 
 func TestPermissionDelete(t *testing.T) {
@@ -342,6 +388,43 @@ func TestPermissionFetchAll(t *testing.T) {
 	require.Len(t, perms, totalPerms)
 }
 
+func TestPermissionGrantedToUsers(t *testing.T) {
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.Permissions()
+
+	perms, _ := createTestPermissions(ctx, t, store)
+	user := createTestUserForUserRole(ctx, "test@test.com", "test-user-1", t, db)
+	otherUser := createTestUserForUserRole(ctx, "test2@test.com", "test-user-2", t, db)
+
+	// The user is granted perms[0] and perms[1] by their role.
+	role, err := createTestRole(ctx, "TEST-ROLE", false, t, db.Roles())
+	require.NoError(t, err)
+	for _, perm := range perms[:2] {
+		_, err = db.RolePermissions().Assign(ctx, AssignRolePermissionOpts{RoleID: role.ID, PermissionID: perm.ID})
+		require.NoError(t, err)
+	}
+	_, err = db.UserRoles().Assign(ctx, AssignUserRoleOpts{RoleID: role.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	t.Run("no users or permissions", func(t *testing.T) {
+		granted, err := store.GrantedToUsers(ctx, nil, []int32{perms[0].ID})
+		require.NoError(t, err)
+		require.Empty(t, granted)
+
+		granted, err = store.GrantedToUsers(ctx, []int32{user.ID}, nil)
+		require.NoError(t, err)
+		require.Empty(t, granted)
+	})
+
+	t.Run("granted subset", func(t *testing.T) {
+		granted, err := store.GrantedToUsers(ctx, []int32{user.ID, otherUser.ID}, []int32{perms[0].ID, perms[2].ID})
+		require.NoError(t, err)
+		require.Equal(t, map[int32][]int32{user.ID: {perms[0].ID}}, granted)
+	})
+}
+
 func seedPermissionDataForList(ctx context.Context, t *testing.T, store PermissionStore, db DB) (*types.Role, *types.User, int) {
 	t.Helper()
 
@@ -35,6 +35,7 @@ var roleColumns = []*sqlf.Query{
 	sqlf.Sprintf("roles.name"),
 	sqlf.Sprintf("roles.system"),
 	sqlf.Sprintf("roles.created_at"),
+	sqlf.Sprintf("roles.updated_at"),
 }
 
 var roleInsertColumns = []*sqlf.Query{
@@ -74,11 +75,25 @@ type (
 	GetRoleOpts    RoleOpts
 )
 
+// RoleUserCountColumn is an expression that evaluates to the number of users a role is
+// actively assigned to. It can be used in OrderBy options when listing roles.
+const RoleUserCountColumn = `(SELECT COUNT(*) FROM user_roles INNER JOIN users ON users.id = user_roles.user_id WHERE user_roles.role_id = roles.id AND users.deleted_at IS NULL AND (user_roles.expires_at IS NULL OR user_roles.expires_at > NOW()))`
+
+// RoleChangedAtColumn is an expression that evaluates to the time a role was last created
+// or updated. It can be used in OrderBy options when listing roles.
+const RoleChangedAtColumn = `COALESCE(roles.updated_at, roles.created_at)`
+
 type RolesListOptions struct {
 	PaginationArgs *PaginationArgs
 
 	System bool
-	UserID int32
+	// ExcludeSystem, if set, only matches roles that aren't system roles.
+	ExcludeSystem bool
+	// WithoutPermissions, if set, only matches roles that have no permissions assigned to them.
+	WithoutPermissions bool
+	UserID             int32
+	// Query, if set, only matches roles whose name contains it (case-insensitively).
+	Query string
 }
 
 type RoleNotFoundErr struct {
@@ -147,6 +162,7 @@ func scanRole(sc dbutil.Scanner) (*types.Role, error) {
 		&role.Name,
 		&role.System,
 		&role.CreatedAt,
+		&dbutil.NullTime{Time: &role.UpdatedAt},
 	); err != nil {
 		return nil, err
 	}
@@ -216,11 +232,23 @@ func (r *roleStore) computeConditionsAndJoins(opts RolesListOptions) ([]*sqlf.Qu
 		conds = append(conds, sqlf.Sprintf("system IS TRUE"))
 	}
 
+	if opts.ExcludeSystem {
+		conds = append(conds, sqlf.Sprintf("system IS FALSE"))
+	}
+
+	if opts.WithoutPermissions {
+		conds = append(conds, sqlf.Sprintf("NOT EXISTS (SELECT 1 FROM role_permissions WHERE role_permissions.role_id = roles.id)"))
+	}
+
 	if opts.UserID != 0 {
-		conds = append(conds, sqlf.Sprintf("user_roles.user_id = %s", opts.UserID))
+		conds = append(conds, sqlf.Sprintf("user_roles.user_id = %s", opts.UserID), activeUserRoleCond)
 		joins = sqlf.Sprintf("INNER JOIN user_roles ON user_roles.role_id = roles.id")
 	}
 
+	if opts.Query != "" {
+		conds = append(conds, sqlf.Sprintf("roles.name ILIKE %s", "%"+opts.Query+"%"))
+	}
+
 	if len(conds) == 0 {
 		conds = append(conds, sqlf.Sprintf("TRUE"))
 	}
@@ -282,7 +310,8 @@ func (r *roleStore) Count(ctx context.Context, opts RolesListOptions) (c int, er
 const roleUpdateQueryFmtstr = `
 UPDATE roles
 SET
-    name = %s
+    name = %s,
+    updated_at = NOW()
 WHERE
 	id = %s AND NOT system
 RETURNING
@@ -18,7 +18,7 @@ import (
 //
 // These roles come by default on any sourcegraph instance and will always exist in the database,
 // so we need to account for these roles when accessing the database.
-var numberOfSystemRoles = 2
+var numberOfSystemRoles = 3
 
 func TestRoleGet(t *testing.T) {
 	ctx := context.Background()
@@ -95,6 +95,46 @@ func TestRoleList(t *testing.T) {
 		require.Len(t, allSystemRoles, numberOfSystemRoles)
 	})
 
+	t.Run("non-system roles", func(t *testing.T) {
+		nonSystemRoles, err := store.List(ctx, RolesListOptions{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			ExcludeSystem: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, nonSystemRoles, total)
+		for _, role := range nonSystemRoles {
+			require.False(t, role.System)
+		}
+	})
+
+	t.Run("roles without permissions", func(t *testing.T) {
+		permission, err := db.Permissions().Create(ctx, CreatePermissionOpts{
+			Namespace: types.BatchChangesNamespace,
+			Action:    "READ",
+		})
+		require.NoError(t, err)
+		_, err = db.RolePermissions().Assign(ctx, AssignRolePermissionOpts{
+			RoleID:       roles[0].ID,
+			PermissionID: permission.ID,
+		})
+		require.NoError(t, err)
+
+		emptyRoles, err := store.List(ctx, RolesListOptions{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			ExcludeSystem:      true,
+			WithoutPermissions: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, emptyRoles, total-1)
+		for _, role := range emptyRoles {
+			require.NotEqual(t, roles[0].ID, role.ID)
+		}
+	})
+
 	t.Run("with pagination", func(t *testing.T) {
 		firstParam := 2
 		roles, err := store.List(ctx, RolesListOptions{
@@ -118,6 +158,19 @@ func TestRoleList(t *testing.T) {
 		require.Len(t, userRoles, 1)
 		require.Equal(t, userRoles[0].ID, roles[0].ID)
 	})
+
+	t.Run("with query", func(t *testing.T) {
+		// Matches TESTROLE-1 and TESTROLE-10.
+		matchingRoles, err := store.List(ctx, RolesListOptions{
+			PaginationArgs: &PaginationArgs{
+				First: &firstParam,
+			},
+			Query: "testrole-1",
+		})
+		require.NoError(t, err)
+		require.Len(t, matchingRoles, 2)
+		require.ElementsMatch(t, []int32{roles[0].ID, roles[9].ID}, []int32{matchingRoles[0].ID, matchingRoles[1].ID})
+	})
 }
 
 func TestRoleCreate(t *testing.T) {
@@ -192,12 +245,14 @@ func TestRoleUpdate(t *testing.T) {
 	t.Run("existing role", func(t *testing.T) {
 		role, err := createTestRole(ctx, "TEST ROLE 1", false, t, store)
 		require.NoError(t, err)
+		require.True(t, role.UpdatedAt.IsZero())
 
 		role.Name = "TEST ROLE 2"
 		updated, err := store.Update(ctx, role)
 		require.NoError(t, err)
 		require.NotNil(t, updated)
 		require.Equal(t, role.Name, "TEST ROLE 2")
+		require.False(t, updated.UpdatedAt.IsZero())
 	})
 }
 
@@ -12,6 +12,7 @@ import (
 	sgactor "github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/version"
@@ -42,6 +43,11 @@ const (
 	SecurityEventNameRoleChangeDenied  SecurityEventName = "RoleChangeDenied"
 	SecurityEventNameRoleChangeGranted SecurityEventName = "RoleChangeGranted"
 
+	SecurityEventNameRBACRoleDeleted           SecurityEventName = "RBACRoleDeleted"
+	SecurityEventNameRBACRolesBulkDeleted      SecurityEventName = "RBACRolesBulkDeleted"
+	SecurityEventNameRBACRolePermissionRevoked SecurityEventName = "RBACRolePermissionRevoked"
+	SecurityEventNameRBACUserRoleRevoked       SecurityEventName = "RBACUserRoleRevoked"
+
 	SecurityEventNameAccessGranted SecurityEventName = "AccessGranted"
 
 	SecurityEventAccessTokenCreated             SecurityEventName = "AccessTokenCreated"
@@ -96,6 +102,27 @@ type SecurityEventLogsStore interface {
 	LogEvent(ctx context.Context, e *SecurityEvent)
 	// Bulk "LogEvent" action.
 	LogEventList(ctx context.Context, events []*SecurityEvent)
+	// List returns the security events matching the given options, most
+	// recent first.
+	List(ctx context.Context, opts SecurityEventLogsListOptions) ([]*SecurityEvent, error)
+}
+
+// SecurityEventLogsListOptions specifies the options for listing security
+// events.
+type SecurityEventLogsListOptions struct {
+	// Names, if set, only includes events with one of these names.
+	Names []SecurityEventName
+	// UserID, if set, only includes events of this user.
+	UserID uint32
+	// Since, if set, only includes events at or after this time.
+	Since time.Time
+	// Until, if set, only includes events before this time.
+	Until time.Time
+	// ArgumentContains, if set, only includes events whose argument contains its
+	// JSON encoding, like the jsonb @> operator.
+	ArgumentContains any
+
+	*LimitOffset
 }
 
 type securityEventLogsStore struct {
@@ -197,3 +224,51 @@ func (s *securityEventLogsStore) LogEventList(ctx context.Context, events []*Sec
 		}
 	}
 }
+
+func (s *securityEventLogsStore) List(ctx context.Context, opts SecurityEventLogsListOptions) ([]*SecurityEvent, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if len(opts.Names) > 0 {
+		names := make([]*sqlf.Query, 0, len(opts.Names))
+		for _, name := range opts.Names {
+			names = append(names, sqlf.Sprintf("%s", name))
+		}
+		conds = append(conds, sqlf.Sprintf("name IN (%s)", sqlf.Join(names, ",")))
+	}
+	if opts.UserID != 0 {
+		conds = append(conds, sqlf.Sprintf("user_id = %s", opts.UserID))
+	}
+	if !opts.Since.IsZero() {
+		conds = append(conds, sqlf.Sprintf("timestamp >= %s", opts.Since.UTC()))
+	}
+	if !opts.Until.IsZero() {
+		conds = append(conds, sqlf.Sprintf("timestamp < %s", opts.Until.UTC()))
+	}
+	if opts.ArgumentContains != nil {
+		argument, err := json.Marshal(opts.ArgumentContains)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling argument")
+		}
+		conds = append(conds, sqlf.Sprintf("argument @> %s::jsonb", string(argument)))
+	}
+
+	q := sqlf.Sprintf(listSecurityEventsQueryFmtStr, sqlf.Join(conds, "AND"), opts.LimitOffset.SQL())
+	return scanSecurityEvents(s.Query(ctx, q))
+}
+
+const listSecurityEventsQueryFmtStr = `
+SELECT name, url, user_id, anonymous_user_id, argument, source, timestamp
+FROM security_event_logs
+WHERE %s
+ORDER BY timestamp DESC, id DESC
+%s
+`
+
+var scanSecurityEvents = basestore.NewSliceScanner(func(s dbutil.Scanner) (*SecurityEvent, error) {
+	var e SecurityEvent
+	var argument []byte
+	if err := s.Scan(&e.Name, &e.URL, &e.UserID, &e.AnonymousUserID, &argument, &e.Source, &e.Timestamp); err != nil {
+		return nil, err
+	}
+	e.Argument = json.RawMessage(argument)
+	return &e, nil
+})
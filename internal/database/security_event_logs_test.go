@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -94,6 +96,56 @@ func TestSecurityEventLogs_ValidInfo(t *testing.T) {
 	}
 }
 
+func TestSecurityEventLogs_List(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Second)
+	events := []*SecurityEvent{
+		{Name: "a", UserID: 1, Argument: json.RawMessage(`{"roleID": 1}`), Source: "BACKEND", Timestamp: now.Add(-2 * time.Hour)},
+		{Name: "b", UserID: 1, Argument: json.RawMessage(`{"roleID": 2, "userID": 1}`), Source: "BACKEND", Timestamp: now.Add(-time.Hour)},
+		{Name: "a", UserID: 2, Source: "BACKEND", Timestamp: now},
+	}
+	if err := db.SecurityEventLogs().InsertList(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(events []*SecurityEvent) (names []string) {
+		for _, e := range events {
+			names = append(names, fmt.Sprintf("%s:%d", e.Name, e.UserID))
+		}
+		return names
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts SecurityEventLogsListOptions
+		want []string
+	}{
+		{name: "all", want: []string{"a:2", "b:1", "a:1"}},
+		{name: "by name", opts: SecurityEventLogsListOptions{Names: []SecurityEventName{"a"}}, want: []string{"a:2", "a:1"}},
+		{name: "by user", opts: SecurityEventLogsListOptions{UserID: 1}, want: []string{"b:1", "a:1"}},
+		{name: "since", opts: SecurityEventLogsListOptions{Since: now.Add(-time.Hour)}, want: []string{"a:2", "b:1"}},
+		{name: "until", opts: SecurityEventLogsListOptions{Until: now.Add(-time.Hour)}, want: []string{"a:1"}},
+		{name: "by argument", opts: SecurityEventLogsListOptions{ArgumentContains: map[string]any{"roleID": 2}}, want: []string{"b:1"}},
+		{name: "limit", opts: SecurityEventLogsListOptions{LimitOffset: &LimitOffset{Limit: 2}}, want: []string{"a:2", "b:1"}},
+		{name: "limit and offset", opts: SecurityEventLogsListOptions{LimitOffset: &LimitOffset{Limit: 2, Offset: 2}}, want: []string{"a:1"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := db.SecurityEventLogs().List(ctx, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.want, names(got))
+		})
+	}
+}
+
 func filterAudit(logs []logtest.CapturedLog) []logtest.CapturedLog {
 	var filtered []logtest.CapturedLog
 	for _, log := range logs {
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -22,15 +24,26 @@ var userRoleColumns = []*sqlf.Query{
 	sqlf.Sprintf("user_roles.user_id"),
 	sqlf.Sprintf("user_roles.role_id"),
 	sqlf.Sprintf("user_roles.created_at"),
+	sqlf.Sprintf("user_roles.expires_at"),
 }
 
+// activeUserRoleCond excludes role assignments that have expired.
+var activeUserRoleCond = sqlf.Sprintf("(user_roles.expires_at IS NULL OR user_roles.expires_at > NOW())")
+
 type UserRoleOpts struct {
 	UserID int32
 	RoleID int32
 }
 
+type AssignUserRoleOpts struct {
+	UserID int32
+	RoleID int32
+	// ExpiresAt, if set, is the time after which the assignment is no longer
+	// active.
+	ExpiresAt time.Time
+}
+
 type (
-	AssignUserRoleOpts UserRoleOpts
 	RevokeUserRoleOpts UserRoleOpts
 	GetUserRoleOpts    UserRoleOpts
 )
@@ -45,6 +58,14 @@ type RevokeSystemRoleOpts struct {
 	Role   types.SystemRole
 }
 
+type ListRecentUserRolesOpts struct {
+	// OrgsOfUserID, if set, only includes the assignments of the members of the
+	// organizations this user is a member of.
+	OrgsOfUserID int32
+	// Limit is the maximum number of assignments to return.
+	Limit int
+}
+
 type BulkAssignToUserOpts struct {
 	UserID  int32
 	RoleIDs []int32
@@ -58,8 +79,14 @@ type BulkAssignSystemRolesToUserOpts struct {
 type UserRoleStore interface {
 	basestore.ShareableStore
 
-	// Assign is used to assign a role to a user.
+	// Assign is used to assign a role to a user. If the user already has the
+	// role, the existing assignment is returned unchanged, including its
+	// expiration.
 	Assign(ctx context.Context, opts AssignUserRoleOpts) (*types.UserRole, error)
+	// AssignWithExpiry assigns a role to a user until the given expiration. If
+	// the user already has the role, the expiration of the existing assignment
+	// is replaced, even if it was permanent.
+	AssignWithExpiry(ctx context.Context, opts AssignUserRoleOpts) (*types.UserRole, error)
 	// AssignSystemRole assigns a system role to a user.
 	AssignSystemRole(ctx context.Context, opts AssignSystemRoleOpts) (*types.UserRole, error)
 	// BulkAssignToUser assigns multiple roles to a single user. This is useful
@@ -74,6 +101,13 @@ type UserRoleStore interface {
 	GetByRoleIDAndUserID(ctx context.Context, opts GetUserRoleOpts) (*types.UserRole, error)
 	// GetByUserID returns all UserRole associated with the provided user ID
 	GetByUserID(ctx context.Context, opts GetUserRoleOpts) ([]*types.UserRole, error)
+	// GetByUserIDs returns all UserRole associated with any of the provided user IDs
+	GetByUserIDs(ctx context.Context, userIDs []int32) ([]*types.UserRole, error)
+	// ListRecent returns the most recent active role assignments, most recent first.
+	ListRecent(ctx context.Context, opts ListRecentUserRolesOpts) ([]*types.UserRole, error)
+	// DeleteExpired deletes all role assignments that have expired, and returns the
+	// deleted assignments.
+	DeleteExpired(ctx context.Context) ([]*types.UserRole, error)
 	// Revoke deletes the user and role relationship from the database.
 	Revoke(ctx context.Context, opts RevokeUserRoleOpts) error
 	// RevokeSystemRole revokes a system role that has previously being assigned to a user.
@@ -112,6 +146,31 @@ ON CONFLICT DO NOTHING
 RETURNING %s;
 `
 
+// Assigning a role that has already been assigned to the user leaves the
+// existing assignment, including its expiration, untouched, unless it has
+// already expired. The update is otherwise a no-op, so that the existing
+// assignment is returned.
+const userRoleAssignOnceQueryFmtStr = `
+INSERT INTO
+	user_roles (user_id, role_id, expires_at)
+VALUES (%s, %s, %s)
+ON CONFLICT (user_id, role_id) DO UPDATE SET expires_at = CASE
+	WHEN user_roles.expires_at <= NOW() THEN EXCLUDED.expires_at
+	ELSE user_roles.expires_at
+END
+RETURNING %s;
+`
+
+// Assigning a role that has already been assigned to the user updates the
+// expiration of the existing assignment.
+const userRoleAssignWithExpiryQueryFmtStr = `
+INSERT INTO
+	user_roles (user_id, role_id, expires_at)
+VALUES (%s, %s, %s)
+ON CONFLICT (user_id, role_id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+RETURNING %s;
+`
+
 func (r *userRoleStore) Assign(ctx context.Context, opts AssignUserRoleOpts) (*types.UserRole, error) {
 	if opts.UserID == 0 {
 		return nil, errors.New("missing user id")
@@ -121,10 +180,47 @@ func (r *userRoleStore) Assign(ctx context.Context, opts AssignUserRoleOpts) (*t
 		return nil, errors.New("missing role id")
 	}
 
+	// 🚨 SECURITY: The ADMIN system role grants site admin privileges, so it must
+	// never be assigned permanently.
+	if opts.ExpiresAt.IsZero() {
+		if err := r.checkNotAdminRole(ctx, opts.RoleID); err != nil {
+			return nil, err
+		}
+	}
+
 	q := sqlf.Sprintf(
-		userRoleAssignQueryFmtStr,
-		sqlf.Join(userRoleInsertColumns, ", "),
-		sqlf.Sprintf("( %s, %s )", opts.UserID, opts.RoleID),
+		userRoleAssignOnceQueryFmtStr,
+		opts.UserID,
+		opts.RoleID,
+		dbutil.NullTimeColumn(opts.ExpiresAt),
+		sqlf.Join(userRoleColumns, ", "),
+	)
+
+	rm, err := scanUserRole(r.QueryRow(ctx, q))
+	if err != nil {
+		return nil, errors.Wrap(err, "scanning user role")
+	}
+	return rm, nil
+}
+
+func (r *userRoleStore) AssignWithExpiry(ctx context.Context, opts AssignUserRoleOpts) (*types.UserRole, error) {
+	if opts.UserID == 0 {
+		return nil, errors.New("missing user id")
+	}
+
+	if opts.RoleID == 0 {
+		return nil, errors.New("missing role id")
+	}
+
+	if opts.ExpiresAt.IsZero() {
+		return nil, errors.New("missing expiration")
+	}
+
+	q := sqlf.Sprintf(
+		userRoleAssignWithExpiryQueryFmtStr,
+		opts.UserID,
+		opts.RoleID,
+		opts.ExpiresAt,
 		sqlf.Join(userRoleColumns, ", "),
 	)
 
@@ -144,6 +240,12 @@ func (r *userRoleStore) AssignSystemRole(ctx context.Context, opts AssignSystemR
 		return nil, errors.New("role is required")
 	}
 
+	// 🚨 SECURITY: System roles are assigned permanently, which the ADMIN system
+	// role must never be.
+	if opts.Role == types.AdminSystemRole {
+		return nil, ErrPermanentAdminRole
+	}
+
 	roleQuery := sqlf.Sprintf("SELECT id FROM roles WHERE name = %s", opts.Role)
 
 	q := sqlf.Sprintf(
@@ -169,6 +271,12 @@ func (r *userRoleStore) BulkAssignToUser(ctx context.Context, opts BulkAssignToU
 		return nil, errors.New("missing role ids")
 	}
 
+	// 🚨 SECURITY: The roles are assigned permanently, which the ADMIN system role
+	// must never be.
+	if err := r.checkNotAdminRole(ctx, opts.RoleIDs...); err != nil {
+		return nil, err
+	}
+
 	var urs []*sqlf.Query
 
 	for _, roleId := range opts.RoleIDs {
@@ -195,6 +303,14 @@ func (r *userRoleStore) BulkAssignSystemRolesToUser(ctx context.Context, opts Bu
 		return nil, errors.New("roles are required")
 	}
 
+	// 🚨 SECURITY: System roles are assigned permanently, which the ADMIN system
+	// role must never be.
+	for _, role := range opts.Roles {
+		if role == types.AdminSystemRole {
+			return nil, ErrPermanentAdminRole
+		}
+	}
+
 	var urs []*sqlf.Query
 	for _, role := range opts.Roles {
 		roleQuery := sqlf.Sprintf("SELECT id FROM roles WHERE name = %s", role)
@@ -212,6 +328,29 @@ func (r *userRoleStore) BulkAssignSystemRolesToUser(ctx context.Context, opts Bu
 	return scanUserRoles(r.Query(ctx, q))
 }
 
+// ErrPermanentAdminRole is returned when the ADMIN system role would be assigned
+// without an expiration. The ADMIN role grants site admin privileges, so it can
+// only be assigned temporarily.
+var ErrPermanentAdminRole = errors.Newf("the %s role can only be assigned with an expiration", types.AdminSystemRole)
+
+// checkNotAdminRole returns ErrPermanentAdminRole if any of the given roles is
+// the ADMIN system role.
+func (r *userRoleStore) checkNotAdminRole(ctx context.Context, roleIDs ...int32) error {
+	q := sqlf.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM roles WHERE id = ANY(%s) AND system AND name = %s)",
+		pq.Array(roleIDs),
+		types.AdminSystemRole,
+	)
+	isAdminRole, _, err := basestore.ScanFirstBool(r.Query(ctx, q))
+	if err != nil {
+		return err
+	}
+	if isAdminRole {
+		return ErrPermanentAdminRole
+	}
+	return nil
+}
+
 type UserRoleNotFoundErr struct {
 	UserID int32
 	RoleID int32
@@ -265,6 +404,23 @@ func (r *userRoleStore) Revoke(ctx context.Context, opts RevokeUserRoleOpts) err
 	return nil
 }
 
+const deleteExpiredUserRolesQuery = `
+DELETE FROM user_roles
+WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+RETURNING %s
+`
+
+func (r *userRoleStore) DeleteExpired(ctx context.Context) ([]*types.UserRole, error) {
+	q := sqlf.Sprintf(deleteExpiredUserRolesQuery, sqlf.Join(userRoleColumns, ", "))
+
+	var scanUserRoles = basestore.NewSliceScanner(scanUserRole)
+	userRoles, err := scanUserRoles(r.Query(ctx, q))
+	if err != nil {
+		return nil, errors.Wrap(err, "deleting expired user roles")
+	}
+	return userRoles, nil
+}
+
 func (r *userRoleStore) RevokeSystemRole(ctx context.Context, opts RevokeSystemRoleOpts) error {
 	if opts.UserID == 0 {
 		return errors.New("userID is required")
@@ -296,6 +452,13 @@ func (r *userRoleStore) GetByUserID(ctx context.Context, opts GetUserRoleOpts) (
 	return r.get(ctx, sqlf.Sprintf("user_id = %s", opts.UserID))
 }
 
+func (r *userRoleStore) GetByUserIDs(ctx context.Context, userIDs []int32) ([]*types.UserRole, error) {
+	if len(userIDs) == 0 {
+		return []*types.UserRole{}, nil
+	}
+	return r.get(ctx, sqlf.Sprintf("user_roles.user_id = ANY(%s)", pq.Array(userIDs)))
+}
+
 func (r *userRoleStore) GetByRoleID(ctx context.Context, opts GetUserRoleOpts) ([]*types.UserRole, error) {
 	if opts.RoleID == 0 {
 		return nil, errors.New("missing role id")
@@ -316,7 +479,7 @@ func (r *userRoleStore) GetByRoleIDAndUserID(ctx context.Context, opts GetUserRo
 		getUserRoleQueryFmtStr,
 		sqlf.Join(userRoleColumns, ", "),
 		sqlf.Sprintf("INNER JOIN users ON user_roles.user_id = users.id"),
-		sqlf.Sprintf("users.deleted_at IS NULL AND user_roles.role_id = %s AND user_roles.user_id = %s", opts.RoleID, opts.UserID),
+		sqlf.Sprintf("users.deleted_at IS NULL AND user_roles.role_id = %s AND user_roles.user_id = %s AND %s", opts.RoleID, opts.UserID, activeUserRoleCond),
 	)
 
 	ur, err := scanUserRole(r.QueryRow(ctx, q))
@@ -330,12 +493,49 @@ func (r *userRoleStore) GetByRoleIDAndUserID(ctx context.Context, opts GetUserRo
 	return ur, nil
 }
 
+const listRecentUserRolesQueryFmtStr = `
+SELECT
+	%s
+FROM user_roles
+INNER JOIN users ON user_roles.user_id = users.id
+WHERE %s
+ORDER BY user_roles.created_at DESC, user_roles.user_id ASC, user_roles.role_id ASC
+LIMIT %s
+`
+
+const orgsOfUserMembersCond = `
+user_roles.user_id IN (
+	SELECT members.user_id
+	FROM org_members members
+	INNER JOIN org_members self ON self.org_id = members.org_id
+	WHERE self.user_id = %s
+)
+`
+
+func (r *userRoleStore) ListRecent(ctx context.Context, opts ListRecentUserRolesOpts) ([]*types.UserRole, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("users.deleted_at IS NULL"), activeUserRoleCond}
+	if opts.OrgsOfUserID != 0 {
+		conds = append(conds, sqlf.Sprintf(orgsOfUserMembersCond, opts.OrgsOfUserID))
+	}
+
+	q := sqlf.Sprintf(
+		listRecentUserRolesQueryFmtStr,
+		sqlf.Join(userRoleColumns, ", "),
+		sqlf.Join(conds, " AND "),
+		opts.Limit,
+	)
+
+	var scanUserRoles = basestore.NewSliceScanner(scanUserRole)
+	return scanUserRoles(r.Query(ctx, q))
+}
+
 func scanUserRole(sc dbutil.Scanner) (*types.UserRole, error) {
 	var rm types.UserRole
 	if err := sc.Scan(
 		&rm.UserID,
 		&rm.RoleID,
 		&rm.CreatedAt,
+		&dbutil.NullTime{Time: &rm.ExpiresAt},
 	); err != nil {
 		return nil, err
 	}
@@ -353,7 +553,7 @@ WHERE %s
 `
 
 func (r *userRoleStore) get(ctx context.Context, cond *sqlf.Query) ([]*types.UserRole, error) {
-	conds := sqlf.Sprintf("%s AND users.deleted_at IS NULL", cond)
+	conds := sqlf.Sprintf("%s AND users.deleted_at IS NULL AND %s", cond, activeUserRoleCond)
 	q := sqlf.Sprintf(
 		getUserRoleQueryFmtStr,
 		sqlf.Join(userRoleColumns, ", "),
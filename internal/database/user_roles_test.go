@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/log/logtest"
 	"github.com/stretchr/testify/require"
@@ -322,6 +323,40 @@ func TestUserRoleGetByUserID(t *testing.T) {
 	})
 }
 
+func TestUserRoleGetByUserIDs(t *testing.T) {
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.UserRoles()
+
+	user := createTestUserForUserRole(ctx, "testuser@example.com", "TESTUSER", t, db)
+	otherUser := createTestUserForUserRole(ctx, "otheruser@example.com", "OTHERUSER", t, db)
+	unrelatedUser := createTestUserForUserRole(ctx, "unrelated@example.com", "UNRELATEDUSER", t, db)
+
+	role := createTestRoleForUserRole(ctx, "TESTROLE", t, db)
+	for _, userID := range []int32{user.ID, otherUser.ID, unrelatedUser.ID} {
+		_, err := store.Assign(ctx, AssignUserRoleOpts{RoleID: role.ID, UserID: userID})
+		require.NoError(t, err)
+	}
+
+	t.Run("no user ids", func(t *testing.T) {
+		urs, err := store.GetByUserIDs(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, urs)
+	})
+
+	t.Run("with provided user ids", func(t *testing.T) {
+		urs, err := store.GetByUserIDs(ctx, []int32{user.ID, otherUser.ID})
+		require.NoError(t, err)
+
+		var userIDs []int32
+		for _, ur := range urs {
+			userIDs = append(userIDs, ur.UserID)
+		}
+		require.ElementsMatch(t, []int32{user.ID, otherUser.ID}, userIDs)
+	})
+}
+
 func TestUserRoleGetByRoleIDAndUserID(t *testing.T) {
 	ctx := context.Background()
 	logger := logtest.Scoped(t)
@@ -367,6 +402,238 @@ func TestUserRoleGetByRoleIDAndUserID(t *testing.T) {
 	})
 }
 
+func TestUserRoleExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.UserRoles()
+
+	user, role := createUserAndRole(ctx, t, db)
+	expiredRole := createTestRoleForUserRole(ctx, "EXPIRED", t, db)
+
+	_, err := store.Assign(ctx, AssignUserRoleOpts{
+		UserID:    user.ID,
+		RoleID:    role.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Assign(ctx, AssignUserRoleOpts{
+		UserID:    user.ID,
+		RoleID:    expiredRole.ID,
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("non-expired assignment is active", func(t *testing.T) {
+		ur, err := store.GetByRoleIDAndUserID(ctx, GetUserRoleOpts{UserID: user.ID, RoleID: role.ID})
+		require.NoError(t, err)
+		require.False(t, ur.ExpiresAt.IsZero())
+
+		roles, err := db.Roles().List(ctx, RolesListOptions{UserID: user.ID})
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		require.Equal(t, role.ID, roles[0].ID)
+	})
+
+	t.Run("expired assignment is inactive", func(t *testing.T) {
+		_, err := store.GetByRoleIDAndUserID(ctx, GetUserRoleOpts{UserID: user.ID, RoleID: expiredRole.ID})
+		require.Error(t, err)
+		require.ErrorAs(t, err, new(*UserRoleNotFoundErr))
+
+		urs, err := store.GetByUserID(ctx, GetUserRoleOpts{UserID: user.ID})
+		require.NoError(t, err)
+		require.Len(t, urs, 1)
+		require.Equal(t, role.ID, urs[0].RoleID)
+	})
+
+	t.Run("reassigning keeps the expiry", func(t *testing.T) {
+		before, err := store.GetByRoleIDAndUserID(ctx, GetUserRoleOpts{UserID: user.ID, RoleID: role.ID})
+		require.NoError(t, err)
+
+		// A temporary assignment must not silently become permanent.
+		ur, err := store.Assign(ctx, AssignUserRoleOpts{
+			UserID: user.ID,
+			RoleID: role.ID,
+		})
+		require.NoError(t, err)
+		require.True(t, before.ExpiresAt.Equal(ur.ExpiresAt))
+
+		after, err := store.GetByRoleIDAndUserID(ctx, GetUserRoleOpts{UserID: user.ID, RoleID: role.ID})
+		require.NoError(t, err)
+		require.True(t, before.ExpiresAt.Equal(after.ExpiresAt))
+	})
+
+	t.Run("reassigning replaces an expired assignment", func(t *testing.T) {
+		ur, err := store.Assign(ctx, AssignUserRoleOpts{
+			UserID: user.ID,
+			RoleID: expiredRole.ID,
+		})
+		require.NoError(t, err)
+		require.True(t, ur.ExpiresAt.IsZero())
+
+		urs, err := store.GetByUserID(ctx, GetUserRoleOpts{UserID: user.ID})
+		require.NoError(t, err)
+		require.Len(t, urs, 2)
+	})
+
+	t.Run("assigning with expiry updates the expiry", func(t *testing.T) {
+		_, err := store.AssignWithExpiry(ctx, AssignUserRoleOpts{
+			UserID: user.ID,
+			RoleID: expiredRole.ID,
+		})
+		require.Error(t, err)
+
+		expiresAt := time.Now().Add(2 * time.Hour).Truncate(time.Microsecond)
+		ur, err := store.AssignWithExpiry(ctx, AssignUserRoleOpts{
+			UserID:    user.ID,
+			RoleID:    expiredRole.ID,
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+		require.True(t, expiresAt.Equal(ur.ExpiresAt))
+	})
+}
+
+func TestUserRoleDeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.UserRoles()
+
+	user, role := createUserAndRole(ctx, t, db)
+	expiredRole := createTestRoleForUserRole(ctx, "EXPIRED", t, db)
+
+	_, err := store.Assign(ctx, AssignUserRoleOpts{
+		UserID:    user.ID,
+		RoleID:    role.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Assign(ctx, AssignUserRoleOpts{
+		UserID:    user.ID,
+		RoleID:    expiredRole.ID,
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	deleted, err := store.DeleteExpired(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, user.ID, deleted[0].UserID)
+	require.Equal(t, expiredRole.ID, deleted[0].RoleID)
+
+	var userRoleCount int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_roles WHERE user_id = $1", user.ID).Scan(&userRoleCount)
+	require.NoError(t, err)
+	require.Equal(t, 1, userRoleCount)
+
+	// Revoking the expired assignment fails because the row no longer exists.
+	err = store.Revoke(ctx, RevokeUserRoleOpts{UserID: user.ID, RoleID: expiredRole.ID})
+	require.ErrorAs(t, err, new(*UserRoleNotFoundErr))
+}
+
+func TestUserRoleAssignAdminRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.UserRoles()
+
+	user, _ := createUserAndRole(ctx, t, db)
+	adminRole, err := db.Roles().Get(ctx, GetRoleOpts{Name: string(types.AdminSystemRole)})
+	require.NoError(t, err)
+
+	// The ADMIN role can never be assigned permanently.
+	_, err = store.Assign(ctx, AssignUserRoleOpts{UserID: user.ID, RoleID: adminRole.ID})
+	require.ErrorIs(t, err, ErrPermanentAdminRole)
+
+	_, err = store.BulkAssignToUser(ctx, BulkAssignToUserOpts{UserID: user.ID, RoleIDs: []int32{adminRole.ID}})
+	require.ErrorIs(t, err, ErrPermanentAdminRole)
+
+	_, err = store.AssignSystemRole(ctx, AssignSystemRoleOpts{UserID: user.ID, Role: types.AdminSystemRole})
+	require.ErrorIs(t, err, ErrPermanentAdminRole)
+
+	_, err = store.BulkAssignSystemRolesToUser(ctx, BulkAssignSystemRolesToUserOpts{UserID: user.ID, Roles: []types.SystemRole{types.AdminSystemRole}})
+	require.ErrorIs(t, err, ErrPermanentAdminRole)
+
+	expiresAt, err := db.Users().TemporaryAdminExpiresAt(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, expiresAt.IsZero())
+
+	// It can be assigned temporarily.
+	_, err = store.Assign(ctx, AssignUserRoleOpts{UserID: user.ID, RoleID: adminRole.ID, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	expiresAt, err = db.Users().TemporaryAdminExpiresAt(ctx, user.ID)
+	require.NoError(t, err)
+	require.False(t, expiresAt.IsZero())
+}
+
+func TestUserRoleListRecent(t *testing.T) {
+	ctx := context.Background()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	store := db.UserRoles()
+
+	// u1 and u2 are members of the organization, u3 isn't.
+	u1 := createTestUserForUserRole(ctx, "u1@example.com", "u1", t, db)
+	u2 := createTestUserForUserRole(ctx, "u2@example.com", "u2", t, db)
+	u3 := createTestUserForUserRole(ctx, "u3@example.com", "u3", t, db)
+	org, err := db.Orgs().Create(ctx, "org", nil)
+	require.NoError(t, err)
+	for _, u := range []*types.User{u1, u2} {
+		_, err := db.OrgMembers().Create(ctx, org.ID, u.ID)
+		require.NoError(t, err)
+	}
+
+	role := createTestRoleForUserRole(ctx, "TESTROLE", t, db)
+	for _, u := range []*types.User{u3, u1, u2} {
+		_, err := store.Assign(ctx, AssignUserRoleOpts{UserID: u.ID, RoleID: role.ID})
+		require.NoError(t, err)
+	}
+
+	// Only the assignments of the test role are compared, as users may also hold system roles.
+	assignees := func(urs []*types.UserRole) []int32 {
+		var ids []int32
+		for _, ur := range urs {
+			if ur.RoleID == role.ID {
+				ids = append(ids, ur.UserID)
+			}
+		}
+		return ids
+	}
+
+	t.Run("all users", func(t *testing.T) {
+		urs, err := store.ListRecent(ctx, ListRecentUserRolesOpts{Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, []int32{u2.ID, u1.ID, u3.ID}, assignees(urs))
+	})
+
+	t.Run("members of the organizations of a user", func(t *testing.T) {
+		urs, err := store.ListRecent(ctx, ListRecentUserRolesOpts{OrgsOfUserID: u1.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, []int32{u2.ID, u1.ID}, assignees(urs))
+
+		urs, err = store.ListRecent(ctx, ListRecentUserRolesOpts{OrgsOfUserID: u3.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Empty(t, urs)
+	})
+
+	t.Run("with limit", func(t *testing.T) {
+		urs, err := store.ListRecent(ctx, ListRecentUserRolesOpts{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, urs, 1)
+		require.Equal(t, u2.ID, urs[0].UserID)
+	})
+}
+
 func createUserAndRole(ctx context.Context, t *testing.T, db DB) (*types.User, *types.Role) {
 	t.Helper()
 	user := createTestUserForUserRole(ctx, "a1@example.com", "u1", t, db)
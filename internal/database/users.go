@@ -66,6 +66,7 @@ type UserStore interface {
 	Exec(ctx context.Context, query *sqlf.Query) error
 	ExecResult(ctx context.Context, query *sqlf.Query) (sql.Result, error)
 	GetByCurrentAuthUser(context.Context) (*types.User, error)
+	GetDeletedNonSCIMByUsername(context.Context, string) (*types.User, error)
 	GetByID(context.Context, int32) (*types.User, error)
 	GetByUsername(context.Context, string) (*types.User, error)
 	GetByUsernames(context.Context, ...string) ([]*types.User, error)
@@ -81,12 +82,16 @@ type UserStore interface {
 	ListDates(context.Context) ([]types.UserDates, error)
 	ListByOrg(ctx context.Context, orgID int32, paginationArgs *PaginationArgs, query *string) ([]*types.User, error)
 	RandomizePasswordAndClearPasswordResetRateLimit(context.Context, int32) error
+	ReclaimDeleted(ctx context.Context, id int32) error
 	RecoverUsersList(context.Context, []int32) (_ []int32, err error)
 	RenewPasswordResetCode(context.Context, int32) (string, error)
 	SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool) error
 	SetPassword(ctx context.Context, id int32, resetCode, newPassword string) (bool, error)
+	SetSCIMEnterpriseAttributes(ctx context.Context, id int32, attributes map[string]string) error
+	SetSCIMResourceID(ctx context.Context, id int32, resourceID string) error
 	SetTag(ctx context.Context, userID int32, tag string, present bool) error
 	Tags(context.Context, int32) (map[string]bool, error)
+	TemporaryAdminExpiresAt(ctx context.Context, id int32) (time.Time, error)
 	Transact(context.Context) (UserStore, error)
 	Update(context.Context, int32, UserUpdate) error
 	UpdatePassword(ctx context.Context, id int32, oldPassword, newPassword string) error
@@ -765,6 +770,55 @@ func (u *userStore) RecoverUsersList(ctx context.Context, ids []int32) (_ []int3
 	return updateIds, nil
 }
 
+// ReclaimDeleted recovers the soft-deleted user with the given ID so that the account can be taken over by someone
+// else, e.g. a new user from an identity provider with the same username. Unlike RecoverUsersList, nothing that lets
+// the previous owner sign in or act as the user comes back: access tokens and external accounts stay deleted, the
+// password is cleared, the user is no longer a site admin, all roles except USER are revoked and existing sessions
+// are invalidated.
+func (u *userStore) ReclaimDeleted(ctx context.Context, id int32) (err error) {
+	tx, err := u.transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	res, err := tx.ExecResult(ctx, sqlf.Sprintf(`
+UPDATE users
+SET
+	deleted_at = NULL,
+	updated_at = now(),
+	passwd = NULL,
+	passwd_reset_code = NULL,
+	passwd_reset_time = NULL,
+	site_admin = false,
+	invalidated_sessions_at = now()
+WHERE id = %s AND deleted_at IS NOT NULL`, id))
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return userNotFoundErr{args: []any{id}}
+	}
+
+	if err := tx.Exec(ctx, sqlf.Sprintf("INSERT INTO names(name, user_id) SELECT username, id FROM users WHERE id = %s", id)); err != nil {
+		return err
+	}
+
+	// The tokens and external accounts were deleted along with the user, this only makes sure that none are left.
+	if err := tx.Exec(ctx, sqlf.Sprintf("UPDATE access_tokens SET deleted_at = now() WHERE subject_user_id = %s AND deleted_at IS NULL", id)); err != nil {
+		return err
+	}
+	if err := tx.Exec(ctx, sqlf.Sprintf("UPDATE user_external_accounts SET deleted_at = now() WHERE user_id = %s AND deleted_at IS NULL", id)); err != nil {
+		return err
+	}
+
+	return tx.Exec(ctx, sqlf.Sprintf(`
+DELETE FROM user_roles
+WHERE user_id = %s AND role_id NOT IN (SELECT id FROM roles WHERE name = %s AND system)`, id, types.UserSystemRole))
+}
+
 // SetIsSiteAdmin sets the user with the given ID to be or not to be the site admin. It also assigns the role `SITE_ADMINISTRATOR`
 // to the user when `isSiteAdmin` is true and revokes the role when false.
 func (u *userStore) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool) error {
@@ -832,6 +886,15 @@ func (u *userStore) GetByVerifiedEmail(ctx context.Context, email string) (*type
 	return u.getOneBySQL(ctx, sqlf.Sprintf("WHERE id=(SELECT user_id FROM user_emails WHERE email=%s AND verified_at IS NOT NULL) AND deleted_at IS NULL LIMIT 1", email))
 }
 
+// GetDeletedNonSCIMByUsername returns the most recently soft-deleted user with
+// the given username that was not provisioned through SCIM.
+func (u *userStore) GetDeletedNonSCIMByUsername(ctx context.Context, username string) (*types.User, error) {
+	return u.getOneBySQL(ctx, sqlf.Sprintf(`
+WHERE u.username=%s AND u.deleted_at IS NOT NULL
+AND NOT EXISTS (SELECT 1 FROM user_external_accounts a WHERE a.user_id = u.id AND a.service_type = 'scim')
+ORDER BY u.deleted_at DESC LIMIT 1`, username))
+}
+
 func (u *userStore) GetByUsername(ctx context.Context, username string) (*types.User, error) {
 	return u.getOneBySQL(ctx, sqlf.Sprintf("WHERE u.username=%s AND u.deleted_at IS NULL LIMIT 1", username))
 }
@@ -920,6 +983,14 @@ type UsersListOptions struct {
 	UserIDs []int32
 	// Only show users inside this org
 	OrgID int32
+	// SCIMResourceID, if set, only includes the user with this opaque SCIM
+	// resource ID.
+	SCIMResourceID string
+	// IncludeDeleted, if set, also includes soft-deleted users.
+	IncludeDeleted bool
+	// AfterID, if set, only includes users with a greater ID, for keyset
+	// pagination.
+	AfterID int32
 
 	Tag string // only include users with this tag
 
@@ -1067,7 +1138,10 @@ func newQueryCond(query *string) *sqlf.Query {
 }
 
 func (*userStore) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
-	conds = []*sqlf.Query{sqlf.Sprintf("deleted_at IS NULL")}
+	conds = []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if !opt.IncludeDeleted {
+		conds = []*sqlf.Query{sqlf.Sprintf("deleted_at IS NULL")}
+	}
 
 	if cond := newQueryCond(&opt.Query); cond != nil {
 		conds = append(conds, cond)
@@ -1088,6 +1162,12 @@ func (*userStore) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	if opt.OrgID != 0 {
 		conds = append(conds, sqlf.Sprintf(orgMembershipCond, opt.OrgID))
 	}
+	if opt.SCIMResourceID != "" {
+		conds = append(conds, sqlf.Sprintf("u.scim_resource_id = %s", opt.SCIMResourceID))
+	}
+	if opt.AfterID != 0 {
+		conds = append(conds, sqlf.Sprintf("u.id > %s", opt.AfterID))
+	}
 	if opt.Tag != "" {
 		conds = append(conds, sqlf.Sprintf("%s::text = ANY(u.tags)", opt.Tag))
 	}
@@ -1196,7 +1276,9 @@ SELECT u.id,
        u.tos_accepted,
        u.searchable,
        ARRAY(SELECT email FROM user_emails WHERE user_id = u.id AND verified_at IS NOT NULL) AS emails,
-       (SELECT account_id FROM user_external_accounts WHERE user_id=u.id AND service_type = 'scim') AS scim_external_id
+       (SELECT account_id FROM user_external_accounts WHERE user_id=u.id AND service_type = 'scim' AND deleted_at IS NULL) AS scim_external_id,
+       u.scim_resource_id,
+       u.scim_enterprise_attributes
   FROM users u %s`
 
 // getBySQLForSCIM returns users matching the SQL query, along with their email addresses and SCIM ExternalID.
@@ -1210,14 +1292,19 @@ func (u *userStore) getBySQLForSCIM(ctx context.Context, query *sqlf.Query) ([]*
 // scanUserForSCIM scans a UserForSCIM from the return of a *sql.Rows.
 func scanUserForSCIM(s dbutil.Scanner) (*types.UserForSCIM, error) {
 	var u types.UserForSCIM
-	var displayName, avatarURL, scimExternalID sql.NullString
-	err := s.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.InvalidatedSessionsAt, &u.TosAccepted, &u.Searchable, pq.Array(&u.Emails), &scimExternalID)
+	var displayName, avatarURL, scimExternalID, scimResourceID sql.NullString
+	var scimEnterpriseAttributes []byte
+	err := s.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.InvalidatedSessionsAt, &u.TosAccepted, &u.Searchable, pq.Array(&u.Emails), &scimExternalID, &scimResourceID, &scimEnterpriseAttributes)
 	if err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(scimEnterpriseAttributes, &u.SCIMEnterpriseAttributes); err != nil {
+		return nil, err
+	}
 	u.DisplayName = displayName.String
 	u.AvatarURL = avatarURL.String
 	u.SCIMExternalID = scimExternalID.String
+	u.SCIMResourceID = scimResourceID.String
 	return &u, nil
 }
 
@@ -1467,6 +1554,47 @@ func (u *userStore) SetTag(ctx context.Context, userID int32, tag string, presen
 	return nil
 }
 
+// SetSCIMResourceID sets the opaque identifier of the given user in SCIM. An
+// error occurs if the user does not exist.
+func (u *userStore) SetSCIMResourceID(ctx context.Context, id int32, resourceID string) error {
+	res, err := u.ExecResult(ctx, sqlf.Sprintf("UPDATE users SET scim_resource_id=%s WHERE id=%s AND deleted_at IS NULL", resourceID, id))
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []any{id}}
+	}
+	return nil
+}
+
+// SetSCIMEnterpriseAttributes replaces the SCIM enterprise user extension
+// attributes of the given user. An error occurs if the user does not exist.
+func (u *userStore) SetSCIMEnterpriseAttributes(ctx context.Context, id int32, attributes map[string]string) error {
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	res, err := u.ExecResult(ctx, sqlf.Sprintf("UPDATE users SET scim_enterprise_attributes=%s WHERE id=%s AND deleted_at IS NULL", raw, id))
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []any{id}}
+	}
+	return nil
+}
+
 // HasTag reports whether the context actor has the given tag.
 // If not, it returns false and a nil error.
 func (u *userStore) HasTag(ctx context.Context, userID int32, tag string) (bool, error) {
@@ -1505,6 +1633,23 @@ func (u *userStore) Tags(ctx context.Context, userID int32) (map[string]bool, er
 	return tagMap, nil
 }
 
+// TemporaryAdminExpiresAt returns when the user's active assignment of the ADMIN
+// system role expires, or the zero time if they don't have one.
+func (u *userStore) TemporaryAdminExpiresAt(ctx context.Context, id int32) (time.Time, error) {
+	q := sqlf.Sprintf(`
+SELECT ur.expires_at FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+WHERE ur.user_id = %s AND r.name = %s AND r.system AND ur.expires_at > NOW()`, id, types.AdminSystemRole)
+
+	var expiresAt time.Time
+	if err := u.QueryRow(ctx, q).Scan(&expiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return expiresAt, nil
+}
+
 // MockHashPassword if non-nil is used instead of database.hashPassword. This is useful
 // when running tests since we can use a faster implementation.
 var (
@@ -485,6 +485,99 @@ func TestUsers_ListForSCIM_Query(t *testing.T) {
 	assert.Len(t, users[0].Emails, 1)
 	assert.Len(t, users[1].Emails, 0)
 	assert.Len(t, users[2].Emails, 2)
+
+	// Soft-deleted users are only listed if requested.
+	if err := db.Users().Delete(ctx, users[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	users, err = db.Users().ListForSCIM(ctx, &UsersListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, users, 2)
+	users, err = db.Users().ListForSCIM(ctx, &UsersListOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, users, 3)
+}
+
+func TestUsers_SetSCIMResourceID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	alice, err := db.Users().Create(ctx, NewUser{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := db.Users().Create(ctx, NewUser{Username: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Users().SetSCIMResourceID(ctx, alice.ID, "opaque-alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := db.Users().ListForSCIM(ctx, &UsersListOptions{SCIMResourceID: "opaque-alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, alice.ID, users[0].ID)
+		assert.Equal(t, "opaque-alice", users[0].SCIMResourceID)
+	}
+
+	// Opaque IDs are unique.
+	err = db.Users().SetSCIMResourceID(ctx, bob.ID, "opaque-alice")
+	assert.Error(t, err)
+
+	err = db.Users().SetSCIMResourceID(ctx, 12345, "opaque-missing")
+	assert.True(t, errcode.IsNotFound(err))
+}
+
+func TestUsers_SetSCIMEnterpriseAttributes(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	alice, err := db.Users().Create(ctx, NewUser{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := db.Users().ListForSCIM(ctx, &UsersListOptions{UserIDs: []int32{alice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, users, 1) {
+		assert.Empty(t, users[0].SCIMEnterpriseAttributes)
+	}
+
+	attributes := map[string]string{"department": "Engineering", "costCenter": "4130"}
+	if err := db.Users().SetSCIMEnterpriseAttributes(ctx, alice.ID, attributes); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err = db.Users().ListForSCIM(ctx, &UsersListOptions{UserIDs: []int32{alice.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, attributes, users[0].SCIMEnterpriseAttributes)
+	}
+
+	err = db.Users().SetSCIMEnterpriseAttributes(ctx, 12345, attributes)
+	assert.True(t, errcode.IsNotFound(err))
 }
 
 func TestUsers_Update(t *testing.T) {
@@ -650,6 +743,48 @@ func TestUsers_GetByUsername(t *testing.T) {
 
 }
 
+func TestUsers_GetDeletedNonSCIMByUsername(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+
+	local, err := db.Users().Create(ctx, NewUser{Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scim, err := db.UserExternalAccounts().CreateUserAndSave(ctx, NewUser{Username: "bob"}, extsvc.AccountSpec{ServiceType: "scim", ServiceID: "scim", AccountID: "bob"}, extsvc.AccountData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Users().Create(ctx, NewUser{Username: "cindy"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int32{local.ID, scim.ID} {
+		if err := db.Users().Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	have, err := db.Users().GetDeletedNonSCIMByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have.ID != local.ID {
+		t.Errorf("got user %d, but want %d", have.ID, local.ID)
+	}
+
+	// SCIM-provisioned and active users are not returned.
+	for _, username := range []string{"bob", "cindy"} {
+		if _, err := db.Users().GetDeletedNonSCIMByUsername(ctx, username); !errcode.IsNotFound(err) {
+			t.Errorf("%s: invalid error, expected not found got %v", username, err)
+		}
+	}
+}
+
 func TestUsers_GetByUsernames(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -977,6 +1112,112 @@ func TestUsers_RecoverUsers(t *testing.T) {
 	})
 }
 
+func TestUsers_ReclaimDeleted(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	logger := logtest.Scoped(t)
+	db := NewDB(logger, dbtest.NewDB(logger, t))
+	ctx := context.Background()
+	ctx = actor.WithActor(ctx, &actor.Actor{UID: 1, Internal: true})
+
+	user, err := db.Users().Create(ctx, NewUser{
+		Email:                 "a@a.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Users().SetIsSiteAdmin(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	role, err := db.Roles().Create(ctx, "TEST-ROLE", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.UserRoles().Assign(ctx, AssignUserRoleOpts{RoleID: role.ID, UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := db.AccessTokens().Create(ctx, user.ID, []string{"user:all"}, "n", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := extsvc.AccountSpec{
+		ServiceType: "github",
+		ServiceID:   "https://github.com/",
+		ClientID:    "xc",
+		AccountID:   "xd",
+	}
+	if err := db.UserExternalAccounts().Insert(ctx, user.ID, spec, extsvc.AccountData{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Users().Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Users().ReclaimDeleted(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("user is live again", func(t *testing.T) {
+		reclaimed, err := db.Users().GetByUsername(ctx, "u")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reclaimed.ID != user.ID {
+			t.Errorf("got user %d, want %d", reclaimed.ID, user.ID)
+		}
+		if reclaimed.SiteAdmin {
+			t.Error("reclaimed user should not be a site admin")
+		}
+	})
+
+	t.Run("password is cleared", func(t *testing.T) {
+		ok, err := db.Users().IsPassword(ctx, user.ID, "p")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Error("old password still works")
+		}
+	})
+
+	t.Run("old access token does not work", func(t *testing.T) {
+		if _, err := db.AccessTokens().Lookup(ctx, token, "user:all"); err == nil {
+			t.Error("old access token still works")
+		}
+	})
+
+	t.Run("old external account does not work", func(t *testing.T) {
+		_, err := db.UserExternalAccounts().LookupUserAndSave(ctx, spec, extsvc.AccountData{})
+		if !errcode.IsNotFound(err) {
+			t.Errorf("got err %v, want not found", err)
+		}
+	})
+
+	t.Run("non-default roles are revoked", func(t *testing.T) {
+		userRoles, err := db.UserRoles().GetByUserID(ctx, GetUserRoleOpts{UserID: user.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ur := range userRoles {
+			if ur.RoleID == role.ID {
+				t.Errorf("role %d was not revoked", role.ID)
+			}
+		}
+	})
+
+	t.Run("live user cannot be reclaimed", func(t *testing.T) {
+		err := db.Users().ReclaimDeleted(ctx, user.ID)
+		if !errcode.IsNotFound(err) {
+			t.Errorf("got err %v, want not found", err)
+		}
+	})
+}
+
 func TestUsers_HasTag(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
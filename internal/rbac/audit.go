@@ -0,0 +1,38 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+)
+
+// LogDestructiveEvent records an RBAC operation that removes roles, permissions
+// or their assignments in the security event log, so that it can be reviewed
+// with the destructiveRbacAudit query. The operation is attributed to the
+// current actor, or to the backend when there is none, e.g. when a background
+// job performs it.
+func LogDestructiveEvent(ctx context.Context, logger log.Logger, db database.DB, name database.SecurityEventName, argument any) {
+	arg, err := json.Marshal(argument)
+	if err != nil {
+		logger.Error("failed to marshal RBAC security event argument", log.String("event", string(name)), log.Error(err))
+		return
+	}
+
+	event := &database.SecurityEvent{
+		Name:      name,
+		UserID:    uint32(actor.FromContext(ctx).UID),
+		Argument:  arg,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}
+	// Security events need either a user or an anonymous user.
+	if event.UserID == 0 {
+		event.AnonymousUserID = "backend"
+	}
+	db.SecurityEventLogs().LogEvent(ctx, event)
+}
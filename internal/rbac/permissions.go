@@ -27,6 +27,52 @@ var RBACSchema = func() Schema {
 	return parsedSchema
 }()
 
+// FeaturePermissions returns the permissions a user needs to use the feature
+// with the given name. The returned permissions are not stored in the database,
+// so they have no ID. It returns false if the feature is unknown.
+func (s Schema) FeaturePermissions(feature string) ([]*types.Permission, bool) {
+	for _, f := range s.Features {
+		if f.Name != feature {
+			continue
+		}
+		permissions := make([]*types.Permission, 0, len(f.Permissions))
+		for _, p := range f.Permissions {
+			permissions = append(permissions, &types.Permission{Namespace: p.Namespace, Action: p.Action})
+		}
+		return permissions, true
+	}
+	return nil, false
+}
+
+// DefaultSystemRoles are the system roles that are granted every permission in
+// the schema, so that everyone has access until a site administrator revokes
+// it.
+var DefaultSystemRoles = []types.SystemRole{types.SiteAdministratorSystemRole, types.UserSystemRole}
+
+// Permissions returns all the permissions defined in the schema. The returned
+// permissions are not stored in the database, so they have no ID.
+func (s Schema) Permissions() []*types.Permission {
+	var permissions []*types.Permission
+	for _, n := range s.Namespaces {
+		for _, a := range n.Actions {
+			permissions = append(permissions, &types.Permission{Namespace: n.Name, Action: a})
+		}
+	}
+	return permissions
+}
+
+// SystemRolePermissions returns the permissions that the given system role is
+// expected to grant. The returned permissions are not stored in the database,
+// so they have no ID.
+func (s Schema) SystemRolePermissions(role types.SystemRole) []*types.Permission {
+	for _, r := range DefaultSystemRoles {
+		if r == role {
+			return s.Permissions()
+		}
+	}
+	return nil
+}
+
 // ComparePermissions takes two slices of permissions (one from the database and another from the schema file)
 // and extracts permissions that need to be added / deleted in the database based on those contained in the schema file.
 func ComparePermissions(dbPerms []*types.Permission, schemaPerms Schema) (added []database.CreatePermissionOpts, deleted []database.DeletePermissionOpts) {
@@ -50,16 +96,7 @@ func ComparePermissions(dbPerms []*types.Permission, schemaPerms Schema) (added
 		}
 	}
 
-	var parsedSchemaPerms []*types.Permission
-
-	for _, n := range schemaPerms.Namespaces {
-		for _, a := range n.Actions {
-			parsedSchemaPerms = append(parsedSchemaPerms, &types.Permission{
-				Namespace: n.Name,
-				Action:    a,
-			})
-		}
-	}
+	parsedSchemaPerms := schemaPerms.Permissions()
 
 	// Check items in schema file to see which exists in the database
 	for _, p := range parsedSchemaPerms {
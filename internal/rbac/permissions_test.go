@@ -124,3 +124,80 @@ func TestComparePermissions(t *testing.T) {
 }
 
 func sortDeletePermissionOptSlice(a, b database.DeletePermissionOpts) bool { return a.ID < b.ID }
+
+func TestFeaturePermissions(t *testing.T) {
+	s := Schema{
+		Namespaces: []Namespace{
+			{Name: "TEST-NAMESPACE", Actions: []string{"READ", "WRITE"}},
+		},
+		Features: []Feature{
+			{Name: "test-feature", Permissions: []FeaturePermission{
+				{Namespace: "TEST-NAMESPACE", Action: "READ"},
+				{Namespace: "TEST-NAMESPACE", Action: "WRITE"},
+			}},
+		},
+	}
+
+	t.Run("known feature", func(t *testing.T) {
+		want := []*types.Permission{
+			{Namespace: "TEST-NAMESPACE", Action: "READ"},
+			{Namespace: "TEST-NAMESPACE", Action: "WRITE"},
+		}
+
+		permissions, ok := s.FeaturePermissions("test-feature")
+
+		assert.True(t, ok)
+		if diff := cmp.Diff(want, permissions); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("unknown feature", func(t *testing.T) {
+		_, ok := s.FeaturePermissions("unknown-feature")
+
+		assert.False(t, ok)
+	})
+}
+
+func TestSystemRolePermissions(t *testing.T) {
+	s := Schema{
+		Namespaces: []Namespace{
+			{Name: "TEST-NAMESPACE", Actions: []string{"READ", "WRITE"}},
+			{Name: "TEST-NAMESPACE-2", Actions: []string{"READ"}},
+		},
+	}
+	all := []*types.Permission{
+		{Namespace: "TEST-NAMESPACE", Action: "READ"},
+		{Namespace: "TEST-NAMESPACE", Action: "WRITE"},
+		{Namespace: "TEST-NAMESPACE-2", Action: "READ"},
+	}
+
+	for _, role := range DefaultSystemRoles {
+		if diff := cmp.Diff(all, s.SystemRolePermissions(role)); diff != "" {
+			t.Errorf("wrong permissions for %s (-want +got):\n%s", role, diff)
+		}
+	}
+
+	// The ADMIN role grants site admin privileges instead of permissions.
+	assert.Empty(t, s.SystemRolePermissions(types.AdminSystemRole))
+}
+
+func TestRBACSchemaFeatures(t *testing.T) {
+	// Every permission required by a feature must be defined in a namespace,
+	// otherwise it can never be granted.
+	defined := make(map[string]struct{})
+	for _, n := range RBACSchema.Namespaces {
+		for _, a := range n.Actions {
+			defined[(&types.Permission{Namespace: n.Name, Action: a}).DisplayName()] = struct{}{}
+		}
+	}
+
+	for _, f := range RBACSchema.Features {
+		for _, p := range f.Permissions {
+			name := (&types.Permission{Namespace: p.Namespace, Action: p.Action}).DisplayName()
+			if _, ok := defined[name]; !ok {
+				t.Errorf("feature %q requires undefined permission %q", f.Name, name)
+			}
+		}
+	}
+}
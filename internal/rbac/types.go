@@ -6,6 +6,7 @@ import "github.com/sourcegraph/sourcegraph/internal/types"
 // the RBAC system.
 type Schema struct {
 	Namespaces []Namespace `json:"namespaces"`
+	Features   []Feature   `json:"features"`
 }
 
 // Namespace represents a feature to be guarded by RBAC. (example: Batch Changes, Code Insights e.t.c)
@@ -13,3 +14,15 @@ type Namespace struct {
 	Name    types.PermissionNamespace `json:"name"`
 	Actions []string                  `json:"actions"`
 }
+
+// Feature represents a product feature and the permissions a user needs to use it.
+type Feature struct {
+	Name        string              `json:"name"`
+	Permissions []FeaturePermission `json:"permissions"`
+}
+
+// FeaturePermission is a permission required by a feature.
+type FeaturePermission struct {
+	Namespace types.PermissionNamespace `json:"namespace"`
+	Action    string                    `json:"action"`
+}
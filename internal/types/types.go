@@ -810,6 +810,12 @@ type UserForSCIM struct {
 	User
 	Emails         []string
 	SCIMExternalID string
+	// SCIMResourceID is the opaque identifier of the user in SCIM, if one was
+	// assigned. Otherwise, the user is identified by their ID.
+	SCIMResourceID string
+	// SCIMEnterpriseAttributes are the attributes of the SCIM enterprise user
+	// extension, such as "department", keyed by attribute name.
+	SCIMEnterpriseAttributes map[string]string
 }
 
 type SystemRole string
@@ -821,6 +827,15 @@ const (
 	// SiteAdministratorSystemRole represents the role associated with Site Administrators
 	// on a sourcegraph instance.
 	SiteAdministratorSystemRole SystemRole = "SITE_ADMINISTRATOR"
+
+	// AdminSystemRole represents the role that grants site admin privileges while it
+	// is assigned. It can only be assigned temporarily, for just-in-time admin access.
+	//
+	// Only the checks in internal/auth that go through checkIsSiteAdmin honor it, such
+	// as auth.CheckCurrentUserIsSiteAdmin. Code that reads User.SiteAdmin directly,
+	// such as the siteAdmin field of the JS context or the global settings editing
+	// check, doesn't.
+	AdminSystemRole SystemRole = "ADMIN"
 )
 
 type Role struct {
@@ -828,6 +843,9 @@ type Role struct {
 	Name      string
 	System    bool
 	CreatedAt time.Time
+	// UpdatedAt is the time the role was last updated. It is the zero value if
+	// the role was never updated.
+	UpdatedAt time.Time
 }
 
 // A PermissionNamespace represents a distinct context within which permission policies
@@ -841,7 +859,7 @@ func (n PermissionNamespace) String() string {
 // Valid checks if a namespace is valid and supported by the Sourcegraph RBAC system.
 func (n PermissionNamespace) Valid() bool {
 	switch n {
-	case BatchChangesNamespace:
+	case BatchChangesNamespace, RBACNamespace:
 		return true
 	default:
 		return false
@@ -851,6 +869,9 @@ func (n PermissionNamespace) Valid() bool {
 // BatchChangesNamespace represents the Batch Changes namespace.
 const BatchChangesNamespace PermissionNamespace = "BATCH_CHANGES"
 
+// RBACNamespace represents the namespace for managing roles and permissions.
+const RBACNamespace PermissionNamespace = "RBAC"
+
 type Permission struct {
 	ID        int32
 	Namespace PermissionNamespace
@@ -875,6 +896,9 @@ type UserRole struct {
 	RoleID    int32
 	UserID    int32
 	CreatedAt time.Time
+	// ExpiresAt is the time after which the role assignment is no longer active.
+	// It is the zero value if the assignment doesn't expire.
+	ExpiresAt time.Time
 }
 
 type NamespacePermission struct {
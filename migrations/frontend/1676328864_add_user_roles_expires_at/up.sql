@@ -0,0 +1,2 @@
+ALTER TABLE user_roles
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
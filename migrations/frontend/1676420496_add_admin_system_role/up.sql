@@ -0,0 +1,7 @@
+-- system role that grants site admin privileges while it is assigned, used
+-- to give users temporary (just-in-time) admin access
+INSERT INTO
+    roles (name, system)
+VALUES
+    ('ADMIN', TRUE)
+ON CONFLICT DO NOTHING;
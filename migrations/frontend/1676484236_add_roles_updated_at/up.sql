@@ -0,0 +1,4 @@
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN roles.updated_at IS 'The time the role was last updated, or NULL if it was never updated.';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_scim_resource_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS scim_resource_id;
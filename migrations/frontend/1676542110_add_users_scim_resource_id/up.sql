@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS scim_resource_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_scim_resource_id ON users USING btree (scim_resource_id) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN users.scim_resource_id IS 'Opaque identifier of the user in SCIM, or NULL if the user is identified by their ID.';
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS scim_enterprise_attributes JSONB DEFAULT '{}'::jsonb NOT NULL;
+
+COMMENT ON COLUMN users.scim_enterprise_attributes IS 'The attributes of the SCIM enterprise user extension that were provisioned for the user, such as their department.';
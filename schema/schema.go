@@ -53,6 +53,16 @@ type AWSKMSEncryptionKey struct {
 	Region          string `json:"region,omitempty"`
 	Type            string `json:"type"`
 }
+type Announcement struct {
+	// Dismissible description: Whether the announcement can be dismissed (closed) by users.
+	Dismissible bool `json:"dismissible,omitempty"`
+	// Id description: A unique identifier for the announcement. It is used to remember which announcements a user has dismissed.
+	Id string `json:"id"`
+	// Message description: The message to display. Markdown formatting is supported.
+	Message string `json:"message"`
+	// Severity description: The severity of the announcement, which determines how it is styled.
+	Severity string `json:"severity,omitempty"`
+}
 
 // ApiRatelimit description: Configuration for API rate limiting
 type ApiRatelimit struct {
@@ -209,6 +219,14 @@ type BackendInsightSeries struct {
 	// Stroke description: The color of the line for the series.
 	Stroke string `json:"stroke,omitempty"`
 }
+
+// BackupInProgress description: Shows a banner to all users telling them that the instance should be treated as read-only while a backup is in progress. Requests are not blocked.
+type BackupInProgress struct {
+	// Enabled description: Whether a backup is in progress and the banner is shown.
+	Enabled bool `json:"enabled,omitempty"`
+	// Message description: The message shown in the banner instead of the default one. Markdown is supported and HTML is sanitized.
+	Message string `json:"message,omitempty"`
+}
 type BatchChangeRolloutWindow struct {
 	// Days description: Day(s) the window applies to. If omitted, this rule applies to all days of the week.
 	Days []string `json:"days,omitempty"`
@@ -531,6 +549,14 @@ type DebugLog struct {
 	ExtsvcGitlab bool `json:"extsvc.gitlab,omitempty"`
 }
 
+// DefaultEditorIntegration description: The editor that "open in editor" links are preconfigured for. Users can still choose a different editor in their own settings.
+type DefaultEditorIntegration struct {
+	// EditorId description: The editor to open files in. If set to "custom", "urlPattern" must also be set.
+	EditorId string `json:"editorId"`
+	// UrlPattern description: The link template used to open files in the editor. Use the placeholders "%file", "%line", and "%col" to mark where the file path, line number, and column number must be inserted.
+	UrlPattern string `json:"urlPattern,omitempty"`
+}
+
 // Dotcom description: Configuration options for Sourcegraph.com only.
 type Dotcom struct {
 	// SlackLicenseExpirationWebhook description: Slack webhook for upcoming license expiration notifications.
@@ -2005,6 +2031,8 @@ type Settings struct {
 	SearchIncludeArchived *bool `json:"search.includeArchived,omitempty"`
 	// SearchIncludeForks description: Whether searches should include searching forked repositories.
 	SearchIncludeForks *bool `json:"search.includeForks,omitempty"`
+	// SearchMaxConcurrentSearches description: The number of searches to run at the same time, e.g. in several browser tabs, before the web app warns that further searches may be slow. This is only a hint for the web app; the server does not limit concurrent searches. Defaults to 10.
+	SearchMaxConcurrentSearches int `json:"search.maxConcurrentSearches,omitempty"`
 	// SearchSavedQueries description: DEPRECATED: Saved search queries
 	SearchSavedQueries []*SearchSavedQueries `json:"search.savedQueries,omitempty"`
 	// SearchScopes description: Predefined search snippets that can be appended to any search (also known as search scopes)
@@ -2079,6 +2107,7 @@ func (v *Settings) UnmarshalJSON(data []byte) error {
 	delete(m, "search.hideSuggestions")
 	delete(m, "search.includeArchived")
 	delete(m, "search.includeForks")
+	delete(m, "search.maxConcurrentSearches")
 	delete(m, "search.savedQueries")
 	delete(m, "search.scopes")
 	if len(m) > 0 {
@@ -2273,6 +2302,8 @@ type SettingsOpenInEditor struct {
 type SiteConfiguration struct {
 	// RedirectUnsupportedBrowser description: Prompts user to install new browser for non es5
 	RedirectUnsupportedBrowser bool `json:"RedirectUnsupportedBrowser,omitempty"`
+	// Announcements description: Instance-wide announcements shown as banners to all users, including anonymous users. Do not include sensitive information.
+	Announcements []*Announcement `json:"announcements,omitempty"`
 	// ApiRatelimit description: Configuration for API rate limiting
 	ApiRatelimit *ApiRatelimit `json:"api.ratelimit,omitempty"`
 	// AuthAccessTokens description: Settings for access tokens, which enable external tools to access the Sourcegraph API with the privileges of the user.
@@ -2283,6 +2314,8 @@ type SiteConfiguration struct {
 	AuthLockout *AuthLockout `json:"auth.lockout,omitempty"`
 	// AuthMinPasswordLength description: The minimum number of Unicode code points that a password must contain.
 	AuthMinPasswordLength int `json:"auth.minPasswordLength,omitempty"`
+	// AuthOrgMembershipRequired description: Require users to be a member of at least one organization. Signed-in users who are not are prompted to join or create an organization.
+	AuthOrgMembershipRequired bool `json:"auth.orgMembershipRequired,omitempty"`
 	// AuthPasswordPolicy description: Enables and configures password policy. This will allow admins to enforce password complexity and length requirements.
 	AuthPasswordPolicy *AuthPasswordPolicy `json:"auth.passwordPolicy,omitempty"`
 	// AuthPasswordResetLinkExpiry description: The duration (in seconds) that a password reset link is considered valid.
@@ -2317,6 +2350,8 @@ type SiteConfiguration struct {
 	AuthzRefreshInterval int `json:"authz.refreshInterval,omitempty"`
 	// AuthzSyncJobsRecordsLimit description: EXPERIMENTAL: Number of sync job records to retain. Set to a negative value to disable sync jobs records entirely.
 	AuthzSyncJobsRecordsLimit int `json:"authz.syncJobsRecordsLimit,omitempty"`
+	// BackupInProgress description: Shows a banner to all users telling them that the instance should be treated as read-only while a backup is in progress. Requests are not blocked.
+	BackupInProgress *BackupInProgress `json:"backupInProgress,omitempty"`
 	// BatchChangesChangesetsRetention description: How long changesets will be retained after they have been detached from a batch change.
 	BatchChangesChangesetsRetention string `json:"batchChanges.changesetsRetention,omitempty"`
 	// BatchChangesDisableWebhooksWarning description: Hides Batch Changes warnings about webhooks not being configured.
@@ -2345,10 +2380,14 @@ type SiteConfiguration struct {
 	CodeIntelAutoIndexingPolicyRepositoryMatchLimit *int `json:"codeIntelAutoIndexing.policyRepositoryMatchLimit,omitempty"`
 	// CorsOrigin description: Required when using any of the native code host integrations for Phabricator, GitLab, or Bitbucket Server. It is a space-separated list of allowed origins for cross-origin HTTP requests which should be the base URL for your Phabricator, GitLab, or Bitbucket Server instance.
 	CorsOrigin string `json:"corsOrigin,omitempty"`
+	// CustomErrorPageContent description: Markdown content shown on error pages, such as when a page is not found, instead of the default message. For example, it can point users to an internal support channel. HTML is sanitized.
+	CustomErrorPageContent string `json:"customErrorPageContent,omitempty"`
 	// DebugSearchSymbolsParallelism description: (debug) controls the amount of symbol search parallelism. Defaults to 20. It is not recommended to change this outside of debugging scenarios. This option will be removed in a future version.
 	DebugSearchSymbolsParallelism int `json:"debug.search.symbolsParallelism,omitempty"`
 	// DefaultRateLimit description: The rate limit (in requests per hour) for the default rate limiter in the rate limiters registry. By default this is disabled and the default rate limit is infinity.
 	DefaultRateLimit float64 `json:"defaultRateLimit,omitempty"`
+	// DefaultRepoSortOrder description: The order in which repository lists in the UI are sorted by default.
+	DefaultRepoSortOrder string `json:"defaultRepoSortOrder,omitempty"`
 	// DisableAutoCodeHostSyncs description: Disable periodic syncs of configured code host connections (repository metadata, permissions, batch changes changesets, etc)
 	DisableAutoCodeHostSyncs bool `json:"disableAutoCodeHostSyncs,omitempty"`
 	// DisableAutoGitUpdates description: Disable periodically fetching git contents for existing repositories.
@@ -2371,6 +2410,8 @@ type SiteConfiguration struct {
 	EmailTemplates *EmailTemplates `json:"email.templates,omitempty"`
 	// EncryptionKeys description: Configuration for encryption keys used to encrypt data at rest in the database.
 	EncryptionKeys *EncryptionKeys `json:"encryption.keys,omitempty"`
+	// EnforceRepoVisibility description: Whether repository visibility rules are enforced, i.e. repositories must not be shared outside of the users allowed to see them. If true, the UI hides controls for sharing repositories or links publicly.
+	EnforceRepoVisibility bool `json:"enforceRepoVisibility,omitempty"`
 	// ExecutorsAccessToken description: The shared secret between Sourcegraph and executors.
 	ExecutorsAccessToken string `json:"executors.accessToken,omitempty"`
 	// ExecutorsBatcheshelperImage description: The image to use for batch changes in executors. Use this value to pull from a custom image registry.
@@ -2458,6 +2499,8 @@ type SiteConfiguration struct {
 	ObservabilitySilenceAlerts []string `json:"observability.silenceAlerts,omitempty"`
 	// ObservabilityTracing description: Configures distributed tracing within Sourcegraph. To learn more, refer to https://docs.sourcegraph.com/admin/observability/tracing
 	ObservabilityTracing *ObservabilityTracing `json:"observability.tracing,omitempty"`
+	// OpenInEditorDefault description: The editor that "open in editor" links are preconfigured for. Users can still choose a different editor in their own settings.
+	OpenInEditorDefault *DefaultEditorIntegration `json:"openInEditor.default,omitempty"`
 	// OrganizationInvitations description: Configuration for organization invitations.
 	OrganizationInvitations *OrganizationInvitations `json:"organizationInvitations,omitempty"`
 	// OutboundRequestLogLimit description: The maximum number of outbound requests to retain. This is a global limit across all outbound requests. If the limit is exceeded, older items will be deleted. If the limit is 0, no outbound requests are logged.
@@ -2482,6 +2525,8 @@ type SiteConfiguration struct {
 	PermissionsSyncUsersMaxConcurrency int `json:"permissions.syncUsersMaxConcurrency,omitempty"`
 	// PermissionsUserMapping description: Settings for Sourcegraph permissions, which allow the site admin to explicitly manage repository permissions via the GraphQL API. This setting cannot be enabled if repository permissions for any specific external service are enabled (i.e., when the external service's `authorization` field is set).
 	PermissionsUserMapping *PermissionsUserMapping `json:"permissions.userMapping,omitempty"`
+	// PreferredCodeHostKind description: The kind of code host that is preselected when a site admin adds a code host connection.
+	PreferredCodeHostKind string `json:"preferredCodeHostKind,omitempty"`
 	// ProductResearchPageEnabled description: Enables users access to the product research page in their settings.
 	ProductResearchPageEnabled *bool `json:"productResearchPage.enabled,omitempty"`
 	// RedactOutboundRequestHeaders description: Enables redacting sensitive information from outbound requests. Important: We only respect this setting in development environments. In production, we always redact outbound requests.
@@ -2492,14 +2537,36 @@ type SiteConfiguration struct {
 	RepoListUpdateInterval int `json:"repoListUpdateInterval,omitempty"`
 	// RepoPurgeWorker description: Configuration for repository purge worker.
 	RepoPurgeWorker *RepoPurgeWorker `json:"repoPurgeWorker,omitempty"`
+	// RequireSignedCommits description: Whether commits to this instance's repositories are required to be signed. If true, the UI warns users about this where relevant. Sourcegraph doesn't enforce this itself; enforcement must be configured on the code host.
+	RequireSignedCommits bool `json:"requireSignedCommits,omitempty"`
 	// ScimAuthToken description: DISCLAIMER: UNDER DEVELOPMENT. THE ENDPOINT DOES NOT COMPLY WITH THE SCIM STANDARD YET. The SCIM auth token is used to authenticate SCIM requests. If not set, SCIM is disabled.
 	ScimAuthToken string `json:"scim.authToken,omitempty"`
+	// ScimDefaultRole description: The name of a role that is assigned to every user created through SCIM. The role must exist and must not be a system role.
+	ScimDefaultRole string `json:"scim.defaultRole,omitempty"`
+	// ScimDeletedUserConflictStrategy description: How users created through SCIM are handled if their username belongs to a soft-deleted user who was not provisioned through SCIM. "reject" fails the request, so that the deleted user can still be recovered. "suffix" creates the user with a numeric suffix added to their username, e.g. alice-1. "reclaim" recovers the deleted user and links them to the identity provider instead of creating a new user. If not set, the new user is created with the username, and the deleted user can't be recovered anymore.
+	ScimDeletedUserConflictStrategy string `json:"scim.deletedUserConflictStrategy,omitempty"`
+	// ScimDryRun description: Whether SCIM requests that create, update, or delete users only log the changes they would make, without making them. Their responses describe the result the request would have had, e.g. to check what the identity provider would provision before enabling provisioning. Requests that change groups are rejected.
+	ScimDryRun bool `json:"scim.dryRun,omitempty"`
+	// ScimHardDeleteUsers description: Whether users deleted through SCIM are permanently deleted along with all their data. If false, they are soft-deleted and can be recovered by a site admin.
+	ScimHardDeleteUsers bool `json:"scim.hardDeleteUsers,omitempty"`
+	// ScimInvalidateSessionsOnRename description: Whether a user's sessions are invalidated when their username is changed through SCIM, signing them out everywhere. If false, existing sessions stay valid after a rename.
+	ScimInvalidateSessionsOnRename bool `json:"scim.invalidateSessionsOnRename,omitempty"`
+	// ScimMarkEmailsVerified description: Whether email addresses provisioned through SCIM are marked as verified, trusting the identity provider to have verified them. If false, users need to verify their email addresses themselves.
+	ScimMarkEmailsVerified *bool `json:"scim.markEmailsVerified,omitempty"`
+	// ScimMaxPatchBodyBytes description: The maximum size of the body of a SCIM PATCH or user PUT request, in bytes. Larger requests are rejected.
+	ScimMaxPatchBodyBytes int `json:"scim.maxPatchBodyBytes,omitempty"`
+	// ScimMaxPatchOperations description: The maximum number of operations in a SCIM PATCH request. Requests with more operations are rejected.
+	ScimMaxPatchOperations int `json:"scim.maxPatchOperations,omitempty"`
+	// ScimOpaqueResourceIDs description: Whether users created through SCIM are identified by an opaque, randomly generated ID instead of their Sourcegraph user ID. Users created before this is enabled keep being identified by their user ID. Both forms are accepted when looking up users.
+	ScimOpaqueResourceIDs bool `json:"scim.opaqueResourceIDs,omitempty"`
 	// SearchIndexSymbolsEnabled description: Whether indexed symbol search is enabled. This is contingent on the indexed search configuration, and is true by default for instances with indexed search enabled. Enabling this will cause every repository to re-index, which is a time consuming (several hours) operation. Additionally, it requires more storage and ram to accommodate the added symbols information in the search index.
 	SearchIndexSymbolsEnabled *bool `json:"search.index.symbols.enabled,omitempty"`
 	// SearchLargeFiles description: A list of file glob patterns where matching files will be indexed and searched regardless of their size. Files still need to be valid utf-8 to be indexed. The glob pattern syntax can be found here: https://github.com/bmatcuk/doublestar#patterns.
 	SearchLargeFiles []string `json:"search.largeFiles,omitempty"`
 	// SearchLimits description: Limits that search applies for number of repositories searched and timeouts.
 	SearchLimits *SearchLimits `json:"search.limits,omitempty"`
+	// ShareURLBase description: The base URL that share links are built with. Set this if users reach Sourcegraph through a proxy and share links should point elsewhere than the external URL. Defaults to the external URL.
+	ShareURLBase string `json:"shareURLBase,omitempty"`
 	// SyntaxHighlighting description: Syntax highlighting configuration
 	SyntaxHighlighting *SyntaxHighlighting `json:"syntaxHighlighting,omitempty"`
 	// UpdateChannel description: The channel on which to automatically check for Sourcegraph updates.
@@ -2540,11 +2607,13 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	delete(m, "RedirectUnsupportedBrowser")
+	delete(m, "announcements")
 	delete(m, "api.ratelimit")
 	delete(m, "auth.accessTokens")
 	delete(m, "auth.enableUsernameChanges")
 	delete(m, "auth.lockout")
 	delete(m, "auth.minPasswordLength")
+	delete(m, "auth.orgMembershipRequired")
 	delete(m, "auth.passwordPolicy")
 	delete(m, "auth.passwordResetLinkExpiry")
 	delete(m, "auth.providers")
@@ -2556,6 +2625,7 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 	delete(m, "authz.enforceForSiteAdmins")
 	delete(m, "authz.refreshInterval")
 	delete(m, "authz.syncJobsRecordsLimit")
+	delete(m, "backupInProgress")
 	delete(m, "batchChanges.changesetsRetention")
 	delete(m, "batchChanges.disableWebhooksWarning")
 	delete(m, "batchChanges.enabled")
@@ -2569,8 +2639,10 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 	delete(m, "codeIntelAutoIndexing.indexerMap")
 	delete(m, "codeIntelAutoIndexing.policyRepositoryMatchLimit")
 	delete(m, "corsOrigin")
+	delete(m, "customErrorPageContent")
 	delete(m, "debug.search.symbolsParallelism")
 	delete(m, "defaultRateLimit")
+	delete(m, "defaultRepoSortOrder")
 	delete(m, "disableAutoCodeHostSyncs")
 	delete(m, "disableAutoGitUpdates")
 	delete(m, "disableFeedbackSurvey")
@@ -2581,6 +2653,7 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 	delete(m, "email.smtp")
 	delete(m, "email.templates")
 	delete(m, "encryption.keys")
+	delete(m, "enforceRepoVisibility")
 	delete(m, "executors.accessToken")
 	delete(m, "executors.batcheshelperImage")
 	delete(m, "executors.batcheshelperImageTag")
@@ -2625,6 +2698,7 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 	delete(m, "observability.logSlowSearches")
 	delete(m, "observability.silenceAlerts")
 	delete(m, "observability.tracing")
+	delete(m, "openInEditor.default")
 	delete(m, "organizationInvitations")
 	delete(m, "outboundRequestLogLimit")
 	delete(m, "parentSourcegraph")
@@ -2637,15 +2711,27 @@ func (v *SiteConfiguration) UnmarshalJSON(data []byte) error {
 	delete(m, "permissions.syncUsersBackoffSeconds")
 	delete(m, "permissions.syncUsersMaxConcurrency")
 	delete(m, "permissions.userMapping")
+	delete(m, "preferredCodeHostKind")
 	delete(m, "productResearchPage.enabled")
 	delete(m, "redactOutboundRequestHeaders")
 	delete(m, "repoConcurrentExternalServiceSyncers")
 	delete(m, "repoListUpdateInterval")
 	delete(m, "repoPurgeWorker")
+	delete(m, "requireSignedCommits")
 	delete(m, "scim.authToken")
+	delete(m, "scim.defaultRole")
+	delete(m, "scim.deletedUserConflictStrategy")
+	delete(m, "scim.dryRun")
+	delete(m, "scim.hardDeleteUsers")
+	delete(m, "scim.invalidateSessionsOnRename")
+	delete(m, "scim.markEmailsVerified")
+	delete(m, "scim.maxPatchBodyBytes")
+	delete(m, "scim.maxPatchOperations")
+	delete(m, "scim.opaqueResourceIDs")
 	delete(m, "search.index.symbols.enabled")
 	delete(m, "search.largeFiles")
 	delete(m, "search.limits")
+	delete(m, "shareURLBase")
 	delete(m, "syntaxHighlighting")
 	delete(m, "update.channel")
 	delete(m, "webhook.logging")